@@ -0,0 +1,127 @@
+package alerts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"rdf-store-backend/base"
+	"rdf-store-backend/metrics"
+	"rdf-store-backend/rdf"
+	"rdf-store-backend/search"
+	"sync"
+	"time"
+)
+
+// Schedule is the cron expression for checking subscribed saved searches.
+var Schedule = base.EnvVar("ALERT_SCHEDULE", "0 * * * *")
+
+// SinkType selects where alerts are delivered. Alerts are disabled when it is empty.
+var SinkType = base.EnvVar("ALERT_SINK", "")
+
+var maxHits = base.EnvVarAsInt("ALERT_MAX_HITS", 100)
+var lock sync.Mutex
+
+// Alert reports the entities matching a saved search that were created or
+// changed in a period.
+type Alert struct {
+	SavedSearch *rdf.SavedSearch `json:"savedSearch"`
+	// Since and Until delimit the period [Since, Until).
+	Since time.Time `json:"since"`
+	Until time.Time `json:"until"`
+	// Total is the number of matching entities. Hits holds at most
+	// ALERT_MAX_HITS of them, oldest first.
+	Total int                `json:"total"`
+	Hits  []search.SearchHit `json:"hits"`
+}
+
+// Enabled reports whether an alert sink is configured.
+func Enabled() bool {
+	return SinkType != ""
+}
+
+// DeliversMail reports whether alerts are delivered as mails, which requires
+// saved searches to have an email address.
+func DeliversMail() bool {
+	return SinkType == SinkMailDrop || SinkType == SinkSMTP
+}
+
+// Run checks all subscribed saved searches and delivers an alert for each
+// one with new or changed matching entities.
+func Run() {
+	if !lock.TryLock() {
+		slog.Warn("Skipping saved search alerts: already running")
+		return
+	}
+	defer lock.Unlock()
+	sink, err := newSink(SinkType)
+	if err != nil {
+		slog.Error("failed creating alert sink", "error", err)
+		return
+	}
+	ctx := context.Background()
+	searches, err := rdf.ListSubscribedSearches(ctx)
+	if err != nil {
+		slog.Error("failed listing subscribed searches", "error", err)
+		return
+	}
+	for _, savedSearch := range searches {
+		// saved searches stored without authentication have no owner
+		if savedSearch.Creator == "" {
+			slog.Warn("skipping saved search without owner", "id", savedSearch.Id)
+			continue
+		}
+		if err := check(ctx, sink, savedSearch, time.Now().UTC()); err != nil {
+			slog.Error("failed checking saved search", "id", savedSearch.Id, "error", err)
+		}
+	}
+}
+
+// check delivers an alert for the changes since the last notification and
+// records the new notification time. The period is only advanced after a
+// successful delivery, so failed deliveries are retried on the next run.
+func check(ctx context.Context, sink Sink, savedSearch *rdf.SavedSearch, until time.Time) error {
+	query, err := solrQuery(savedSearch)
+	if err != nil {
+		return err
+	}
+	since := savedSearch.NotifiedUntil
+	if since.IsZero() {
+		since = savedSearch.Created
+	}
+	result, err := search.FindModifiedEntities(ctx, query, since, until, maxHits)
+	if err != nil {
+		return err
+	}
+	if result.Total > 0 {
+		alert := &Alert{SavedSearch: savedSearch, Since: since, Until: until, Total: result.Total, Hits: result.Hits}
+		err = sink.Deliver(ctx, alert)
+		metrics.AlertDeliveries.WithLabelValues(SinkType, deliveryResult(err)).Inc()
+		if err != nil {
+			return fmt.Errorf("delivering alert: %w", err)
+		}
+		slog.Info("delivered saved search alert", "id", savedSearch.Id, "entities", result.Total, "sink", SinkType)
+	}
+	return rdf.MarkSavedSearchNotified(ctx, savedSearch, until)
+}
+
+// solrQuery decodes the structured or raw query of a saved search.
+func solrQuery(savedSearch *rdf.SavedSearch) (search.SolrQuery, error) {
+	if savedSearch.Query != "" {
+		var query search.Query
+		if err := json.Unmarshal([]byte(savedSearch.Query), &query); err != nil {
+			return search.SolrQuery{}, err
+		}
+		return query.SolrQuery()
+	}
+	var query search.SolrQuery
+	err := json.Unmarshal([]byte(savedSearch.SolrQuery), &query)
+	return query, err
+}
+
+func deliveryResult(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "ok"
+}
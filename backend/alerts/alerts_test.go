@@ -0,0 +1,112 @@
+package alerts
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"rdf-store-backend/rdf"
+	"rdf-store-backend/search"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testAlert(email string) *Alert {
+	until := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	return &Alert{
+		SavedSearch: &rdf.SavedSearch{Id: "urn:uuid:0b0e8c4e-52c4-4a55-9d47-3f8d1e0d6e55", Name: "Soil\r\nBcc: x@example.org", Email: email},
+		Since:       until.Add(-time.Hour),
+		Until:       until,
+		Total:       3,
+		Hits: []search.SearchHit{
+			{Subject: "http://example.org/a", Label: "A"},
+			{Subject: "http://example.org/b"},
+		},
+	}
+}
+
+func TestComposeMailListsHitsAndEncodesSubject(t *testing.T) {
+	message, err := composeMail(testAlert("user@example.org"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := string(message)
+	header, body, _ := strings.Cut(text, "\r\n\r\n")
+	if !strings.Contains(header, "To: user@example.org\r\n") || strings.Contains(header, "\r\nBcc:") {
+		t.Fatalf("unexpected header:\n%s", header)
+	}
+	for _, expected := range []string{"- A\r\n  http://example.org/a", "- http://example.org/b\r\n", "1 more entities"} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("missing %q in body:\n%s", expected, body)
+		}
+	}
+	if _, err := composeMail(testAlert("")); err != errNoRecipient {
+		t.Fatalf("expected missing recipient error, got %v", err)
+	}
+}
+
+func TestMailDropSinkWritesMessage(t *testing.T) {
+	dir := t.TempDir()
+	sink := &mailDropSink{dir: filepath.Join(dir, "drop")}
+	if err := sink.Deliver(t.Context(), testAlert("user@example.org")); err != nil {
+		t.Fatal(err)
+	}
+	files, err := os.ReadDir(sink.dir)
+	if err != nil || len(files) != 1 || !strings.HasSuffix(files[0].Name(), "-0b0e8c4e-52c4-4a55-9d47-3f8d1e0d6e55.eml") {
+		t.Fatalf("unexpected mail drop content: %v, %v", files, err)
+	}
+}
+
+func TestWebhookSinkPostsAlert(t *testing.T) {
+	var received Alert
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	sink := &webhookSink{url: server.URL, client: server.Client()}
+	if err := sink.Deliver(t.Context(), testAlert("")); err != nil {
+		t.Fatal(err)
+	}
+	if received.Total != 3 || len(received.Hits) != 2 || received.SavedSearch.Id != "urn:uuid:0b0e8c4e-52c4-4a55-9d47-3f8d1e0d6e55" {
+		t.Fatalf("unexpected alert: %+v", received)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+	sink = &webhookSink{url: failing.URL, client: failing.Client()}
+	if err := sink.Deliver(t.Context(), testAlert("")); err == nil {
+		t.Fatal("expected delivery error")
+	}
+}
+
+func TestSolrQueryDecodesStructuredAndRawQueries(t *testing.T) {
+	query, err := solrQuery(&rdf.SavedSearch{Query: `{"profile":"http://example.org/P"}`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(query.Filter) != 2 || query.Filter[1] != `shape:"http\:\/\/example.org\/P"` {
+		t.Fatalf("unexpected structured filters %q", query.Filter)
+	}
+	query, err = solrQuery(&rdf.SavedSearch{SolrQuery: `{"query":"*","filter":["creator:\"x\""],"limit":10}`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query.Query != "*" || len(query.Filter) != 1 {
+		t.Fatalf("unexpected raw query %+v", query)
+	}
+}
+
+func TestNewSinkRejectsUnknownType(t *testing.T) {
+	if _, err := newSink("pigeon"); err == nil {
+		t.Fatal("expected error for unknown sink")
+	}
+}
@@ -0,0 +1,147 @@
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"os"
+	"path"
+	"path/filepath"
+	"rdf-store-backend/base"
+	"rdf-store-backend/httpclient"
+	"strings"
+	"time"
+)
+
+// Sink types selectable with ALERT_SINK.
+const (
+	SinkWebhook  = "webhook"
+	SinkMailDrop = "maildrop"
+	SinkSMTP     = "smtp"
+)
+
+var webhookURL = base.EnvVar("ALERT_WEBHOOK_URL", "")
+var mailDropDir = base.EnvVar("ALERT_MAILDROP_DIR", path.Join("local", "alerts"))
+var mailFrom = base.EnvVar("ALERT_MAIL_FROM", "rdf-store@localhost")
+var smtpAddress = net.JoinHostPort(base.EnvVar("SMTP_HOST", "localhost"), base.EnvVar("SMTP_PORT", "25"))
+var webhookClient = httpclient.New("webhook", httpclient.Timeout("ALERT_WEBHOOK_TIMEOUT", 10))
+
+var errNoRecipient = errors.New("saved search has no alert email address")
+
+// Sink delivers alerts.
+type Sink interface {
+	Deliver(ctx context.Context, alert *Alert) error
+}
+
+// newSink creates the sink of the given type.
+// It returns an error for unknown types or missing configuration.
+func newSink(sinkType string) (Sink, error) {
+	switch sinkType {
+	case SinkWebhook:
+		if webhookURL == "" {
+			return nil, errors.New("ALERT_WEBHOOK_URL is not set")
+		}
+		return &webhookSink{url: webhookURL, client: webhookClient}, nil
+	case SinkMailDrop:
+		return &mailDropSink{dir: mailDropDir}, nil
+	case SinkSMTP:
+		return &smtpSink{address: smtpAddress}, nil
+	}
+	return nil, fmt.Errorf("unknown alert sink %q, expected %s, %s or %s", sinkType, SinkWebhook, SinkMailDrop, SinkSMTP)
+}
+
+// webhookSink posts alerts as JSON.
+type webhookSink struct {
+	url    string
+	client *http.Client
+}
+
+func (sink *webhookSink) Deliver(ctx context.Context, alert *Alert) error {
+	data, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sink.url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := httpclient.Do(sink.client, req, false)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %s", resp.Status)
+	}
+	return nil
+}
+
+// mailDropSink writes alert mails as .eml files into a directory, e.g. for
+// pickup by a local mail transfer agent.
+type mailDropSink struct {
+	dir string
+}
+
+func (sink *mailDropSink) Deliver(ctx context.Context, alert *Alert) error {
+	message, err := composeMail(alert)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(sink.dir, 0o755); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s.eml", alert.Until.UTC().Format("20060102T150405.000000000Z"), strings.TrimPrefix(alert.SavedSearch.Id, "urn:uuid:"))
+	return os.WriteFile(filepath.Join(sink.dir, name), message, 0o644)
+}
+
+// smtpSink sends alert mails through an SMTP server without authentication,
+// e.g. a local relay or test mail server.
+type smtpSink struct {
+	address string
+}
+
+func (sink *smtpSink) Deliver(ctx context.Context, alert *Alert) error {
+	message, err := composeMail(alert)
+	if err != nil {
+		return err
+	}
+	return smtp.SendMail(sink.address, nil, mailFrom, []string{alert.SavedSearch.Email}, message)
+}
+
+// composeMail renders an alert as a plain text mail to the saved search's
+// email address.
+// It returns errNoRecipient if the saved search has no email address.
+func composeMail(alert *Alert) ([]byte, error) {
+	if alert.SavedSearch.Email == "" {
+		return nil, errNoRecipient
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", mailFrom)
+	fmt.Fprintf(&buf, "To: %s\r\n", alert.SavedSearch.Email)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", "New or changed results for "+alert.SavedSearch.Name))
+	fmt.Fprintf(&buf, "Date: %s\r\n", alert.Until.Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	fmt.Fprintf(&buf, "%d entities matching your saved search %q were created or changed between %s and %s.\r\n\r\n",
+		alert.Total, alert.SavedSearch.Name, alert.Since.Format(time.RFC3339), alert.Until.Format(time.RFC3339))
+	for _, hit := range alert.Hits {
+		label := hit.Label
+		if label == "" {
+			label = hit.Subject
+		}
+		fmt.Fprintf(&buf, "- %s\r\n  %s\r\n", label, hit.Subject)
+	}
+	if len(alert.Hits) < alert.Total {
+		fmt.Fprintf(&buf, "\r\n%d more entities are not listed.\r\n", alert.Total-len(alert.Hits))
+	}
+	return buf.Bytes(), nil
+}
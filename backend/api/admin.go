@@ -0,0 +1,49 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"rdf-store-backend/search"
+
+	"github.com/gin-gonic/gin"
+)
+
+// init registers administrative endpoints on the router.
+func init() {
+	Router.POST(BasePath+"/admin/reindex", handleReindex)
+}
+
+// handleReindex rebuilds conformance and search documents of the resources
+// selected by the profile, since and resource parameters.
+func handleReindex(c *gin.Context) {
+	if granted, _ := adminAccessGranted(c.Request.Header); !granted {
+		c.JSON(http.StatusForbidden, gin.H{"error": "not allowed"})
+		return
+	}
+	scope, err := search.ParseReindexScope(formOrQuery(c, "profile"), formOrQuery(c, "since"), formOrQuery(c, "resource"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	// a full rebuild recreates the collection and is only available through the CLI
+	if scope.IsEmpty() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing request parameter 'profile', 'since' or 'resource'"})
+		return
+	}
+	result, err := search.ReindexScoped(c.Request.Context(), scope)
+	if err != nil {
+		slog.Error("failed reindexing", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// formOrQuery reads a form parameter and falls back to the query string.
+// It returns the parameter value or an empty string.
+func formOrQuery(c *gin.Context, name string) string {
+	if value := c.PostForm(name); value != "" {
+		return value
+	}
+	return c.Query(name)
+}
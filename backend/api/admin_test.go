@@ -0,0 +1,34 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestHandleReindexRequiresScope(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	response := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(response)
+	context.Request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(""))
+	handleReindex(context)
+
+	if response.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", response.Code, response.Body)
+	}
+}
+
+func TestHandleReindexRejectsInvalidTimestamp(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	response := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(response)
+	context.Request = httptest.NewRequest(http.MethodPost, "/?since=yesterday", nil)
+	handleReindex(context)
+
+	if response.Code != http.StatusBadRequest || !strings.Contains(response.Body.String(), "invalid timestamp") {
+		t.Fatalf("expected 400 for invalid timestamp, got %d: %s", response.Code, response.Body)
+	}
+}
@@ -3,12 +3,17 @@ package api
 import (
 	"net/http"
 	"rdf-store-backend/base"
+	"rdf-store-backend/metrics"
+	"rdf-store-backend/search"
+	"rdf-store-backend/tracing"
 	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
 type JSONError struct {
@@ -18,6 +23,10 @@ type JSONError struct {
 var Router = gin.New()
 var BasePath = "/api/v1"
 var livelinessEndpoint = "/healthz"
+var metricsEndpoint = "/metrics"
+
+// probePaths are polled by orchestration and monitoring and are neither logged nor traced.
+var probePaths = []string{BasePath + livelinessEndpoint, BasePath + readinessEndpoint, BasePath + metricsEndpoint}
 
 // init configures CORS and base routes for the API router.
 func init() {
@@ -29,16 +38,37 @@ func init() {
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	})
-	// exclude liveliness checks from access logs
+	// exclude liveliness and readiness checks and metrics scrapes from access logs
 	Router.Use(gin.LoggerWithConfig(gin.LoggerConfig{
-		SkipPaths: []string{BasePath + livelinessEndpoint},
+		SkipPaths: probePaths,
 	}))
 	Router.Use(gin.Recovery())
+	Router.Use(otelgin.Middleware(tracing.ServiceName, otelgin.WithFilter(isTracedRequest)))
+	Router.Use(recordRequestMetrics)
 	Router.Use(corsConfig)
 	Router.SetTrustedProxies(nil)
 	Router.UseRawPath = true
 	Router.GET(BasePath+livelinessEndpoint, handleHealthz)
 	Router.GET(BasePath+"/config", handleConfig)
+	Router.GET(BasePath+metricsEndpoint, gin.WrapH(metrics.Handler()))
+}
+
+// recordRequestMetrics counts requests and observes their latency per route
+// template, so that path parameters do not create separate series.
+func recordRequestMetrics(c *gin.Context) {
+	start := time.Now()
+	c.Next()
+	route := c.FullPath()
+	if route == "" {
+		route = "unmatched"
+	}
+	metrics.HTTPRequests.WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).Inc()
+	metrics.HTTPRequestDuration.WithLabelValues(c.Request.Method, route).Observe(time.Since(start).Seconds())
+}
+
+// isTracedRequest excludes liveliness and readiness checks and metrics scrapes from tracing.
+func isTracedRequest(r *http.Request) bool {
+	return !slices.Contains(probePaths, r.URL.Path)
 }
 
 // handleHealthz returns a lightweight health response for liveness checks.
@@ -55,6 +85,7 @@ func handleConfig(c *gin.Context) {
 		Email:       c.Request.Header.Get(base.AuthEmailHeader),
 		WriteAccess: writeAccess,
 	}
+	config.ConversionPredicates = search.QuantityPredicates()
 	c.JSON(http.StatusOK, config)
 }
 
@@ -77,3 +108,18 @@ func writeAccessGranted(h http.Header) (granted bool, user string) {
 	}
 	return
 }
+
+// adminAccessGranted checks headers to determine access to admin endpoints.
+// Without a configured admin group, users with write access are admins.
+// It returns whether admin access is granted and the resolved user name.
+func adminAccessGranted(h http.Header) (granted bool, user string) {
+	if !base.Configuration.AuthEnabled || len(base.AuthAdminGroup) == 0 {
+		return writeAccessGranted(h)
+	}
+	user = h.Get(base.AuthUserHeader)
+	if len(user) == 0 {
+		return
+	}
+	granted = slices.Contains(strings.Split(h.Get(base.AuthGroupsHeader), ","), base.AuthAdminGroup)
+	return
+}
@@ -0,0 +1,22 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMetricsEndpointServesRouteMetrics(t *testing.T) {
+	Router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, BasePath+livelinessEndpoint, nil))
+
+	response := httptest.NewRecorder()
+	Router.ServeHTTP(response, httptest.NewRequest(http.MethodGet, BasePath+metricsEndpoint, nil))
+	if response.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", response.Code, response.Body)
+	}
+	expected := `rdfstore_http_requests_total{method="GET",route="` + BasePath + livelinessEndpoint + `",status="200"}`
+	if !strings.Contains(response.Body.String(), expected) {
+		t.Fatalf("expected %s in metrics output:\n%s", expected, response.Body)
+	}
+}
@@ -0,0 +1,119 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"rdf-store-backend/export"
+	"rdf-store-backend/search"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// init registers the export endpoint.
+func init() {
+	Router.POST(BasePath+"/export", handleExport)
+}
+
+// exportRequest is the body of POST /export. At most one of Query and
+// SolrQuery may be set; all entities are exported without either.
+type exportRequest struct {
+	Format    string          `json:"format"`
+	Query     json.RawMessage `json:"query,omitempty"`
+	SolrQuery json.RawMessage `json:"solrQuery,omitempty"`
+	// Profile defaults to the profile of a structured query.
+	Profile string     `json:"profile,omitempty"`
+	Columns [][]string `json:"columns,omitempty"`
+	// Language defaults to the language of a structured query.
+	Language string `json:"language,omitempty"`
+}
+
+// handleExport streams all entities matching a search as CSV, zipped Turtle,
+// N-Quads or JSON-LD.
+func handleExport(c *gin.Context) {
+	var request exportRequest
+	if err := json.NewDecoder(c.Request.Body).Decode(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	options, err := newExportOptions(request)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx := c.Request.Context()
+	result, err := export.Prepare(ctx, options)
+	if err != nil {
+		if errors.Is(err, export.ErrInvalidExport) || errors.Is(err, export.ErrTooManyEntities) || errors.Is(err, search.ErrInvalidQuery) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		slog.Error("failed preparing export", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("Content-Type", result.ContentType())
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, result.Filename()))
+	c.Header("X-Total-Count", strconv.Itoa(result.Total()))
+	c.Status(http.StatusOK)
+	if err := result.Write(ctx, c.Writer); err != nil {
+		slog.Error("failed writing export", "format", options.Format, "error", err)
+		if !c.Writer.Written() {
+			c.Header("Content-Disposition", "")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		abortResponse(c)
+	}
+}
+
+// newExportOptions decodes the search of an export request.
+// It returns an error for malformed queries.
+func newExportOptions(request exportRequest) (export.Options, error) {
+	options := export.Options{Format: request.Format, Profile: request.Profile, Columns: request.Columns, Language: request.Language}
+	switch {
+	case len(request.Query) > 0 && len(request.SolrQuery) > 0:
+		return options, errors.New("at most one of query and solrQuery is allowed")
+	case len(request.Query) > 0:
+		var query search.Query
+		if err := json.Unmarshal(request.Query, &query); err != nil {
+			return options, err
+		}
+		solrQuery, err := query.SolrQuery()
+		if err != nil {
+			return options, err
+		}
+		options.Query = solrQuery
+		if options.Profile == "" {
+			options.Profile = query.Profile
+		}
+		if options.Language == "" {
+			options.Language = query.Language
+		}
+	case len(request.SolrQuery) > 0:
+		// apply the restrictions of the Solr proxy
+		data, err := filterSolrJSON(request.SolrQuery)
+		if err != nil {
+			return options, err
+		}
+		if err := json.Unmarshal(data, &options.Query); err != nil {
+			return options, err
+		}
+	}
+	return options, nil
+}
+
+// abortResponse closes the connection of a response that failed after its
+// status was sent, so clients see an incomplete download instead of a
+// truncated but seemingly complete one.
+func abortResponse(c *gin.Context) {
+	conn, _, err := http.NewResponseController(c.Writer).Hijack()
+	if err != nil {
+		c.Abort()
+		return
+	}
+	conn.Close()
+}
@@ -0,0 +1,42 @@
+package api
+
+import (
+	"encoding/json"
+	"rdf-store-backend/export"
+	"slices"
+	"testing"
+)
+
+func TestNewExportOptionsDecodesSearches(t *testing.T) {
+	options, err := newExportOptions(exportRequest{
+		Format: export.FormatCSV,
+		Query:  json.RawMessage(`{"profile":"http://example.org/P","language":"de"}`),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if options.Profile != "http://example.org/P" || options.Language != "de" || len(options.Query.Filter) != 2 {
+		t.Fatalf("unexpected options %+v", options)
+	}
+
+	options, err = newExportOptions(exportRequest{
+		Format:    export.FormatNQuads,
+		SolrQuery: json.RawMessage(`{"query":"*","filter":"shape:x","limit":5}`),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if options.Query.Query != "*" || !slices.Equal(options.Query.Filter, []string{"shape:x", entityFilter}) {
+		t.Fatalf("unexpected options %+v", options)
+	}
+
+	for name, request := range map[string]exportRequest{
+		"both":       {Query: json.RawMessage(`{}`), SolrQuery: json.RawMessage(`{}`)},
+		"structured": {Query: json.RawMessage(`{"sort":"label"}`)},
+		"raw":        {SolrQuery: json.RawMessage(`{"filter":"{!join from=id to=id}x"}`)},
+	} {
+		if _, err := newExportOptions(request); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
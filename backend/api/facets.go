@@ -0,0 +1,86 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"rdf-store-backend/base"
+	"rdf-store-backend/search"
+
+	"github.com/gin-gonic/gin"
+)
+
+const defaultFacetValueLimit = 100
+
+// init registers the path statistics endpoints.
+func init() {
+	Router.GET(BasePath+"/facets", handleGetFacets)
+	Router.GET(BasePath+"/facets/hierarchy", handleGetHierarchyFacets)
+}
+
+// handleGetFacets returns value statistics for the indexed paths of a
+// profile. Repeated path parameters are the segments of a single path.
+func handleGetFacets(c *gin.Context) {
+	profile := c.Query("profile")
+	if profile == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing profile parameter"})
+		return
+	}
+	limit, err := queryInt(c, "limit", defaultFacetValueLimit)
+	if err != nil || limit < 1 || limit > base.Configuration.SolrMaxAggregations {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("limit must be between 1 and %d", base.Configuration.SolrMaxAggregations)})
+		return
+	}
+	statistics, err := search.PathValueStatistics(c.Request.Context(), search.StatisticsQuery{
+		Profile:      profile,
+		Path:         c.QueryArray("path"),
+		Language:     c.Query("language"),
+		Limit:        limit,
+		Unit:         c.Query("unit"),
+		QuantityKind: c.Query("quantityKind"),
+		IsDelta:      c.Query("isDelta") == "true",
+	})
+	if err != nil {
+		if errors.Is(err, search.ErrInvalidQuery) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		slog.Error("failed computing path statistics", "profile", profile, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, statistics)
+}
+
+// handleGetHierarchyFacets returns the entity counts per concept of a path,
+// nested along the skos:broader hierarchy of the concepts.
+func handleGetHierarchyFacets(c *gin.Context) {
+	profile := c.Query("profile")
+	path := c.QueryArray("path")
+	if profile == "" || len(path) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing profile or path parameter"})
+		return
+	}
+	limit, err := queryInt(c, "limit", defaultFacetValueLimit)
+	if err != nil || limit < 1 || limit > base.Configuration.SolrMaxAggregations {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("limit must be between 1 and %d", base.Configuration.SolrMaxAggregations)})
+		return
+	}
+	concepts, err := search.HierarchyFacets(c.Request.Context(), search.StatisticsQuery{
+		Profile:  profile,
+		Path:     path,
+		Language: c.Query("language"),
+		Limit:    limit,
+	})
+	if err != nil {
+		if errors.Is(err, search.ErrInvalidQuery) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		slog.Error("failed computing hierarchy facets", "profile", profile, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, concepts)
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"rdf-store-backend/rdf"
@@ -11,7 +12,7 @@ import (
 
 const defaultNeighborhoodLimit = 25
 
-type graphNeighborhoodGetter func(subject, direction string, offset, limit int) (*rdf.GraphNeighborhood, error)
+type graphNeighborhoodGetter func(ctx context.Context, subject, direction string, offset, limit int) (*rdf.GraphNeighborhood, error)
 
 func init() {
 	Router.GET(BasePath+"/graph/neighborhood", handleGetGraphNeighborhood)
@@ -35,7 +36,7 @@ func handleGetGraphNeighborhoodWith(c *gin.Context, getNeighborhood graphNeighbo
 		return
 	}
 
-	page, err := getNeighborhood(subject, direction, offset, limit)
+	page, err := getNeighborhood(c.Request.Context(), subject, direction, offset, limit)
 	if err != nil {
 		status := http.StatusInternalServerError
 		if errors.Is(err, rdf.ErrInvalidNeighborhoodRequest) {
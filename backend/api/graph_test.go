@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -11,7 +12,7 @@ import (
 )
 
 func TestGraphNeighborhoodReturnsJSONPage(t *testing.T) {
-	getNeighborhood := func(subject, direction string, offset, limit int) (*rdf.GraphNeighborhood, error) {
+	getNeighborhood := func(ctx context.Context, subject, direction string, offset, limit int) (*rdf.GraphNeighborhood, error) {
 		if subject != "https://example.org/root" || direction != rdf.NeighborhoodIncoming || offset != 5 || limit != 10 {
 			t.Fatalf("unexpected request: %q, %q, %d, %d", subject, direction, offset, limit)
 		}
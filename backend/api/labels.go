@@ -17,7 +17,7 @@ func init() {
 func handleLabels(c *gin.Context) {
 	language := c.PostForm("lang")
 	ids := c.PostFormArray("id")
-	labels, err := rdf.GetLabels(language, ids)
+	labels, err := rdf.GetLabels(c.Request.Context(), language, ids)
 	if err != nil {
 		slog.Error("failed getting labels", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
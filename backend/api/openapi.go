@@ -1,9 +1,12 @@
 package api
 
 import (
+	"fmt"
 	"log/slog"
 	"net/http"
 	"rdf-store-backend/base"
+	"rdf-store-backend/export"
+	"rdf-store-backend/search"
 	"strings"
 
 	"github.com/getkin/kin-openapi/openapi3"
@@ -113,7 +116,13 @@ func addSchemas(spec *openapi3.T) {
 		WithProperty("rdfNamespace", openapi3.NewStringSchema()).
 		WithProperty("conversionUnit", openapi3.NewStringSchema()).
 		WithProperty("conversionQuantity", openapi3.NewStringSchema()).
-		WithProperty("conversionValue", openapi3.NewStringSchema()))
+		WithProperty("conversionValue", openapi3.NewStringSchema()).
+		WithProperty("conversionPredicates", openapi3.NewArraySchema().WithItems(openapi3.NewObjectSchema().
+			WithProperty("unit", openapi3.NewStringSchema()).
+			WithProperty("quantityKind", openapi3.NewStringSchema()).
+			WithProperty("value", openapi3.NewStringSchema()).
+			WithProperty("kindOptional", openapi3.NewBoolSchema()))).
+		WithProperty("textLanguages", openapi3.NewArraySchema().WithItems(openapi3.NewStringSchema())))
 	spec.Components.Schemas["LabelsResponse"] = openapi3.NewSchemaRef("", openapi3.NewSchema().
 		WithAdditionalProperties(openapi3.NewStringSchema()))
 	spec.Components.Schemas["ShapeInstancesResponse"] = openapi3.NewSchemaRef("", openapi3.NewSchema().
@@ -151,6 +160,155 @@ func addSchemas(spec *openapi3.T) {
 	quantitiesResponse := openapi3.NewArraySchema()
 	quantitiesResponse.Items = quantitiesResponseItems.NewRef()
 	spec.Components.Schemas["QuantitiesResponse"] = openapi3.NewSchemaRef("", quantitiesResponse)
+	spec.Components.Schemas["UnitConversion"] = openapi3.NewSchemaRef("", openapi3.NewObjectSchema().
+		WithProperty("value", openapi3.NewFloat64Schema()).
+		WithProperty("fromUnit", openapi3.NewStringSchema()).
+		WithProperty("toUnit", openapi3.NewStringSchema()).
+		WithProperty("isDelta", openapi3.NewBoolSchema()).
+		WithProperty("result", openapi3.NewFloat64Schema()).
+		WithRequired([]string{"value", "fromUnit", "toUnit"}))
+	spec.Components.Schemas["UnitDescription"] = openapi3.NewSchemaRef("", openapi3.NewObjectSchema().
+		WithProperty("uri", openapi3.NewStringSchema()).
+		WithProperty("label", openapi3.NewStringSchema()).
+		WithProperty("symbol", openapi3.NewStringSchema()).
+		WithProperty("multiplier", openapi3.NewFloat64Schema()).
+		WithProperty("offset", openapi3.NewFloat64Schema()).
+		WithProperty("dimensionVector", openapi3.NewStringSchema()).
+		WithProperty("canonical", openapi3.NewBoolSchema()).
+		WithRequired([]string{"uri", "multiplier", "offset", "dimensionVector"}))
+	spec.Components.Schemas["UnitCatalog"] = openapi3.NewSchemaRef("", openapi3.NewObjectSchema().
+		WithProperty("versions", openapi3.NewArraySchema().WithItems(openapi3.NewStringSchema())).
+		WithProperty("units", openapi3.NewIntegerSchema().WithMin(0)).
+		WithProperty("quantityKinds", openapi3.NewIntegerSchema().WithMin(0)).
+		WithProperty("conflicts", openapi3.NewArraySchema().WithItems(openapi3.NewObjectSchema().
+			WithProperty("catalog", openapi3.NewStringSchema()).
+			WithProperty("unit", openapi3.NewStringSchema()).
+			WithProperty("quantityKind", openapi3.NewStringSchema()).
+			WithProperty("existing", openapi3.NewStringSchema()).
+			WithProperty("replacement", openapi3.NewStringSchema()))).
+		WithRequired([]string{"versions", "units", "quantityKinds", "conflicts"}))
+	spec.Components.Schemas["ReindexResponse"] = openapi3.NewSchemaRef("", openapi3.NewObjectSchema().
+		WithProperty("resources", openapi3.NewIntegerSchema().WithMin(0)).
+		WithProperty("indexed", openapi3.NewIntegerSchema().WithMin(0)).
+		WithProperty("failed", openapi3.NewArraySchema().WithItems(openapi3.NewStringSchema())))
+	componentStatus := openapi3.NewObjectSchema().
+		WithProperty("status", openapi3.NewStringSchema().WithEnum(StatusUp, StatusDown, StatusPending, StatusFailed)).
+		WithProperty("error", openapi3.NewStringSchema()).
+		WithProperty("duration", openapi3.NewStringSchema())
+	spec.Components.Schemas["ReadinessResponse"] = openapi3.NewSchemaRef("", openapi3.NewObjectSchema().
+		WithProperty("ready", openapi3.NewBoolSchema()).
+		WithProperty("components", openapi3.NewObjectSchema().WithAdditionalProperties(componentStatus)))
+	searchCriterion := openapi3.NewObjectSchema().
+		WithProperty("path", openapi3.NewArraySchema().WithItems(openapi3.NewStringSchema()).WithMinItems(1)).
+		WithProperty("operator", openapi3.NewStringSchema().WithEnum(search.OperatorEquals, search.OperatorContains, search.OperatorRange, search.OperatorGeoIntersects, search.OperatorGeoWithin, search.OperatorGeoBBox, search.OperatorGeoDistance, search.OperatorIntervalOverlaps, search.OperatorIntervalWithin, search.OperatorIntervalContains)).
+		WithProperty("value", openapi3.NewStringSchema()).
+		WithProperty("min", openapi3.NewStringSchema()).
+		WithProperty("max", openapi3.NewStringSchema()).
+		WithProperty("datatype", openapi3.NewStringSchema()).
+		WithProperty("distance", openapi3.NewFloat64Schema().WithMin(0)).
+		WithProperty("exact", openapi3.NewBoolSchema()).
+		WithProperty("unit", openapi3.NewStringSchema()).
+		WithProperty("quantityKind", openapi3.NewStringSchema()).
+		WithProperty("isDelta", openapi3.NewBoolSchema()).
+		WithRequired([]string{"path", "operator"})
+	searchCriteria := openapi3.NewArraySchema()
+	searchCriteria.Items = searchCriterion.NewRef()
+	spec.Components.Schemas["SearchRequest"] = openapi3.NewSchemaRef("", openapi3.NewObjectSchema().
+		WithProperty("profile", openapi3.NewStringSchema()).
+		WithProperty("criteria", searchCriteria).
+		WithProperty("fulltext", openapi3.NewStringSchema()).
+		WithProperty("sort", openapi3.NewStringSchema()).
+		WithProperty("offset", openapi3.NewIntegerSchema().WithMin(0)).
+		WithProperty("limit", openapi3.NewIntegerSchema().WithMin(1).WithMax(100)).
+		WithProperty("language", openapi3.NewStringSchema()).
+		WithProperty("geometries", openapi3.NewBoolSchema()))
+	searchHit := openapi3.NewObjectSchema().
+		WithProperty("id", openapi3.NewStringSchema()).
+		WithProperty("resourceId", openapi3.NewStringSchema()).
+		WithProperty("subject", openapi3.NewStringSchema()).
+		WithProperty("label", openapi3.NewStringSchema()).
+		WithProperty("shapes", openapi3.NewArraySchema().WithItems(openapi3.NewStringSchema())).
+		WithProperty("lastModified", openapi3.NewDateTimeSchema()).
+		WithProperty("highlights", openapi3.NewArraySchema().WithItems(openapi3.NewObjectSchema().
+			WithProperty("path", openapi3.NewArraySchema().WithItems(openapi3.NewStringSchema())).
+			WithProperty("snippet", openapi3.NewStringSchema()))).
+		WithProperty("geometries", openapi3.NewArraySchema().WithItems(openapi3.NewObjectSchema().
+			WithProperty("path", openapi3.NewArraySchema().WithItems(openapi3.NewStringSchema())).
+			WithProperty("geometry", openapi3.NewObjectSchema())))
+	searchHits := openapi3.NewArraySchema()
+	searchHits.Items = searchHit.NewRef()
+	spec.Components.Schemas["SearchResponse"] = openapi3.NewSchemaRef("", openapi3.NewObjectSchema().
+		WithProperty("total", openapi3.NewIntegerSchema().WithMin(0)).
+		WithProperty("offset", openapi3.NewIntegerSchema().WithMin(0)).
+		WithProperty("limit", openapi3.NewIntegerSchema().WithMin(1)).
+		WithProperty("hits", searchHits))
+	spec.Components.Schemas["SavedSearchRequest"] = openapi3.NewSchemaRef("", openapi3.NewObjectSchema().
+		WithProperty("name", openapi3.NewStringSchema().WithMinLength(1).WithMaxLength(200)).
+		WithPropertyRef("query", openapi3.NewSchemaRef("#/components/schemas/SearchRequest", nil)).
+		WithProperty("solrQuery", openapi3.NewObjectSchema()).
+		WithProperty("subscribe", openapi3.NewBoolSchema()).
+		WithProperty("email", openapi3.NewStringSchema()).
+		WithRequired([]string{"name"}))
+	spec.Components.Schemas["SavedSearch"] = openapi3.NewSchemaRef("", openapi3.NewObjectSchema().
+		WithProperty("id", openapi3.NewStringSchema()).
+		WithProperty("name", openapi3.NewStringSchema()).
+		WithProperty("creator", openapi3.NewStringSchema()).
+		WithProperty("created", openapi3.NewDateTimeSchema()).
+		WithPropertyRef("query", openapi3.NewSchemaRef("#/components/schemas/SearchRequest", nil)).
+		WithProperty("solrQuery", openapi3.NewObjectSchema()).
+		WithProperty("subscribed", openapi3.NewBoolSchema()).
+		WithProperty("email", openapi3.NewStringSchema()).
+		WithProperty("notifiedUntil", openapi3.NewDateTimeSchema()))
+	spec.Components.Schemas["SubscriptionRequest"] = openapi3.NewSchemaRef("", openapi3.NewObjectSchema().
+		WithProperty("email", openapi3.NewStringSchema()))
+	spec.Components.Schemas["ExportRequest"] = openapi3.NewSchemaRef("", openapi3.NewObjectSchema().
+		WithProperty("format", openapi3.NewStringSchema().WithEnum(export.FormatCSV, export.FormatTurtle, export.FormatNQuads, export.FormatJSONLD)).
+		WithPropertyRef("query", openapi3.NewSchemaRef("#/components/schemas/SearchRequest", nil)).
+		WithProperty("solrQuery", openapi3.NewObjectSchema()).
+		WithProperty("profile", openapi3.NewStringSchema()).
+		WithProperty("columns", openapi3.NewArraySchema().WithItems(openapi3.NewArraySchema().WithItems(openapi3.NewStringSchema()).WithMinItems(1))).
+		WithProperty("language", openapi3.NewStringSchema()).
+		WithRequired([]string{"format"}))
+	rangeStatistics := openapi3.NewObjectSchema().
+		WithProperty("min", openapi3.NewSchema()).
+		WithProperty("max", openapi3.NewSchema()).
+		WithProperty("histogram", openapi3.NewArraySchema().WithItems(openapi3.NewObjectSchema().
+			WithProperty("start", openapi3.NewSchema()).
+			WithProperty("end", openapi3.NewSchema()).
+			WithProperty("count", openapi3.NewIntegerSchema())))
+	spec.Components.Schemas["PathStatistics"] = openapi3.NewSchemaRef("", openapi3.NewObjectSchema().
+		WithProperty("path", openapi3.NewArraySchema().WithItems(openapi3.NewStringSchema())).
+		WithProperty("id", openapi3.NewStringSchema()).
+		WithProperty("datatype", openapi3.NewStringSchema()).
+		WithProperty("entities", openapi3.NewIntegerSchema()).
+		WithProperty("values", openapi3.NewArraySchema().WithItems(openapi3.NewObjectSchema().
+			WithProperty("value", openapi3.NewStringSchema()).
+			WithProperty("label", openapi3.NewStringSchema()).
+			WithProperty("count", openapi3.NewIntegerSchema()))).
+		WithProperty("numbers", rangeStatistics).
+		WithProperty("dates", rangeStatistics).
+		WithProperty("booleans", openapi3.NewObjectSchema().
+			WithProperty("true", openapi3.NewIntegerSchema()).
+			WithProperty("false", openapi3.NewIntegerSchema())))
+	narrowerConcepts := openapi3.NewArraySchema()
+	narrowerConcepts.Items = openapi3.NewSchemaRef("#/components/schemas/ConceptCount", nil)
+	spec.Components.Schemas["ConceptCount"] = openapi3.NewSchemaRef("", openapi3.NewObjectSchema().
+		WithProperty("value", openapi3.NewStringSchema()).
+		WithProperty("label", openapi3.NewStringSchema()).
+		WithProperty("count", openapi3.NewIntegerSchema()).
+		WithProperty("direct", openapi3.NewIntegerSchema()).
+		WithProperty("narrower", narrowerConcepts))
+	spec.Components.Schemas["Suggestion"] = openapi3.NewSchemaRef("", openapi3.NewObjectSchema().
+		WithProperty("text", openapi3.NewStringSchema()).
+		WithProperty("subject", openapi3.NewStringSchema()).
+		WithProperty("resourceId", openapi3.NewStringSchema()).
+		WithProperty("score", openapi3.NewFloat64Schema()))
+	spec.Components.Schemas["RegisteredPath"] = openapi3.NewSchemaRef("", openapi3.NewObjectSchema().
+		WithProperty("id", openapi3.NewStringSchema()).
+		WithProperty("path", openapi3.NewArraySchema().WithItems(openapi3.NewStringSchema())).
+		WithProperty("labels", openapi3.NewArraySchema().WithItems(openapi3.NewStringSchema())).
+		WithProperty("rootShapes", openapi3.NewArraySchema().WithItems(openapi3.NewStringSchema())).
+		WithProperty("datatype", openapi3.NewStringSchema()))
 	spec.Components.Schemas["Error"] = openapi3.NewSchemaRef("", openapi3.NewSchema().
 		WithProperty("error", openapi3.NewStringSchema()))
 }
@@ -167,6 +325,17 @@ func addPaths(spec *openapi3.T) {
 		Tags: []string{TAG_MISC},
 	}})
 
+	spec.Paths.Set("/readyz", &openapi3.PathItem{Get: &openapi3.Operation{
+		Summary:     "Check readiness",
+		Description: "Probes Fuseki, Solr and the SHACL validator and reports whether the initial search index setup and profile sync have finished. Responds with 503 when any component is not up.",
+		OperationID: "getReadiness",
+		Responses: responses(map[string]*openapi3.Response{
+			"200": jsonSchemaResponse(openapi3.NewSchemaRef("#/components/schemas/ReadinessResponse", nil), "OK"),
+			"503": jsonSchemaResponse(openapi3.NewSchemaRef("#/components/schemas/ReadinessResponse", nil), "Service Unavailable"),
+		}),
+		Tags: []string{TAG_MISC},
+	}})
+
 	spec.Paths.Set("/quantities", &openapi3.PathItem{Post: &openapi3.Operation{
 		Summary:     "Resolve SI unit conversions for quantities",
 		Description: "Accepts unit and quantity kind pairs and returns each pair enriched with its SI conversion factors, or null when no conversion exists.",
@@ -180,6 +349,61 @@ func addPaths(spec *openapi3.T) {
 		Tags: []string{TAG_MISC},
 	}})
 
+	spec.Paths.Set("/units/convert", &openapi3.PathItem{Post: &openapi3.Operation{
+		Summary:     "Convert a value between units",
+		Description: "Converts a value from one QUDT unit to another of the same dimension vector. Delta quantities convert without offsets. Responds with 400 for unknown units and different dimension vectors.",
+		OperationID: "convertUnit",
+		RequestBody: &openapi3.RequestBodyRef{Value: jsonRequestBody(openapi3.NewSchemaRef("#/components/schemas/UnitConversion", nil))},
+		Responses: responses(map[string]*openapi3.Response{
+			"200": jsonSchemaResponse(openapi3.NewSchemaRef("#/components/schemas/UnitConversion", nil), "OK"),
+			"400": errorResponse(),
+			"500": errorResponse(),
+		}),
+		Tags: []string{TAG_MISC},
+	}})
+
+	unitDescriptions := openapi3.NewArraySchema()
+	unitDescriptions.Items = openapi3.NewSchemaRef("#/components/schemas/UnitDescription", nil)
+	spec.Paths.Set("/units", &openapi3.PathItem{Get: &openapi3.Operation{
+		Summary:     "List convertible units",
+		Description: "Lists the units of the conversion catalog with the dimension vector of the canonical unit of a quantity kind, or all units without quantity kind.",
+		OperationID: "getUnits",
+		Parameters: openapi3.Parameters{
+			&openapi3.ParameterRef{Value: openapi3.NewQueryParameter("quantityKind").WithDescription("QUDT quantity kind IRI or local name").WithSchema(openapi3.NewStringSchema())},
+			&openapi3.ParameterRef{Value: openapi3.NewQueryParameter("language").WithDescription("Language of unit labels").WithSchema(openapi3.NewStringSchema())},
+		},
+		Responses: responses(map[string]*openapi3.Response{
+			"200": jsonSchemaResponse(unitDescriptions.NewRef(), "OK"),
+			"400": errorResponse(),
+			"500": errorResponse(),
+		}),
+		Tags: []string{TAG_MISC},
+	}})
+
+	spec.Paths.Set("/units/catalog", &openapi3.PathItem{Get: &openapi3.Operation{
+		Summary:     "Describe the active unit catalog",
+		Description: "Reports the versions of the embedded and the merged local unit catalogs, the numbers of units and quantity kinds with canonical unit, and the units and quantity kinds that local catalogs redefined.",
+		OperationID: "getUnitCatalog",
+		Responses: responses(map[string]*openapi3.Response{
+			"200": jsonSchemaResponse(openapi3.NewSchemaRef("#/components/schemas/UnitCatalog", nil), "OK"),
+		}),
+		Tags: []string{TAG_MISC},
+	}})
+
+	spec.Paths.Set("/admin/reindex", &openapi3.PathItem{Post: &openapi3.Operation{
+		Summary:     "Reindex a subset of resources",
+		Description: "Rebuilds shape conformance and search documents of the resources selected by profile, last modification and resource ID. Given parameters are combined, and at least one is required. A full rebuild is only available through the CLI.",
+		OperationID: "reindex",
+		RequestBody: &openapi3.RequestBodyRef{Value: formRequestBody("profile", "since", "resource")},
+		Responses: responses(map[string]*openapi3.Response{
+			"200": jsonSchemaResponse(openapi3.NewSchemaRef("#/components/schemas/ReindexResponse", nil), "OK"),
+			"400": errorResponse(),
+			"403": errorResponse(),
+			"500": errorResponse(),
+		}),
+		Tags: []string{TAG_MISC},
+	}})
+
 	spec.Paths.Set("/labels", &openapi3.PathItem{Post: &openapi3.Operation{
 		Summary:     "Resolve labels for RDF ids",
 		OperationID: "getLabels",
@@ -356,12 +580,211 @@ func addPaths(spec *openapi3.T) {
 		Tags: []string{TAG_RDF},
 	}})
 
+	spec.Paths.Set("/search", &openapi3.PathItem{Post: &openapi3.Operation{
+		Summary:     "Search entities",
+		Description: "Runs a structured query. Criteria reference SHACL paths by their predicate IRIs or qualified property shape IDs and are all required to match. Operators are equals, contains, range and the spatial geo-intersects, geo-within, geo-bbox and geo-distance, whose geometries may be WKT or GeoJSON, and the temporal interval-overlaps, interval-within and interval-contains, which take a (partial) date in value or an interval from min to max, or numbers with a numeric datatype or unit, matching the uncertainty intervals of quantities. Numeric equals, range and interval criteria may give a QUDT unit, optionally with quantityKind and isDelta, and are converted to the canonical unit the values are indexed in. Sortable fields are score, lastModified, resourceId and subject. Hits of a fulltext search are ordered by relevance unless a sort is given.",
+		OperationID: "search",
+		RequestBody: &openapi3.RequestBodyRef{Value: jsonRequestBody(openapi3.NewSchemaRef("#/components/schemas/SearchRequest", nil))},
+		Responses: responses(map[string]*openapi3.Response{
+			"200": jsonSchemaResponse(openapi3.NewSchemaRef("#/components/schemas/SearchResponse", nil), "OK"),
+			"400": errorResponse(),
+			"500": errorResponse(),
+		}),
+		Tags: []string{TAG_SOLR},
+	}})
+
+	spec.Paths.Set("/export", &openapi3.PathItem{Post: &openapi3.Operation{
+		Summary:     "Export search results",
+		Description: "Exports all entities matching a structured query or a Solr JSON request, bounded by EXPORT_MAX_ENTITIES. CSV exports contain a row per entity with columns for SHACL paths of the profile, other formats contain the graphs of the matching resources as zipped Turtle files, N-Quads or JSON-LD.",
+		OperationID: "export",
+		RequestBody: &openapi3.RequestBodyRef{Value: jsonRequestBody(openapi3.NewSchemaRef("#/components/schemas/ExportRequest", nil))},
+		Responses: responses(map[string]*openapi3.Response{
+			"200": openapi3.NewResponse().WithDescription("Export file").
+				WithContent(openapi3.Content{
+					"text/csv":            openapi3.NewMediaType().WithSchema(openapi3.NewStringSchema()),
+					"application/zip":     openapi3.NewMediaType().WithSchema(openapi3.NewStringSchema().WithFormat("binary")),
+					"application/n-quads": openapi3.NewMediaType().WithSchema(openapi3.NewStringSchema()),
+					"application/ld+json": openapi3.NewMediaType().WithSchema(openapi3.NewArraySchema().WithItems(openapi3.NewObjectSchema())),
+				}),
+			"400": errorResponse(),
+			"500": errorResponse(),
+		}),
+		Tags: []string{TAG_SOLR},
+	}})
+
+	pathStatistics := openapi3.NewArraySchema()
+	pathStatistics.Items = openapi3.NewSchemaRef("#/components/schemas/PathStatistics", nil)
+	spec.Paths.Set("/facets", &openapi3.PathItem{Get: &openapi3.Operation{
+		Summary:     "Get path value statistics",
+		Description: "Summarizes the indexed values of the SHACL paths of a profile across its conforming entities: counts of string and IRI values with labels, bounds and histograms of numbers and dates, and boolean counts.",
+		OperationID: "getFacets",
+		Parameters: openapi3.Parameters{
+			&openapi3.ParameterRef{Value: openapi3.NewQueryParameter("profile").WithRequired(true).WithSchema(openapi3.NewStringSchema())},
+			&openapi3.ParameterRef{Value: openapi3.NewQueryParameter("path").WithDescription("Segments of a single path in the notation of search criteria; all indexed paths of the profile when omitted").WithSchema(openapi3.NewArraySchema().WithItems(openapi3.NewStringSchema()))},
+			&openapi3.ParameterRef{Value: openapi3.NewQueryParameter("language").WithDescription("Language of value labels").WithSchema(openapi3.NewStringSchema())},
+			&openapi3.ParameterRef{Value: openapi3.NewQueryParameter("limit").WithDescription("Maximum number of values counted per path; defaults to 100").WithSchema(openapi3.NewIntegerSchema().WithMin(1))},
+			&openapi3.ParameterRef{Value: openapi3.NewQueryParameter("unit").WithDescription("QUDT unit to convert the number statistics of a single path to from the canonical unit they are indexed in").WithSchema(openapi3.NewStringSchema())},
+			&openapi3.ParameterRef{Value: openapi3.NewQueryParameter("quantityKind").WithDescription("QUDT quantity kind selecting the canonical unit; the unit's dimension vector selects it when omitted").WithSchema(openapi3.NewStringSchema())},
+			&openapi3.ParameterRef{Value: openapi3.NewQueryParameter("isDelta").WithDescription("Convert as differences, without offset").WithSchema(openapi3.NewBoolSchema())},
+		},
+		Responses: responses(map[string]*openapi3.Response{
+			"200": jsonSchemaResponse(pathStatistics.NewRef(), "OK"),
+			"400": errorResponse(),
+			"500": errorResponse(),
+		}),
+		Tags: []string{TAG_SOLR},
+	}})
+
+	conceptCounts := openapi3.NewArraySchema()
+	conceptCounts.Items = openapi3.NewSchemaRef("#/components/schemas/ConceptCount", nil)
+	spec.Paths.Set("/facets/hierarchy", &openapi3.PathItem{Get: &openapi3.Operation{
+		Summary:     "Get hierarchical concept counts",
+		Description: "Counts the entities conforming to a profile per concept at a path, including entities having narrower concepts, and nests the counts along the skos:broader hierarchy of the imported taxonomies. direct counts the entities having the concept itself.",
+		OperationID: "getHierarchyFacets",
+		Parameters: openapi3.Parameters{
+			&openapi3.ParameterRef{Value: openapi3.NewQueryParameter("profile").WithRequired(true).WithSchema(openapi3.NewStringSchema())},
+			&openapi3.ParameterRef{Value: openapi3.NewQueryParameter("path").WithRequired(true).WithDescription("Segments of a single path in the notation of search criteria").WithSchema(openapi3.NewArraySchema().WithItems(openapi3.NewStringSchema()))},
+			&openapi3.ParameterRef{Value: openapi3.NewQueryParameter("language").WithDescription("Language of concept labels").WithSchema(openapi3.NewStringSchema())},
+			&openapi3.ParameterRef{Value: openapi3.NewQueryParameter("limit").WithDescription("Maximum number of concepts counted; defaults to 100").WithSchema(openapi3.NewIntegerSchema().WithMin(1))},
+		},
+		Responses: responses(map[string]*openapi3.Response{
+			"200": jsonSchemaResponse(conceptCounts.NewRef(), "OK"),
+			"400": errorResponse(),
+			"500": errorResponse(),
+		}),
+		Tags: []string{TAG_SOLR},
+	}})
+
+	suggestions := openapi3.NewArraySchema()
+	suggestions.Items = openapi3.NewSchemaRef("#/components/schemas/Suggestion", nil)
+	spec.Paths.Set("/suggest", &openapi3.PathItem{Get: &openapi3.Operation{
+		Summary:     "Suggest completions",
+		Description: "Completes a partially typed text from entity labels and text values. Every word of q matches as a prefix. Completions are ranked by relevance and name the entity they were found on.",
+		OperationID: "suggest",
+		Parameters: openapi3.Parameters{
+			&openapi3.ParameterRef{Value: openapi3.NewQueryParameter("q").WithRequired(true).WithSchema(openapi3.NewStringSchema())},
+			&openapi3.ParameterRef{Value: openapi3.NewQueryParameter("profile").WithDescription("Restricts completions to entities conforming to the profile").WithSchema(openapi3.NewStringSchema())},
+			&openapi3.ParameterRef{Value: openapi3.NewQueryParameter("path").WithDescription("Segments of a single path in the notation of search criteria; restricts completions to the text values at the path").WithSchema(openapi3.NewArraySchema().WithItems(openapi3.NewStringSchema()))},
+			&openapi3.ParameterRef{Value: openapi3.NewQueryParameter("limit").WithDescription(fmt.Sprintf("Maximum number of completions; defaults to 10 and must be between 1 and %d", search.MaxSuggestLimit)).WithSchema(openapi3.NewIntegerSchema().WithMin(1).WithMax(search.MaxSuggestLimit))},
+		},
+		Responses: responses(map[string]*openapi3.Response{
+			"200": jsonSchemaResponse(suggestions.NewRef(), "OK"),
+			"400": errorResponse(),
+			"500": errorResponse(),
+		}),
+		Tags: []string{TAG_SOLR},
+	}})
+
+	registeredPaths := openapi3.NewArraySchema()
+	registeredPaths.Items = openapi3.NewSchemaRef("#/components/schemas/RegisteredPath", nil)
+	languageParam := &openapi3.ParameterRef{Value: openapi3.NewQueryParameter("language").WithDescription("Language of segment labels").WithSchema(openapi3.NewStringSchema())}
+	spec.Paths.Set("/paths", &openapi3.PathItem{Get: &openapi3.Operation{
+		Summary:     "List registered paths",
+		Description: "Lists the path IDs stored in the path field of value documents with their SHACL path segments, segment labels, the root shapes reaching them and their datatype.",
+		OperationID: "getPaths",
+		Parameters: openapi3.Parameters{
+			&openapi3.ParameterRef{Value: openapi3.NewQueryParameter("profile").WithDescription("Lists only paths reached from the profile").WithSchema(openapi3.NewStringSchema())},
+			languageParam,
+		},
+		Responses: responses(map[string]*openapi3.Response{
+			"200": jsonSchemaResponse(registeredPaths.NewRef(), "OK"),
+			"500": errorResponse(),
+		}),
+		Tags: []string{TAG_SOLR},
+	}})
+	spec.Paths.Set("/paths/{id}", &openapi3.PathItem{Get: &openapi3.Operation{
+		Summary:     "Resolve path ID",
+		OperationID: "getPath",
+		Parameters:  openapi3.Parameters{pathParam("id"), languageParam},
+		Responses: responses(map[string]*openapi3.Response{
+			"200": jsonSchemaResponse(openapi3.NewSchemaRef("#/components/schemas/RegisteredPath", nil), "OK"),
+			"404": errorResponse(),
+			"500": errorResponse(),
+		}),
+		Tags: []string{TAG_SOLR},
+	}})
+
+	savedSearches := openapi3.NewArraySchema()
+	savedSearches.Items = openapi3.NewSchemaRef("#/components/schemas/SavedSearch", nil)
+	spec.Paths.Set("/saved-searches", &openapi3.PathItem{
+		Get: &openapi3.Operation{
+			Summary:     "List saved searches",
+			Description: "Returns the saved searches of the logged in user.",
+			OperationID: "listSavedSearches",
+			Responses: responses(map[string]*openapi3.Response{
+				"200": jsonSchemaResponse(savedSearches.NewRef(), "OK"),
+				"403": errorResponse(),
+				"500": errorResponse(),
+			}),
+			Tags: []string{TAG_SOLR},
+		},
+		Post: &openapi3.Operation{
+			Summary:     "Save search",
+			Description: "Stores either a structured query as accepted by /search or a Solr JSON request as accepted by the Solr proxy. Subscribed searches deliver alerts about new or changed matching entities.",
+			OperationID: "createSavedSearch",
+			RequestBody: &openapi3.RequestBodyRef{Value: jsonRequestBody(openapi3.NewSchemaRef("#/components/schemas/SavedSearchRequest", nil))},
+			Responses: responses(map[string]*openapi3.Response{
+				"201": jsonSchemaResponse(openapi3.NewSchemaRef("#/components/schemas/SavedSearch", nil), "Created"),
+				"400": errorResponse(),
+				"403": errorResponse(),
+				"500": errorResponse(),
+			}),
+			Tags: []string{TAG_SOLR},
+		},
+	})
+
+	spec.Paths.Set("/saved-searches/{id}", &openapi3.PathItem{Delete: &openapi3.Operation{
+		Summary:     "Delete saved search",
+		OperationID: "deleteSavedSearch",
+		Parameters:  openapi3.Parameters{pathParam("id")},
+		Responses: responses(map[string]*openapi3.Response{
+			"204": openapi3.NewResponse().WithDescription("Deleted"),
+			"403": errorResponse(),
+			"404": errorResponse(),
+			"500": errorResponse(),
+		}),
+		Tags: []string{TAG_SOLR},
+	}})
+
+	subscriptionBody := jsonRequestBody(openapi3.NewSchemaRef("#/components/schemas/SubscriptionRequest", nil))
+	subscriptionBody.Required = false
+	spec.Paths.Set("/saved-searches/{id}/subscription", &openapi3.PathItem{
+		Put: &openapi3.Operation{
+			Summary:     "Subscribe to saved search",
+			Description: "Enables alerts about entities created or changed from now on. The email address defaults to the one of the logged in user.",
+			OperationID: "subscribeSavedSearch",
+			Parameters:  openapi3.Parameters{pathParam("id")},
+			RequestBody: &openapi3.RequestBodyRef{Value: subscriptionBody},
+			Responses: responses(map[string]*openapi3.Response{
+				"200": jsonSchemaResponse(openapi3.NewSchemaRef("#/components/schemas/SavedSearch", nil), "OK"),
+				"400": errorResponse(),
+				"403": errorResponse(),
+				"404": errorResponse(),
+				"500": errorResponse(),
+			}),
+			Tags: []string{TAG_SOLR},
+		},
+		Delete: &openapi3.Operation{
+			Summary:     "Unsubscribe from saved search",
+			OperationID: "unsubscribeSavedSearch",
+			Parameters:  openapi3.Parameters{pathParam("id")},
+			Responses: responses(map[string]*openapi3.Response{
+				"200": jsonSchemaResponse(openapi3.NewSchemaRef("#/components/schemas/SavedSearch", nil), "OK"),
+				"403": errorResponse(),
+				"404": errorResponse(),
+				"500": errorResponse(),
+			}),
+			Tags: []string{TAG_SOLR},
+		},
+	})
+
 	spec.Paths.Set("/solr/{collection}/schema", &openapi3.PathItem{Get: &openapi3.Operation{
 		Summary:     "Proxy Solr schema request",
 		OperationID: "solrSchema",
 		Parameters:  openapi3.Parameters{pathParam("collection")},
 		Responses: responses(map[string]*openapi3.Response{
 			"200": openapi3.NewResponse().WithDescription("Solr schema response"),
+			"400": errorResponse(),
 			"500": errorResponse(),
 		}),
 		Tags: []string{TAG_SOLR},
@@ -373,6 +796,7 @@ func addPaths(spec *openapi3.T) {
 		Parameters:  openapi3.Parameters{pathParam("collection")},
 		Responses: responses(map[string]*openapi3.Response{
 			"200": openapi3.NewResponse().WithDescription("Solr select response"),
+			"400": errorResponse(),
 			"500": errorResponse(),
 		}),
 		Tags: []string{TAG_SOLR},
@@ -383,21 +807,24 @@ func addPaths(spec *openapi3.T) {
 			Summary:     "Proxy Solr query request",
 			OperationID: "solrQueryGet",
 			Parameters: openapi3.Parameters{&openapi3.ParameterRef{
-				Value: openapi3.NewQueryParameter("query").WithRequired(true).WithSchema(openapi3.NewStringSchema()),
+				Value: openapi3.NewQueryParameter("q").WithRequired(true).WithSchema(openapi3.NewStringSchema()),
 			}, pathParam("collection")},
 			Responses: responses(map[string]*openapi3.Response{
 				"200": openapi3.NewResponse().WithDescription("Solr query response"),
+				"400": errorResponse(),
 				"500": errorResponse(),
 			}),
 			Tags: []string{TAG_SOLR},
 		},
 		Post: &openapi3.Operation{
 			Summary:     "Proxy Solr query request",
+			Description: "Accepts a Solr JSON request with the members query, filter, sort, offset, limit, fields and facet.",
 			OperationID: "solrQueryPost",
-			RequestBody: &openapi3.RequestBodyRef{Value: formRequestBody("query")},
+			RequestBody: &openapi3.RequestBodyRef{Value: jsonRequestBody(openapi3.NewObjectSchema().NewRef())},
 			Parameters:  openapi3.Parameters{pathParam("collection")},
 			Responses: responses(map[string]*openapi3.Response{
 				"200": openapi3.NewResponse().WithDescription("Solr query response"),
+				"400": errorResponse(),
 				"500": errorResponse(),
 			}),
 			Tags: []string{TAG_SOLR},
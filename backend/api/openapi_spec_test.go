@@ -20,7 +20,7 @@ func TestApiSpecValidates(t *testing.T) {
 	if err := doc.Validate(t.Context()); err != nil {
 		t.Fatal(err)
 	}
-	for _, path := range []string{"/quantities", "/config", "/labels", "/resource", "/resource/{id}", "/profiles", "/profile/{id}", "/class-instances", "/conforming-resources", "/graph/neighborhood", "/sparql/query", "/rdfproxy", "/solr/{collection}/schema", "/solr/{collection}/select", "/solr/{collection}/query"} {
+	for _, path := range []string{"/quantities", "/units", "/units/convert", "/units/catalog", "/config", "/readyz", "/admin/reindex", "/labels", "/resource", "/resource/{id}", "/profiles", "/profile/{id}", "/class-instances", "/conforming-resources", "/graph/neighborhood", "/sparql/query", "/rdfproxy", "/search", "/export", "/facets", "/facets/hierarchy", "/suggest", "/paths", "/paths/{id}", "/saved-searches", "/saved-searches/{id}", "/saved-searches/{id}/subscription", "/solr/{collection}/schema", "/solr/{collection}/select", "/solr/{collection}/query"} {
 		if doc.Paths.Find(path) == nil {
 			t.Errorf("missing path %s", path)
 		}
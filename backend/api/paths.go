@@ -0,0 +1,43 @@
+package api
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"rdf-store-backend/rdf"
+	"rdf-store-backend/search"
+
+	"github.com/gin-gonic/gin"
+)
+
+// init registers the path registry endpoints.
+func init() {
+	Router.GET(BasePath+"/paths", handleGetPaths)
+	Router.GET(BasePath+"/paths/:id", handleGetPath)
+}
+
+// handleGetPaths lists the registered paths, optionally of a single profile.
+func handleGetPaths(c *gin.Context) {
+	paths, err := search.RegisteredPaths(c.Request.Context(), c.Query("profile"), c.Query("language"))
+	if err != nil {
+		slog.Error("failed loading registered paths", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, paths)
+}
+
+// handleGetPath resolves a path ID to its SHACL path.
+func handleGetPath(c *gin.Context) {
+	path, err := search.FindRegisteredPath(c.Request.Context(), c.Param("id"), c.Query("language"))
+	if err != nil {
+		if errors.Is(err, rdf.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		slog.Error("failed resolving path", "id", c.Param("id"), "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, path)
+}
@@ -54,10 +54,10 @@ func handleRdfProxy(c *gin.Context) {
 		}
 		var err error
 		// check if URL references a resource
-		data, _, err = rdf.GetResource(url, true)
+		data, _, err = rdf.GetResource(c.Request.Context(), url, true)
 		if err != nil {
 			// URL refences no profile or resource, so try to load URL from cache or from the web
-			data, err = base.CacheLoad(url, filterClientAccept(c.Request))
+			data, err = base.CacheLoad(c.Request.Context(), url, filterClientAccept(c.Request))
 			if err != nil {
 				slog.Error("failed proxying", "url", url, "error", err)
 				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -8,8 +9,11 @@ import (
 	"net/http/httputil"
 	"net/url"
 	"rdf-store-backend/base"
+	"rdf-store-backend/httpclient"
+	"rdf-store-backend/metrics"
 	"rdf-store-backend/rdf"
 	"rdf-store-backend/search"
+	"rdf-store-backend/tracing"
 	"sort"
 	"strings"
 
@@ -28,6 +32,7 @@ func init() {
 		panic(err)
 	}
 	fusekiProxy = httputil.NewSingleHostReverseProxy(fusekiProxyTarget)
+	fusekiProxy.Transport = tracing.Transport(httpclient.NewTransport(), metrics.ServiceFuseki)
 	fusekiProxy.ModifyResponse = func(resp *http.Response) error {
 		// delete CORS headers sent by the sparql endpoint. we're setting these ourselves in the http handler chain.
 		// not deleting the headers will produce e.g. "CORS Multiple Origin Not Allowed" errors in the browser.
@@ -113,7 +118,7 @@ func handleGetResource(c *gin.Context) {
 	did = strings.TrimPrefix(did, "/")
 	// if "includeLinked" request parameter is set, then pull in linked resources
 	includeLinked := c.Request.URL.Query().Has("includeLinked")
-	resource, metadata, err := rdf.GetResource(did, includeLinked)
+	resource, metadata, err := rdf.GetResource(c.Request.Context(), did, includeLinked)
 	if err != nil {
 		slog.Error("failed loading resource", "id", did, "error", err)
 		if errors.Is(err, rdf.ErrNotFound) {
@@ -144,7 +149,7 @@ func handleAddResource(c *gin.Context) {
 		return
 	}
 
-	resource, metadata, err := rdf.CreateResource(data, user)
+	resource, metadata, err := rdf.CreateResource(c.Request.Context(), data, user)
 	if err != nil {
 		slog.Error("failed creating resource", "error", err)
 		if errors.Is(err, rdf.ErrNotFound) {
@@ -154,7 +159,8 @@ func handleAddResource(c *gin.Context) {
 		}
 		return
 	}
-	if err = search.IndexResource(resource, metadata); err != nil {
+	// the resource is stored, so keep the index consistent even if the client disconnects
+	if err = search.IndexResource(context.WithoutCancel(c.Request.Context()), resource, metadata); err != nil {
 		slog.Error("failed indexing resource", "id", metadata.Id.RawValue(), "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -186,7 +192,7 @@ func handleUpdateResource(c *gin.Context) {
 		return
 	}
 
-	resource, metadata, err := rdf.UpdateResource(did, data, user)
+	resource, metadata, err := rdf.UpdateResource(c.Request.Context(), did, data, user)
 	if err != nil {
 		slog.Error("failed updating resource", "id", did, "error", err)
 		if errors.Is(err, rdf.ErrNotFound) {
@@ -196,7 +202,7 @@ func handleUpdateResource(c *gin.Context) {
 		}
 		return
 	}
-	if err = search.IndexResource(resource, metadata); err != nil {
+	if err = search.IndexResource(context.WithoutCancel(c.Request.Context()), resource, metadata); err != nil {
 		slog.Error("failed indexing resource", "id", did, "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -219,7 +225,7 @@ func handleDeleteResource(c *gin.Context) {
 		return
 	}
 	did = strings.TrimPrefix(did, "/")
-	if err := rdf.DeleteResource(did, user); err != nil {
+	if err := rdf.DeleteResource(c.Request.Context(), did, user); err != nil {
 		slog.Error("failed deleting resource", "id", did, "error", err)
 		if errors.Is(err, rdf.ErrResourceLinked) {
 			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
@@ -228,7 +234,7 @@ func handleDeleteResource(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	if err = search.DeindexResource(did); err != nil {
+	if err = search.DeindexResource(context.WithoutCancel(c.Request.Context()), did); err != nil {
 		slog.Error("failed deindexing resource", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -246,7 +252,7 @@ func handleGetProfile(c *gin.Context) {
 		return
 	}
 	did = strings.TrimPrefix(did, "/")
-	graph, err := rdf.GetProfile(did)
+	graph, err := rdf.GetProfile(c.Request.Context(), did)
 	if err != nil {
 		slog.Error("failed loading profile", "id", did, "error", err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -261,7 +267,7 @@ func handleGetClassInstances(c *gin.Context) {
 	var instances []byte
 	if len(classes) > 0 {
 		var err error
-		instances, err = rdf.GetClassInstances(classes)
+		instances, err = rdf.GetClassInstances(c.Request.Context(), classes)
 		if err != nil {
 			slog.Error("failed retrieving class instances", "classes", classes, "error", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -280,7 +286,7 @@ func handleListConformingResources(c *gin.Context) {
 		return
 
 	}
-	resourceIds, err := rdf.ListConformingResources(shape)
+	resourceIds, err := rdf.ListConformingResources(c.Request.Context(), shape)
 	if err != nil {
 		slog.Error("failed listing conforming resources", "shape", shape, "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
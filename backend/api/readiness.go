@@ -0,0 +1,116 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"rdf-store-backend/base"
+	"rdf-store-backend/rdf"
+	"rdf-store-backend/search"
+	"rdf-store-backend/shacl"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	StatusUp      = "up"
+	StatusDown    = "down"
+	StatusPending = "pending"
+	StatusFailed  = "failed"
+)
+
+type ComponentStatus struct {
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration,omitempty"`
+}
+
+type ReadinessResponse struct {
+	Ready      bool                       `json:"ready"`
+	Components map[string]ComponentStatus `json:"components"`
+}
+
+var readinessEndpoint = "/readyz"
+var readinessTimeout = time.Duration(base.EnvVarAsInt("READINESS_TIMEOUT", 2)) * time.Second
+
+// readinessProbes checks the external services the API depends on.
+var readinessProbes = map[string]func(ctx context.Context) error{
+	"fuseki":    rdf.Ping,
+	"solr":      search.Ping,
+	"validator": shacl.Ping,
+}
+
+// startup tracks the background initialization started by main.
+var startup struct {
+	sync.RWMutex
+	finished bool
+	err      error
+}
+
+// init registers the readiness endpoint on the router.
+func init() {
+	Router.GET(BasePath+readinessEndpoint, handleReadyz)
+}
+
+// StartupFinished records the outcome of the background initialization.
+// A nil error marks the service as initialized.
+func StartupFinished(err error) {
+	startup.Lock()
+	defer startup.Unlock()
+	startup.finished = true
+	startup.err = err
+}
+
+// startupStatus reports the state of the background initialization.
+func startupStatus() ComponentStatus {
+	startup.RLock()
+	defer startup.RUnlock()
+	if !startup.finished {
+		return ComponentStatus{Status: StatusPending}
+	}
+	if startup.err != nil {
+		return ComponentStatus{Status: StatusFailed, Error: startup.err.Error()}
+	}
+	return ComponentStatus{Status: StatusUp}
+}
+
+// handleReadyz probes all dependencies concurrently and reports their status.
+// It responds with 503 when any dependency is down or initialization has not succeeded.
+func handleReadyz(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), readinessTimeout)
+	defer cancel()
+
+	response := ReadinessResponse{Ready: true, Components: make(map[string]ComponentStatus)}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, probe := range readinessProbes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			err := probe(ctx)
+			status := ComponentStatus{Status: StatusUp, Duration: time.Since(start).String()}
+			if err != nil {
+				status.Status = StatusDown
+				status.Error = err.Error()
+			}
+			mu.Lock()
+			response.Components[name] = status
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	response.Components["startup"] = startupStatus()
+
+	for _, component := range response.Components {
+		if component.Status != StatusUp {
+			response.Ready = false
+		}
+	}
+	if response.Ready {
+		c.JSON(http.StatusOK, response)
+	} else {
+		c.JSON(http.StatusServiceUnavailable, response)
+	}
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func serveReadyz(t *testing.T, probes map[string]func(ctx context.Context) error) (int, ReadinessResponse) {
+	t.Helper()
+	previous := readinessProbes
+	readinessProbes = probes
+	t.Cleanup(func() { readinessProbes = previous })
+
+	response := httptest.NewRecorder()
+	Router.ServeHTTP(response, httptest.NewRequest(http.MethodGet, BasePath+readinessEndpoint, nil))
+	var body ReadinessResponse
+	if err := json.Unmarshal(response.Body.Bytes(), &body); err != nil {
+		t.Fatalf("invalid readiness response %s: %v", response.Body, err)
+	}
+	return response.Code, body
+}
+
+func TestReadyzReportsComponentStatus(t *testing.T) {
+	up := func(ctx context.Context) error { return nil }
+	down := func(ctx context.Context) error { return errors.New("connection refused") }
+
+	StartupFinished(nil)
+	code, body := serveReadyz(t, map[string]func(ctx context.Context) error{"fuseki": up, "solr": up, "validator": up})
+	if code != http.StatusOK || !body.Ready {
+		t.Fatalf("expected ready, got %d: %+v", code, body)
+	}
+
+	code, body = serveReadyz(t, map[string]func(ctx context.Context) error{"fuseki": up, "solr": down, "validator": up})
+	if code != http.StatusServiceUnavailable || body.Ready {
+		t.Fatalf("expected unavailable, got %d: %+v", code, body)
+	}
+	if solr := body.Components["solr"]; solr.Status != StatusDown || solr.Error != "connection refused" {
+		t.Fatalf("unexpected solr status: %+v", solr)
+	}
+	if fuseki := body.Components["fuseki"]; fuseki.Status != StatusUp {
+		t.Fatalf("unexpected fuseki status: %+v", fuseki)
+	}
+}
+
+func TestReadyzReportsFailedStartup(t *testing.T) {
+	t.Cleanup(func() { StartupFinished(nil) })
+	StartupFinished(errors.New("solr not ready after 30 attempts"))
+	code, body := serveReadyz(t, map[string]func(ctx context.Context) error{})
+	if code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d", code)
+	}
+	if startup := body.Components["startup"]; startup.Status != StatusFailed || startup.Error == "" {
+		t.Fatalf("unexpected startup status: %+v", startup)
+	}
+}
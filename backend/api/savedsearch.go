@@ -0,0 +1,268 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"rdf-store-backend/alerts"
+	"rdf-store-backend/base"
+	"rdf-store-backend/rdf"
+	"rdf-store-backend/search"
+	"strings"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+)
+
+const maxSavedSearchNameLength = 200
+
+// init registers the saved search endpoints.
+func init() {
+	Router.GET(BasePath+"/saved-searches", handleListSavedSearches)
+	Router.POST(BasePath+"/saved-searches", handleCreateSavedSearch)
+	Router.DELETE(BasePath+"/saved-searches/:id", handleDeleteSavedSearch)
+	Router.PUT(BasePath+"/saved-searches/:id/subscription", handleSubscribeSavedSearch)
+	Router.DELETE(BasePath+"/saved-searches/:id/subscription", handleUnsubscribeSavedSearch)
+}
+
+// savedSearchRequest is the body of POST /saved-searches. Exactly one of
+// Query and SolrQuery must be set.
+type savedSearchRequest struct {
+	Name      string          `json:"name"`
+	Query     json.RawMessage `json:"query,omitempty"`
+	SolrQuery json.RawMessage `json:"solrQuery,omitempty"`
+	Subscribe bool            `json:"subscribe"`
+	Email     string          `json:"email,omitempty"`
+}
+
+// subscriptionRequest is the optional body of PUT /saved-searches/:id/subscription.
+type subscriptionRequest struct {
+	Email string `json:"email,omitempty"`
+}
+
+// savedSearchResponse returns stored queries as JSON objects instead of strings.
+type savedSearchResponse struct {
+	*rdf.SavedSearch
+	Query     json.RawMessage `json:"query,omitempty"`
+	SolrQuery json.RawMessage `json:"solrQuery,omitempty"`
+}
+
+func newSavedSearchResponse(savedSearch *rdf.SavedSearch) savedSearchResponse {
+	response := savedSearchResponse{SavedSearch: savedSearch}
+	if savedSearch.Query != "" {
+		response.Query = json.RawMessage(savedSearch.Query)
+	}
+	if savedSearch.SolrQuery != "" {
+		response.SolrQuery = json.RawMessage(savedSearch.SolrQuery)
+	}
+	return response
+}
+
+// savedSearchUser resolves the owner of saved searches. Saved searches need
+// authentication, as alerts may be sent to any address of their owner's
+// choosing.
+// It returns false when authentication is disabled or no user is logged in.
+func savedSearchUser(h http.Header) (user string, ok bool) {
+	if !base.Configuration.AuthEnabled {
+		return "", false
+	}
+	user = h.Get(base.AuthUserHeader)
+	return user, len(user) > 0
+}
+
+// handleListSavedSearches returns the saved searches of the requesting user.
+func handleListSavedSearches(c *gin.Context) {
+	user, ok := savedSearchUser(c.Request.Header)
+	if !ok {
+		c.JSON(http.StatusForbidden, gin.H{"error": "not allowed"})
+		return
+	}
+	searches, err := rdf.ListSavedSearches(c.Request.Context(), user)
+	if err != nil {
+		slog.Error("failed listing saved searches", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	response := make([]savedSearchResponse, 0, len(searches))
+	for _, savedSearch := range searches {
+		response = append(response, newSavedSearchResponse(savedSearch))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// handleCreateSavedSearch validates and stores a saved search of the requesting user.
+func handleCreateSavedSearch(c *gin.Context) {
+	user, ok := savedSearchUser(c.Request.Header)
+	if !ok {
+		c.JSON(http.StatusForbidden, gin.H{"error": "not allowed"})
+		return
+	}
+	var request savedSearchRequest
+	if err := json.NewDecoder(c.Request.Body).Decode(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	savedSearch, err := newSavedSearch(request, user, c.Request.Header.Get(base.AuthEmailHeader))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := rdf.CreateSavedSearch(c.Request.Context(), savedSearch); err != nil {
+		slog.Error("failed creating saved search", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("Location", savedSearch.Id)
+	c.JSON(http.StatusCreated, newSavedSearchResponse(savedSearch))
+}
+
+// newSavedSearch validates a saved search request and normalizes its query.
+// It returns an error describing the first invalid member.
+func newSavedSearch(request savedSearchRequest, user string, userEmail string) (*rdf.SavedSearch, error) {
+	name := strings.TrimSpace(request.Name)
+	if name == "" || len(name) > maxSavedSearchNameLength || strings.IndexFunc(name, unicode.IsControl) >= 0 {
+		return nil, errors.New("name must be a single line of 1 to 200 characters")
+	}
+	savedSearch := &rdf.SavedSearch{Name: name, Creator: user, Subscribed: request.Subscribe}
+	switch {
+	case len(request.Query) > 0 && len(request.SolrQuery) == 0:
+		var query search.Query
+		if err := json.Unmarshal(request.Query, &query); err != nil {
+			return nil, err
+		}
+		if _, err := query.SolrQuery(); err != nil {
+			return nil, err
+		}
+		data, err := json.Marshal(query)
+		if err != nil {
+			return nil, err
+		}
+		savedSearch.Query = string(data)
+	case len(request.SolrQuery) > 0 && len(request.Query) == 0:
+		// apply the restrictions of the Solr proxy to the stored request
+		data, err := filterSolrJSON(request.SolrQuery)
+		if err != nil {
+			return nil, err
+		}
+		savedSearch.SolrQuery = string(data)
+	default:
+		return nil, errors.New("exactly one of query and solrQuery is required")
+	}
+	email, err := alertEmail(request.Email, userEmail, request.Subscribe)
+	if err != nil {
+		return nil, err
+	}
+	savedSearch.Email = email
+	return savedSearch, nil
+}
+
+// alertEmail selects the alert recipient, preferring an explicit address over
+// the one of the logged in user.
+// It returns an error for invalid addresses or when a subscription to a mail
+// sink has no recipient.
+func alertEmail(requested string, userEmail string, subscribe bool) (string, error) {
+	email := requested
+	if email == "" {
+		email = userEmail
+	}
+	if email != "" {
+		address, err := mail.ParseAddress(email)
+		if err != nil {
+			return "", errors.New("invalid email address")
+		}
+		email = address.Address
+	}
+	if subscribe && email == "" && alerts.DeliversMail() {
+		return "", errors.New("an email address is required for alerts")
+	}
+	return email, nil
+}
+
+// handleDeleteSavedSearch deletes a saved search of the requesting user.
+func handleDeleteSavedSearch(c *gin.Context) {
+	user, ok := savedSearchUser(c.Request.Header)
+	if !ok {
+		c.JSON(http.StatusForbidden, gin.H{"error": "not allowed"})
+		return
+	}
+	id, err := url.QueryUnescape(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := rdf.DeleteSavedSearch(c.Request.Context(), id, user); err != nil {
+		respondSavedSearchError(c, id, err)
+		return
+	}
+	c.String(http.StatusNoContent, "")
+}
+
+// handleSubscribeSavedSearch enables alerts for a saved search of the requesting user.
+func handleSubscribeSavedSearch(c *gin.Context) {
+	user, ok := savedSearchUser(c.Request.Header)
+	if !ok {
+		c.JSON(http.StatusForbidden, gin.H{"error": "not allowed"})
+		return
+	}
+	var request subscriptionRequest
+	if c.Request.ContentLength != 0 {
+		if err := json.NewDecoder(c.Request.Body).Decode(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	setSavedSearchSubscription(c, user, true, request.Email)
+}
+
+// handleUnsubscribeSavedSearch disables alerts for a saved search of the requesting user.
+func handleUnsubscribeSavedSearch(c *gin.Context) {
+	user, ok := savedSearchUser(c.Request.Header)
+	if !ok {
+		c.JSON(http.StatusForbidden, gin.H{"error": "not allowed"})
+		return
+	}
+	setSavedSearchSubscription(c, user, false, "")
+}
+
+func setSavedSearchSubscription(c *gin.Context, user string, subscribed bool, requestedEmail string) {
+	id, err := url.QueryUnescape(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	email := ""
+	if subscribed {
+		savedSearch, err := rdf.GetSavedSearch(c.Request.Context(), id, user)
+		if err != nil {
+			respondSavedSearchError(c, id, err)
+			return
+		}
+		userEmail := c.Request.Header.Get(base.AuthEmailHeader)
+		if savedSearch.Email != "" && requestedEmail == "" {
+			userEmail = savedSearch.Email
+		}
+		if email, err = alertEmail(requestedEmail, userEmail, true); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	savedSearch, err := rdf.SubscribeSavedSearch(c.Request.Context(), id, user, subscribed, email)
+	if err != nil {
+		respondSavedSearchError(c, id, err)
+		return
+	}
+	c.JSON(http.StatusOK, newSavedSearchResponse(savedSearch))
+}
+
+// respondSavedSearchError maps saved search lookup errors to status codes.
+func respondSavedSearchError(c *gin.Context, id string, err error) {
+	if errors.Is(err, rdf.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	slog.Error("failed updating saved search", "id", id, "error", err)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
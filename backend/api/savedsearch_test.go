@@ -0,0 +1,90 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"rdf-store-backend/alerts"
+	"rdf-store-backend/base"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestNewSavedSearchNormalizesQueries(t *testing.T) {
+	savedSearch, err := newSavedSearch(savedSearchRequest{
+		Name:      " Soil samples ",
+		SolrQuery: json.RawMessage(`{"query":"*","filter":"shape:\"x\"","limit":5000}`),
+	}, "alice", "alice@example.org")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stored struct {
+		Filter []string `json:"filter"`
+		Limit  int      `json:"limit"`
+	}
+	if err := json.Unmarshal([]byte(savedSearch.SolrQuery), &stored); err != nil {
+		t.Fatal(err)
+	}
+	if savedSearch.Name != "Soil samples" || savedSearch.Creator != "alice" || savedSearch.Email != "alice@example.org" {
+		t.Fatalf("unexpected saved search %+v", savedSearch)
+	}
+	if !slices.Contains(stored.Filter, entityFilter) || stored.Limit != solrMaxRows {
+		t.Fatalf("raw query was not restricted: %s", savedSearch.SolrQuery)
+	}
+
+	savedSearch, err = newSavedSearch(savedSearchRequest{
+		Name:  "Structured",
+		Query: json.RawMessage(`{"profile":"http://example.org/P","criteria":[{"path":["http://example.org/p"],"operator":"contains","value":"x"}]}`),
+	}, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if savedSearch.Query == "" || savedSearch.SolrQuery != "" {
+		t.Fatalf("unexpected saved search %+v", savedSearch)
+	}
+}
+
+func TestNewSavedSearchRejectsInvalidRequests(t *testing.T) {
+	previous := alerts.SinkType
+	alerts.SinkType = alerts.SinkMailDrop
+	defer func() { alerts.SinkType = previous }()
+
+	for name, request := range map[string]savedSearchRequest{
+		"name":       {Name: "a\nb", Query: json.RawMessage(`{}`)},
+		"no query":   {Name: "a"},
+		"two":        {Name: "a", Query: json.RawMessage(`{}`), SolrQuery: json.RawMessage(`{}`)},
+		"structured": {Name: "a", Query: json.RawMessage(`{"sort":"label"}`)},
+		"raw":        {Name: "a", SolrQuery: json.RawMessage(`{"params":{}}`)},
+		"email":      {Name: "a", Query: json.RawMessage(`{}`), Email: "not an address"},
+		"recipient":  {Name: "a", Query: json.RawMessage(`{}`), Subscribe: true},
+	} {
+		if _, err := newSavedSearch(request, "alice", ""); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestSavedSearchesRequireAuthentication(t *testing.T) {
+	previous := base.Configuration.AuthEnabled
+	t.Cleanup(func() { base.Configuration.AuthEnabled = previous })
+	requests := []struct{ method, path, body string }{
+		{http.MethodGet, "/saved-searches", ""},
+		{http.MethodPost, "/saved-searches", `{"name":"a","query":{},"subscribe":true,"email":"someone@example.org"}`},
+		{http.MethodDelete, "/saved-searches/x", ""},
+		{http.MethodPut, "/saved-searches/x/subscription", `{"email":"someone@example.org"}`},
+		{http.MethodDelete, "/saved-searches/x/subscription", ""},
+	}
+	// without authentication, and with authentication but no logged in user
+	for _, authEnabled := range []bool{false, true} {
+		base.Configuration.AuthEnabled = authEnabled
+		for _, test := range requests {
+			request := httptest.NewRequest(test.method, BasePath+test.path, strings.NewReader(test.body))
+			response := httptest.NewRecorder()
+			Router.ServeHTTP(response, request)
+			if response.Code != http.StatusForbidden {
+				t.Errorf("auth enabled %v: expected 403 for %s %s, got %d", authEnabled, request.Method, request.URL, response.Code)
+			}
+		}
+	}
+}
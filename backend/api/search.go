@@ -1,9 +1,16 @@
 package api
 
 import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
 	"net/http/httputil"
 	"net/url"
+	"rdf-store-backend/httpclient"
+	"rdf-store-backend/metrics"
 	"rdf-store-backend/search"
+	"rdf-store-backend/tracing"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -21,15 +28,49 @@ func init() {
 		panic(err)
 	}
 	solrProxy = httputil.NewSingleHostReverseProxy(solrProxyTarget)
+	solrProxy.Transport = tracing.Transport(httpclient.NewTransport(), metrics.ServiceSolr)
 
 	Router.GET(BasePath+"/solr/:collection/schema", handleSolr)
 	Router.GET(BasePath+"/solr/:collection/select", handleSolr)
 	Router.GET(BasePath+"/solr/:collection/query", handleSolr)
 	Router.POST(BasePath+"/solr/:collection/query", handleSolr)
+	Router.POST(BasePath+"/search", handleSearch)
+}
+
+// handleSearch runs a structured query and returns a page of entity hits.
+func handleSearch(c *gin.Context) {
+	var query search.Query
+	if err := json.NewDecoder(c.Request.Body).Decode(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	result, err := search.Search(c.Request.Context(), query)
+	if err != nil {
+		if errors.Is(err, search.ErrInvalidQuery) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		slog.Error("failed searching", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
 }
 
 // handleSolr proxies Solr query and schema requests to the Solr backend.
+// Requests are restricted by filterSolrRequest first.
 func handleSolr(c *gin.Context) {
+	handler := c.Request.URL.Path[strings.LastIndex(c.Request.URL.Path, "/")+1:]
+	if err := filterSolrRequest(c.Request, c.Param("collection"), handler); err != nil {
+		if errors.Is(err, errInvalidSolrRequest) {
+			slog.Warn("rejected solr request", "error", err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			slog.Error("failed filtering solr request", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
 	c.Request.URL.Path = strings.TrimPrefix(c.Request.URL.Path, BasePath)
 	c.Request.URL.Scheme = solrProxyTarget.Scheme
 	c.Request.URL.Host = solrProxyTarget.Host
@@ -0,0 +1,343 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"rdf-store-backend/base"
+	"strconv"
+	"strings"
+)
+
+// entityFilter is injected into every proxied query so value documents never
+// appear as hits.
+const entityFilter = "docType:entity"
+
+// blockJoinLocalParams is the only local parameter prefix accepted in proxied
+// queries. Other query parsers, e.g. cross-collection joins, are rejected.
+const blockJoinLocalParams = "{!parent which=docType:entity}"
+
+var solrMaxRows = base.EnvVarAsInt("SOLR_MAX_ROWS", 100)
+
+// solrHandlerParams lists the URL parameters accepted per proxied Solr handler.
+var solrHandlerParams = map[string]map[string]bool{
+	"schema": {"wt": true},
+	"select": solrQueryParams,
+	"query":  solrQueryParams,
+}
+
+var solrQueryParams = map[string]bool{
+	"q": true, "fq": true, "fl": true, "sort": true, "start": true, "rows": true, "wt": true, "q.op": true, "df": true,
+	"facet": true, "facet.field": true, "facet.limit": true, "facet.mincount": true, "facet.sort": true, "facet.offset": true,
+	"json.facet": true,
+}
+
+// solrBodyKeys lists the members accepted in JSON request bodies.
+var solrBodyKeys = map[string]bool{"query": true, "filter": true, "sort": true, "offset": true, "limit": true, "fields": true, "facet": true}
+
+var solrFacetKeys = map[string]bool{
+	"type": true, "field": true, "q": true, "query": true, "limit": true, "offset": true, "mincount": true, "sort": true,
+	"missing": true, "numBuckets": true, "allBuckets": true, "prefix": true, "domain": true, "facet": true,
+	"start": true, "end": true, "gap": true, "geom": true, "gridLevel": true, "distErrPct": true, "format": true,
+}
+var solrFacetTypes = map[string]bool{"terms": true, "query": true, "range": true, "heatmap": true}
+var solrDomainKeys = map[string]bool{"blockChildren": true, "blockParent": true, "filter": true, "query": true, "excludeTags": true}
+
+var errInvalidSolrRequest = errors.New("invalid solr request")
+
+// filterSolrRequest restricts a proxied request to read-only queries on the
+// configured collection. It rejects unknown handlers, parameters and query
+// parsers, caps rows and facet limits and injects the entity filter into
+// query requests. The request is rewritten in place.
+// It returns an error wrapping errInvalidSolrRequest when the request is rejected.
+func filterSolrRequest(req *http.Request, collection string, handler string) error {
+	if collection != base.SolrIndex {
+		return fmt.Errorf("%w: unknown collection %q", errInvalidSolrRequest, collection)
+	}
+	allowed, ok := solrHandlerParams[handler]
+	if !ok {
+		return fmt.Errorf("%w: unsupported handler %q", errInvalidSolrRequest, handler)
+	}
+	params := req.URL.Query()
+	for name := range params {
+		if !allowed[name] {
+			return fmt.Errorf("%w: unsupported parameter %q", errInvalidSolrRequest, name)
+		}
+	}
+	if handler == "schema" {
+		return nil
+	}
+	if err := filterSolrParams(params); err != nil {
+		return err
+	}
+	req.URL.RawQuery = params.Encode()
+	if req.Method != http.MethodPost {
+		return nil
+	}
+	return filterSolrBody(req)
+}
+
+// filterSolrParams validates and caps query parameters and adds the entity filter.
+// Every parameter is checked for local parameters, as function queries in
+// sort, fl or facet.field may embed queries as well.
+func filterSolrParams(params url.Values) error {
+	for _, values := range params {
+		for _, value := range values {
+			if err := checkLocalParams(value); err != nil {
+				return err
+			}
+		}
+	}
+	if err := capIntParam(params, "rows", solrMaxRows); err != nil {
+		return err
+	}
+	if err := capIntParam(params, "facet.limit", base.Configuration.SolrMaxAggregations); err != nil {
+		return err
+	}
+	if raw := params.Get("json.facet"); raw != "" {
+		var facets map[string]any
+		if err := json.Unmarshal([]byte(raw), &facets); err != nil {
+			return fmt.Errorf("%w: json.facet: %w", errInvalidSolrRequest, err)
+		}
+		if err := filterFacets(facets); err != nil {
+			return err
+		}
+		data, err := json.Marshal(facets)
+		if err != nil {
+			return err
+		}
+		params.Set("json.facet", string(data))
+	}
+	params.Add("fq", entityFilter)
+	return nil
+}
+
+// filterSolrBody validates a JSON request body, caps its limits and adds the
+// entity filter.
+func filterSolrBody(req *http.Request) error {
+	if mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type")); mediaType != "application/json" {
+		return fmt.Errorf("%w: request body must be application/json", errInvalidSolrRequest)
+	}
+	data, err := io.ReadAll(req.Body)
+	if err != nil {
+		return err
+	}
+	if data, err = filterSolrJSON(data); err != nil {
+		return err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(data))
+	req.ContentLength = int64(len(data))
+	req.Header.Set("Content-Length", strconv.Itoa(len(data)))
+	return nil
+}
+
+// filterSolrJSON applies the restrictions of filterSolrBody to a Solr JSON request.
+// It returns the rewritten request.
+func filterSolrJSON(data []byte) ([]byte, error) {
+	body := make(map[string]any)
+	if len(bytes.TrimSpace(data)) > 0 {
+		if err := json.Unmarshal(data, &body); err != nil {
+			return nil, fmt.Errorf("%w: %w", errInvalidSolrRequest, err)
+		}
+	}
+	for key := range body {
+		if !solrBodyKeys[key] {
+			return nil, fmt.Errorf("%w: unsupported member %q", errInvalidSolrRequest, key)
+		}
+	}
+	if query, ok := body["query"]; ok {
+		value, ok := query.(string)
+		if !ok {
+			return nil, fmt.Errorf("%w: query must be a string", errInvalidSolrRequest)
+		}
+		if err := checkLocalParams(value); err != nil {
+			return nil, err
+		}
+	}
+	filters, err := stringList(body["filter"], "filter")
+	if err != nil {
+		return nil, err
+	}
+	for _, filter := range filters {
+		if err := checkLocalParams(filter); err != nil {
+			return nil, err
+		}
+	}
+	body["filter"] = append(filters, entityFilter)
+	// sort and fields may hold function queries embedding queries
+	for _, key := range []string{"sort", "fields"} {
+		values, err := stringList(body[key], key)
+		if err != nil {
+			return nil, err
+		}
+		for _, value := range values {
+			if err := checkLocalParams(value); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := capIntMember(body, "limit", solrMaxRows); err != nil {
+		return nil, err
+	}
+	if facet, ok := body["facet"]; ok {
+		facets, ok := facet.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%w: facet must be an object", errInvalidSolrRequest)
+		}
+		if err := filterFacets(facets); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(body)
+}
+
+// filterFacets validates JSON facet definitions recursively and caps bucket limits.
+func filterFacets(facets map[string]any) error {
+	for name, raw := range facets {
+		switch facet := raw.(type) {
+		case string:
+			// aggregation functions such as "uniqueBlock(_root_)"
+			if err := checkLocalParams(facet); err != nil {
+				return err
+			}
+		case map[string]any:
+			if err := filterFacet(name, facet); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("%w: invalid facet %q", errInvalidSolrRequest, name)
+		}
+	}
+	return nil
+}
+
+func filterFacet(name string, facet map[string]any) error {
+	for key := range facet {
+		if !solrFacetKeys[key] {
+			return fmt.Errorf("%w: unsupported member %q in facet %q", errInvalidSolrRequest, key, name)
+		}
+	}
+	facetType, _ := facet["type"].(string)
+	if facetType != "" && !solrFacetTypes[facetType] {
+		return fmt.Errorf("%w: unsupported type %q of facet %q", errInvalidSolrRequest, facetType, name)
+	}
+	for _, key := range []string{"q", "query"} {
+		queries, err := stringList(facet[key], key)
+		if err != nil {
+			return err
+		}
+		for _, query := range queries {
+			if err := checkLocalParams(query); err != nil {
+				return err
+			}
+		}
+	}
+	if facetType == "" || facetType == "terms" {
+		if err := capIntMember(facet, "limit", base.Configuration.SolrMaxAggregations); err != nil {
+			return err
+		}
+	}
+	if raw, ok := facet["domain"]; ok {
+		domain, ok := raw.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%w: invalid domain of facet %q", errInvalidSolrRequest, name)
+		}
+		for key, value := range domain {
+			if !solrDomainKeys[key] {
+				return fmt.Errorf("%w: unsupported domain %q of facet %q", errInvalidSolrRequest, key, name)
+			}
+			queries, err := stringList(value, key)
+			if err != nil {
+				return err
+			}
+			for _, query := range queries {
+				if err := checkLocalParams(query); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if raw, ok := facet["facet"]; ok {
+		nested, ok := raw.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%w: invalid sub-facets of facet %q", errInvalidSolrRequest, name)
+		}
+		return filterFacets(nested)
+	}
+	return nil
+}
+
+// checkLocalParams rejects queries that switch to a query parser other than
+// the entity block join.
+func checkLocalParams(query string) error {
+	for rest := query; ; {
+		index := strings.Index(rest, "{!")
+		if index < 0 {
+			return nil
+		}
+		rest = rest[index:]
+		if !strings.HasPrefix(rest, blockJoinLocalParams) {
+			return fmt.Errorf("%w: unsupported local parameters in %q", errInvalidSolrRequest, query)
+		}
+		rest = rest[len(blockJoinLocalParams):]
+	}
+}
+
+// stringList converts a JSON string or string array member to a slice.
+func stringList(value any, name string) ([]string, error) {
+	switch v := value.(type) {
+	case nil:
+		return []string{}, nil
+	case string:
+		return []string{v}, nil
+	case []any:
+		result := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("%w: %s must contain strings", errInvalidSolrRequest, name)
+			}
+			result = append(result, s)
+		}
+		return result, nil
+	}
+	return nil, fmt.Errorf("%w: %s must be a string or a list of strings", errInvalidSolrRequest, name)
+}
+
+// capIntParam limits a numeric URL parameter to max. Negative values, which
+// Solr treats as unlimited, are capped as well.
+func capIntParam(params url.Values, name string, max int) error {
+	raw := params.Get(name)
+	if raw == "" {
+		return nil
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		return fmt.Errorf("%w: %s must be an integer", errInvalidSolrRequest, name)
+	}
+	if value < 0 || value > max {
+		params.Set(name, strconv.Itoa(max))
+	}
+	return nil
+}
+
+// capIntMember limits a numeric JSON member to max, like capIntParam.
+func capIntMember(object map[string]any, name string, max int) error {
+	raw, ok := object[name]
+	if !ok {
+		return nil
+	}
+	value, ok := raw.(float64)
+	if !ok || value != float64(int(value)) {
+		return fmt.Errorf("%w: %s must be an integer", errInvalidSolrRequest, name)
+	}
+	if value < 0 || value > float64(max) {
+		object[name] = max
+	}
+	return nil
+}
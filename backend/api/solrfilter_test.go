@@ -0,0 +1,98 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"rdf-store-backend/base"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestFilterSolrRequestCapsAndInjectsEntityFilter(t *testing.T) {
+	body := `{
+		"query": "*",
+		"filter": ["shape:\"x\"", "{!parent which=docType:entity}(docType:value AND path:\"p\")"],
+		"limit": 100000,
+		"facet": {
+			"profiles": {"type": "terms", "field": "shape", "limit": -1},
+			"f0": {"type": "query", "q": "valueNumber:[* TO *]", "domain": {"blockChildren": "docType:entity", "filter": ["docType:value"]},
+				"facet": {"entities": "uniqueBlock(_root_)"}}
+		}
+	}`
+	req := httptest.NewRequest(http.MethodPost, "/solr/"+base.SolrIndex+"/query?rows=5000", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if err := filterSolrRequest(req, base.SolrIndex, "query"); err != nil {
+		t.Fatal(err)
+	}
+	if rows := req.URL.Query().Get("rows"); rows != "100" {
+		t.Fatalf("expected capped rows, got %q", rows)
+	}
+	if !slices.Contains(req.URL.Query()["fq"], entityFilter) {
+		t.Fatalf("expected entity filter parameter, got %q", req.URL.RawQuery)
+	}
+	data, err := io.ReadAll(req.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.ContentLength != int64(len(data)) {
+		t.Fatalf("content length %d does not match body length %d", req.ContentLength, len(data))
+	}
+	var filtered struct {
+		Filter []string `json:"filter"`
+		Limit  int      `json:"limit"`
+		Facet  map[string]struct {
+			Limit int `json:"limit"`
+		} `json:"facet"`
+	}
+	if err := json.Unmarshal(data, &filtered); err != nil {
+		t.Fatal(err)
+	}
+	if filtered.Limit != solrMaxRows || filtered.Facet["profiles"].Limit != base.Configuration.SolrMaxAggregations {
+		t.Fatalf("expected capped limits, got %+v", filtered)
+	}
+	if len(filtered.Filter) != 3 || filtered.Filter[2] != entityFilter {
+		t.Fatalf("expected injected entity filter, got %q", filtered.Filter)
+	}
+}
+
+func TestFilterSolrRequestRejectsUnsafeRequests(t *testing.T) {
+	for name, test := range map[string]struct {
+		collection, handler, query, body string
+	}{
+		"collection":   {collection: "other", handler: "query"},
+		"handler":      {handler: "update"},
+		"parameter":    {handler: "select", query: "qt=/update"},
+		"schema":       {handler: "schema", query: "q=*"},
+		"local params": {handler: "select", query: "q=" + strings.ReplaceAll("{!join fromIndex=other from=id to=id}*:*", " ", "+")},
+		"sort param":   {handler: "select", query: "sort=" + url.QueryEscape("query({!join fromIndex=other from=id to=id v='*:*'}) desc")},
+		"fl param":     {handler: "select", query: "fl=" + url.QueryEscape("id,x:query({!join fromIndex=other from=id to=id v='*:*'})")},
+		"facet field":  {handler: "select", query: "facet=true&facet.field=" + url.QueryEscape("{!join fromIndex=other from=id to=id}shape")},
+		"body sort":    {handler: "query", body: `{"sort": "query({!join fromIndex=other from=id to=id v='*:*'}) desc"}`},
+		"body fields":  {handler: "query", body: `{"fields": ["id", "x:query({!join fromIndex=other from=id to=id v='*:*'})"]}`},
+		"body member":  {handler: "query", body: `{"params": {"qt": "/update"}}`},
+		"query object": {handler: "query", body: `{"query": {"lucene": {"query": "*"}}}`},
+		"facet type":   {handler: "query", body: `{"facet": {"x": {"type": "func"}}}`},
+		"facet domain": {handler: "query", body: `{"facet": {"x": {"type": "terms", "field": "shape", "domain": {"join": {"from": "id", "to": "id"}}}}}`},
+		"facet query":  {handler: "query", body: `{"facet": {"x": {"type": "query", "q": "{!func}id"}}}`},
+		"limit":        {handler: "query", body: `{"limit": "all"}`},
+	} {
+		collection := test.collection
+		if collection == "" {
+			collection = base.SolrIndex
+		}
+		method := http.MethodGet
+		if test.body != "" {
+			method = http.MethodPost
+		}
+		req := httptest.NewRequest(method, "/solr/"+collection+"/"+test.handler+"?"+test.query, strings.NewReader(test.body))
+		req.Header.Set("Content-Type", "application/json")
+		if err := filterSolrRequest(req, collection, test.handler); !errors.Is(err, errInvalidSolrRequest) {
+			t.Errorf("%s: expected rejection, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"rdf-store-backend/search"
+
+	"github.com/gin-gonic/gin"
+)
+
+// init registers the autocomplete endpoint.
+func init() {
+	Router.GET(BasePath+"/suggest", handleGetSuggest)
+}
+
+// handleGetSuggest returns completions of a partially typed text. Repeated
+// path parameters are the segments of a single path.
+func handleGetSuggest(c *gin.Context) {
+	limit, err := queryInt(c, "limit", 0)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("limit must be between 1 and %d", search.MaxSuggestLimit)})
+		return
+	}
+	suggestions, err := search.Suggest(c.Request.Context(), search.SuggestQuery{
+		Text:    c.Query("q"),
+		Profile: c.Query("profile"),
+		Path:    c.QueryArray("path"),
+		Limit:   limit,
+	})
+	if err != nil {
+		if errors.Is(err, search.ErrInvalidQuery) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		slog.Error("failed loading suggestions", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, suggestions)
+}
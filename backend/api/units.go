@@ -0,0 +1,158 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"rdf-store-backend/base"
+	"rdf-store-backend/rdf"
+	"rdf-store-backend/search/qudt"
+
+	"github.com/gin-gonic/gin"
+)
+
+// init registers the unit catalog endpoints.
+func init() {
+	Router.GET(BasePath+"/units", handleGetUnits)
+	Router.POST(BasePath+"/units/convert", handleConvertUnit)
+	Router.GET(BasePath+"/units/catalog", handleGetUnitCatalog)
+}
+
+// UnitConversion converts a value between two units of the same dimension.
+type UnitConversion struct {
+	Value    *float64 `json:"value"`
+	FromUnit string   `json:"fromUnit"`
+	ToUnit   string   `json:"toUnit"`
+	IsDelta  bool     `json:"isDelta,omitempty"`
+	Result   float64  `json:"result"`
+}
+
+// UnitDescription describes a unit of the conversion catalog.
+type UnitDescription struct {
+	URI             string  `json:"uri"`
+	Label           string  `json:"label,omitempty"`
+	Symbol          string  `json:"symbol,omitempty"`
+	Multiplier      float64 `json:"multiplier"`
+	Offset          float64 `json:"offset"`
+	DimensionVector string  `json:"dimensionVector"`
+	// Canonical marks the canonical unit of the requested quantity kind.
+	Canonical bool `json:"canonical,omitempty"`
+}
+
+// handleConvertUnit converts a value from one unit to another. Both units
+// must be in the conversion catalog and share their dimension vector.
+func handleConvertUnit(c *gin.Context) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		slog.Error("failed reading unit conversion", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	var conversion UnitConversion
+	if err = json.Unmarshal(body, &conversion); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if conversion.Value == nil || conversion.FromUnit == "" || conversion.ToUnit == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing value, fromUnit or toUnit"})
+		return
+	}
+	result, err := convertUnit(*conversion.Value, conversion.FromUnit, conversion.ToUnit, conversion.IsDelta)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	conversion.Result = result
+	c.JSON(http.StatusOK, conversion)
+}
+
+// convertUnit converts a value with qudt.ConvertTo.
+// It returns an error if a unit is unknown, the units have different
+// dimension vectors or the conversion fails.
+func convertUnit(value float64, fromUnit, toUnit string, isDelta bool) (float64, error) {
+	from, to := qudt.Unit(fromUnit), qudt.Unit(toUnit)
+	if from == nil {
+		return 0, fmt.Errorf("unknown unit %s", fromUnit)
+	}
+	if to == nil {
+		return 0, fmt.Errorf("unknown unit %s", toUnit)
+	}
+	if from.DimensionVector != to.DimensionVector {
+		return 0, fmt.Errorf("units %s (%s) and %s (%s) have different dimension vectors", fromUnit, from.DimensionVector, toUnit, to.DimensionVector)
+	}
+	if fromUnit == toUnit {
+		return value, nil
+	}
+	result, ok := qudt.ConvertTo(value, fromUnit, toUnit, isDelta)
+	if !ok {
+		return 0, fmt.Errorf("cannot convert from %s to %s", fromUnit, toUnit)
+	}
+	return result, nil
+}
+
+// handleGetUnits lists the units convertible to the canonical unit of a
+// quantity kind, or all units without quantity kind. Labels missing from the
+// catalog are taken from the triple store.
+func handleGetUnits(c *gin.Context) {
+	quantityKind := c.Query("quantityKind")
+	uris := qudt.CompatibleUnits(quantityKind)
+	if uris == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("no canonical unit known for quantity kind %s", quantityKind)})
+		return
+	}
+	language := c.Query("language")
+	languages := base.LabelLanguages
+	if language != "" {
+		languages = append([]string{language}, languages...)
+	}
+	canonical := ""
+	if quantityKind != "" {
+		canonical = qudt.CanonicalUnitOf(quantityKind)
+	}
+	result := make([]UnitDescription, 0, len(uris))
+	unlabeled := make([]string, 0)
+	for _, uri := range uris {
+		info := qudt.Unit(uri)
+		description := UnitDescription{
+			URI:             uri,
+			Label:           info.Label(languages...),
+			Symbol:          info.Symbol,
+			Multiplier:      info.Multiplier,
+			Offset:          info.Offset,
+			DimensionVector: info.DimensionVector,
+			Canonical:       uri == canonical,
+		}
+		if description.Label == "" {
+			unlabeled = append(unlabeled, "<"+uri+">")
+		}
+		result = append(result, description)
+	}
+	if len(unlabeled) > 0 {
+		var labels map[string]string
+		var err error
+		if language == "" {
+			labels, err = rdf.GetDefaultLabels(c.Request.Context(), unlabeled)
+		} else {
+			labels, err = rdf.GetLabels(c.Request.Context(), language, unlabeled)
+		}
+		if err != nil {
+			slog.Error("failed loading unit labels", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		for i := range result {
+			if result[i].Label == "" {
+				result[i].Label = labels["<"+result[i].URI+">"]
+			}
+		}
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// handleGetUnitCatalog reports the versions, size and merge conflicts of the
+// active unit catalog.
+func handleGetUnitCatalog(c *gin.Context) {
+	c.JSON(http.StatusOK, qudt.ActiveCatalog())
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"rdf-store-backend/search/qudt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestHandleConvertUnitConvertsBetweenUnits(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	for _, test := range []struct {
+		body     string
+		code     int
+		expected float64
+	}{
+		{`{"value":212,"fromUnit":"http://qudt.org/vocab/unit/DEG_F","toUnit":"http://qudt.org/vocab/unit/DEG_C"}`, http.StatusOK, 100},
+		{`{"value":9,"fromUnit":"http://qudt.org/vocab/unit/DEG_F","toUnit":"http://qudt.org/vocab/unit/DEG_C","isDelta":true}`, http.StatusOK, 5},
+		{`{"value":2,"fromUnit":"http://qudt.org/vocab/unit/M","toUnit":"http://qudt.org/vocab/unit/M"}`, http.StatusOK, 2},
+		{`{"value":1,"fromUnit":"http://qudt.org/vocab/unit/M","toUnit":"http://qudt.org/vocab/unit/SEC"}`, http.StatusBadRequest, 0},
+		{`{"value":1,"fromUnit":"http://example.org/unknown","toUnit":"http://qudt.org/vocab/unit/M"}`, http.StatusBadRequest, 0},
+		{`{"fromUnit":"http://qudt.org/vocab/unit/M","toUnit":"http://qudt.org/vocab/unit/FT"}`, http.StatusBadRequest, 0},
+	} {
+		response := httptest.NewRecorder()
+		context, _ := gin.CreateTestContext(response)
+		context.Request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(test.body))
+		handleConvertUnit(context)
+		if response.Code != test.code {
+			t.Errorf("%s: expected %d, got %d: %s", test.body, test.code, response.Code, response.Body)
+			continue
+		}
+		var conversion UnitConversion
+		if err := json.Unmarshal(response.Body.Bytes(), &conversion); err != nil {
+			t.Fatal(err)
+		}
+		if test.code == http.StatusOK && !qudt.ApproxEquals(conversion.Result, test.expected, 1e-9) {
+			t.Errorf("%s: expected %v, got %v", test.body, test.expected, conversion.Result)
+		}
+	}
+}
+
+func TestHandleGetUnitsListsCompatibleUnits(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	response := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(response)
+	context.Request = httptest.NewRequest(http.MethodGet, "/?quantityKind=Temperature", nil)
+	handleGetUnits(context)
+	if response.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", response.Code, response.Body)
+	}
+	var units []UnitDescription
+	if err := json.Unmarshal(response.Body.Bytes(), &units); err != nil {
+		t.Fatal(err)
+	}
+	uris := make([]string, 0, len(units))
+	for _, unit := range units {
+		uris = append(uris, unit.URI)
+		if unit.DimensionVector != "A0E0L0I0M0H1T0D0" {
+			t.Errorf("unexpected dimension vector of %s: %s", unit.URI, unit.DimensionVector)
+		}
+		if unit.Canonical != (unit.URI == "http://qudt.org/vocab/unit/K") {
+			t.Errorf("unexpected canonical flag of %s", unit.URI)
+		}
+	}
+	if !slices.Contains(uris, "http://qudt.org/vocab/unit/DEG_C") || !slices.Contains(uris, "http://qudt.org/vocab/unit/K") {
+		t.Fatalf("expected celsius and kelvin, got %v", uris)
+	}
+
+	response = httptest.NewRecorder()
+	context, _ = gin.CreateTestContext(response)
+	context.Request = httptest.NewRequest(http.MethodGet, "/?quantityKind=NoSuchKind", nil)
+	handleGetUnits(context)
+	if response.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unknown quantity kind, got %d", response.Code)
+	}
+}
+
+func TestHandleGetUnitCatalogReportsEmbeddedVersion(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	response := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(response)
+	context.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	handleGetUnitCatalog(context)
+	var status qudt.CatalogStatus
+	if err := json.Unmarshal(response.Body.Bytes(), &status); err != nil {
+		t.Fatal(err)
+	}
+	if len(status.Versions) == 0 || status.Units == 0 || status.Conflicts == nil {
+		t.Fatalf("unexpected catalog status %s", response.Body)
+	}
+}
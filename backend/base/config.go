@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log/slog"
 	"regexp"
+	"slices"
 	"strings"
 )
 
@@ -19,6 +20,22 @@ type Config struct {
 	ConversionUnit      string   `json:"conversionUnit"`
 	ConversionQuantity  string   `json:"conversionQuantity"`
 	ConversionValue     string   `json:"conversionValue"`
+	// ConversionPredicates are all quantity predicates whose values are
+	// converted to SI units when indexing.
+	ConversionPredicates []QuantityPredicates `json:"conversionPredicates"`
+	// TextLanguages are the languages whose text literals are indexed with a
+	// language-specific analyzer.
+	TextLanguages []string `json:"textLanguages"`
+}
+
+// QuantityPredicates are the predicates of a quantity value. Values without
+// quantity kind are converted by the dimension of their unit if KindOptional
+// is set.
+type QuantityPredicates struct {
+	Unit         string `json:"unit"`
+	QuantityKind string `json:"quantityKind,omitempty"`
+	Value        string `json:"value"`
+	KindOptional bool   `json:"kindOptional,omitempty"`
 }
 
 type AuthenticatedConfig struct {
@@ -40,6 +57,7 @@ var Configuration = Config{
 	ConversionUnit:      EnvVar("CONVERSION_UNIT", ""),
 	ConversionQuantity:  EnvVar("CONVERSION_QUANTITY", ""),
 	ConversionValue:     EnvVar("CONVERSION_VALUE", ""),
+	TextLanguages:       textLanguages(LabelLanguages),
 }
 
 var ExposeFusekiFrontend = EnvVarAsBool("EXPOSE_FUSEKI_FRONTEND", false)
@@ -53,6 +71,21 @@ var ValidatorEndpoint = EnvVar("VALIDATOR_ENDPOINT", "http://localhost:8000")
 var RdfStandardTaxonomies = EnvVarAsStringSlice("RDF_STANDARD_TAXONOMIES")
 var LabelLanguages = EnvVarAsStringSlice("LABEL_LANGUAGES", "en", "de")
 
+var languageSubtag = regexp.MustCompile(`^[a-z]{2,3}$`)
+
+// textLanguages reduces language tags to their distinct primary subtags,
+// which are valid in Solr field names.
+func textLanguages(tags []string) []string {
+	languages := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		language, _, _ := strings.Cut(strings.ToLower(tag), "-")
+		if languageSubtag.MatchString(language) && !slices.Contains(languages, language) {
+			languages = append(languages, language)
+		}
+	}
+	return languages
+}
+
 // var SyncSchedule = EnvVar("CRON", "*/5 * * * *") // every 5 minutes
 var SyncSchedule = EnvVar("CRON", "")
 
@@ -78,6 +78,7 @@ var AuthUserHeader = "X-User"
 var AuthEmailHeader = "X-Email"
 var AuthGroupsHeader = "X-Groups"
 var AuthWriteAccessGroup = EnvVar("WRITE_ACCESS_GROUP", "")
+var AuthAdminGroup = EnvVar("ADMIN_GROUP", "")
 
 // EnvVar reads an environment variable and falls back to a default when unset.
 // It returns the resolved string value.
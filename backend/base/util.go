@@ -1,6 +1,7 @@
 package base
 
 import (
+	"context"
 	"fmt"
 	"hash/fnv"
 	"io"
@@ -8,8 +9,10 @@ import (
 	"net/http"
 	"os"
 	"path"
+	"rdf-store-backend/metrics"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/deiu/rdf2go"
 )
@@ -30,18 +33,24 @@ func ParseGraph(reader io.Reader) (graph *rdf2go.Graph, err error) {
 	return
 }
 
+// remoteClient loads external RDF documents, e.g. owl:imports and proxied URLs.
+var remoteClient = &http.Client{Timeout: time.Duration(EnvVarAsInt("REMOTE_TIMEOUT", 30)) * time.Second}
+
 // CacheLoad retrieves a URL response and caches the body on disk for future requests.
 // It returns the cached or freshly fetched bytes along with any error.
-func CacheLoad(url string, accept string) ([]byte, error) {
+func CacheLoad(ctx context.Context, url string, accept string) ([]byte, error) {
 	cacheFilename := path.Join("local", "cache", strings.ReplaceAll(url, "/", "🐴"))
 	data, err := os.ReadFile(cacheFilename)
-	if err != nil {
-		req, err := http.NewRequest("GET", url, nil)
+	if err == nil {
+		metrics.CacheLoads.WithLabelValues("hit").Inc()
+	} else {
+		metrics.CacheLoads.WithLabelValues("miss").Inc()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 		if err != nil {
 			return nil, err
 		}
 		req.Header.Set("Accept", accept)
-		resp, err := http.DefaultClient.Do(req)
+		resp, err := remoteClient.Do(req)
 		if err != nil {
 			return nil, err
 		}
@@ -2,8 +2,11 @@ package main
 
 import (
 	"bytes"
+	"context"
+	"flag"
 	"fmt"
 	"os"
+	"os/signal"
 	"rdf-store-backend/base"
 	"rdf-store-backend/profilesync"
 	"rdf-store-backend/rdf"
@@ -13,7 +16,14 @@ import (
 var commands = []string{"reindex", "rebuild", "sync", "relabel"}
 
 func init() {
-	if _, err := rdf.ParseAllProfiles(); err != nil {
+	if _, err := rdf.ParseAllProfiles(context.Background()); err != nil {
+		panic(err)
+	}
+	// index quantities like the server does
+	if err := search.LoadUnitCatalogs(search.UnitCatalogFiles); err != nil {
+		panic(err)
+	}
+	if err := search.LoadQuantityPredicates(search.QuantityPredicatesFile); err != nil {
 		panic(err)
 	}
 }
@@ -24,44 +34,75 @@ func main() {
 		fmt.Println("missing command argument")
 		os.Exit(-1)
 	}
+	// cancel pending requests to the backend services on Ctrl+C
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
 	switch os.Args[1] {
 	case commands[0]:
-		search.Reindex()
+		reindex(ctx, os.Args[2:])
 	case commands[1]:
-		rebuildResourceMeta()
-		search.Reindex()
+		rebuildResourceMeta(ctx)
+		search.Reindex(ctx)
 	case commands[2]:
 		profilesync.Synchronize()
 	case commands[3]:
-		reextractLabels()
+		reextractLabels(ctx)
 	default:
 		fmt.Println("unknown command", os.Args[1], "known commands:", commands)
 		os.Exit(-1)
 	}
 }
 
-func rebuildResourceMeta() {
-	resourceIds, err := rdf.GetAllResourceIds()
+// reindex rebuilds the whole index or, when scope flags are given, only the
+// selected resources.
+func reindex(ctx context.Context, args []string) {
+	flags := flag.NewFlagSet(commands[0], flag.ExitOnError)
+	profile := flags.String("profile", "", "only reindex resources conforming to this profile `id`")
+	since := flags.String("since", "", "only reindex resources modified at or after this `timestamp` (RFC 3339 or YYYY-MM-DD)")
+	resource := flags.String("resource", "", "only reindex the resource with this `id`")
+	flags.Parse(args)
+	scope, err := search.ParseReindexScope(*profile, *since, *resource)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(-1)
+	}
+	if scope.IsEmpty() {
+		search.Reindex(ctx)
+		return
+	}
+	result, err := search.ReindexScoped(ctx, scope)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(-1)
+	}
+	fmt.Println("reindexed", result.Indexed, "of", result.Resources, "resources")
+	for _, id := range result.Failed {
+		fmt.Println("failed", id)
+	}
+}
+
+func rebuildResourceMeta(ctx context.Context) {
+	resourceIds, err := rdf.GetAllResourceIds(ctx)
 	if err != nil {
 		fmt.Println(err)
 		return
 	}
 	for _, resourceId := range resourceIds {
 		fmt.Println("update resource meta for", resourceId)
-		_, _, err := rdf.RebuildResourceConformance(resourceId)
+		_, _, err := rdf.RebuildResourceConformance(ctx, resourceId)
 		if err != nil {
 			fmt.Println(err)
 		}
 	}
 }
 
-func reextractLabels() {
-	profileIds, err := rdf.GetAllProfileIds()
+func reextractLabels(ctx context.Context) {
+	profileIds, err := rdf.GetAllProfileIds(ctx)
 	if err != nil {
 		panic(err)
 	}
 	for _, profileId := range profileIds {
-		data, err := rdf.GetProfile(profileId)
+		data, err := rdf.GetProfile(ctx, profileId)
 		if err != nil {
 			panic(err)
 		}
@@ -70,16 +111,16 @@ func reextractLabels() {
 			panic(err)
 		}
 		fmt.Println("extract labels of profile", profileId)
-		if err := rdf.ExtractLabels(profileId, graph, true); err != nil {
+		if err := rdf.ExtractLabels(ctx, profileId, graph, true); err != nil {
 			panic(err)
 		}
 	}
-	resourceIds, err := rdf.GetAllResourceIds()
+	resourceIds, err := rdf.GetAllResourceIds(ctx)
 	if err != nil {
 		panic(err)
 	}
 	for _, resourceId := range resourceIds {
-		data, _, err := rdf.GetResource(resourceId, false)
+		data, _, err := rdf.GetResource(ctx, resourceId, false)
 		if err != nil {
 			panic(err)
 		}
@@ -88,7 +129,7 @@ func reextractLabels() {
 			panic(err)
 		}
 		fmt.Println("extract labels of resource", resourceId)
-		if err := rdf.ExtractLabels(resourceId, graph, false); err != nil {
+		if err := rdf.ExtractLabels(ctx, resourceId, graph, false); err != nil {
 			panic(err)
 		}
 	}
@@ -0,0 +1,295 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"maps"
+	"rdf-store-backend/base"
+	"rdf-store-backend/rdf"
+	"rdf-store-backend/search"
+	"rdf-store-backend/shacl"
+	"slices"
+	"strings"
+
+	"github.com/deiu/rdf2go"
+)
+
+// maxColumnDepth limits the nesting of the default columns of a profile.
+const maxColumnDepth = 4
+
+// valueSeparator joins multiple values of a path in one CSV cell.
+const valueSeparator = " | "
+
+// column is a CSV column resolved against the profile.
+type column struct {
+	path  []string
+	steps []columnStep
+	label string
+}
+
+// columnStep follows one path segment. Values of qualified properties must
+// conform to the qualified value shape.
+type columnStep struct {
+	path           *shacl.PathExpression
+	qualifiedShape string
+}
+
+// resolveColumns resolves the column paths against the profile, defaulting
+// to all its leaf paths.
+// It returns an error wrapping ErrInvalidExport for unknown profiles or paths.
+func resolveColumns(profileID string, paths [][]string) ([]*column, error) {
+	profile, ok := rdf.Profiles[profileID]
+	if !ok {
+		return nil, fmt.Errorf("%w: csv exports require a known profile, got %q", ErrInvalidExport, profileID)
+	}
+	if len(paths) == 0 {
+		paths = leafPaths(profile, nil, make(map[string]bool))
+	}
+	columns := make([]*column, 0, len(paths))
+	for i, path := range paths {
+		steps, err := resolvePath(profile, path)
+		if err != nil {
+			return nil, fmt.Errorf("%w: column %d: %w", ErrInvalidExport, i, err)
+		}
+		columns = append(columns, &column{path: path, steps: steps})
+	}
+	return columns, nil
+}
+
+// resolvePath follows the segments of a path through the profile and the
+// shapes referenced by its properties.
+func resolvePath(profile *shacl.NodeShape, path []string) ([]columnStep, error) {
+	if len(path) == 0 {
+		return nil, fmt.Errorf("empty path")
+	}
+	shapes := []*shacl.NodeShape{profile}
+	steps := make([]columnStep, 0, len(path))
+	for _, segment := range path {
+		properties := findProperties(shapes, segment)
+		if len(properties) == 0 {
+			return nil, fmt.Errorf("unknown path segment %q", segment)
+		}
+		step := columnStep{path: properties[0].PathExpression}
+		if step.path == nil {
+			step.path = shacl.NewPredicatePath(properties[0].Path)
+		}
+		if properties[0].Id != nil && properties[0].Id.RawValue() == segment {
+			step.qualifiedShape = properties[0].QualifiedValueShape
+		}
+		steps = append(steps, step)
+		shapes = childShapes(properties)
+	}
+	return steps, nil
+}
+
+// findProperties returns the properties of the shapes, including inherited and
+// alternative ones, that match a path segment. Segments are predicate IRIs or,
+// for qualified properties, property shape IDs, as used by the indexer.
+func findProperties(shapes []*shacl.NodeShape, segment string) []*shacl.Property {
+	result := make([]*shacl.Property, 0)
+	for _, property := range shapeProperties(shapes) {
+		if propertySegment(property) == segment {
+			result = append(result, property)
+		}
+	}
+	return result
+}
+
+// shapeProperties collects the properties of shapes and of their parents and alternatives.
+func shapeProperties(shapes []*shacl.NodeShape) []*shacl.Property {
+	visited := make(map[string]bool)
+	result := make([]*shacl.Property, 0)
+	var collect func(shape *shacl.NodeShape)
+	collect = func(shape *shacl.NodeShape) {
+		if visited[shape.Id.RawValue()] {
+			return
+		}
+		visited[shape.Id.RawValue()] = true
+		for _, properties := range shape.Properties {
+			result = append(result, properties...)
+		}
+		for id := range shape.Parents {
+			if parent, ok := rdf.Profiles[id]; ok {
+				collect(parent)
+			}
+		}
+		for id := range shape.Alternatives {
+			if alternative, ok := rdf.Profiles[id]; ok {
+				collect(alternative)
+			}
+		}
+	}
+	for _, shape := range shapes {
+		collect(shape)
+	}
+	return result
+}
+
+func propertySegment(property *shacl.Property) string {
+	if property.QualifiedValueShape != "" {
+		return property.Id.RawValue()
+	}
+	return property.Path
+}
+
+// childShapes returns the known shapes that values of the properties conform
+// to, ordered by ID.
+func childShapes(properties []*shacl.Property) []*shacl.NodeShape {
+	ids := make(map[string]bool)
+	for _, property := range properties {
+		for id := range property.NodeShapes {
+			ids[id] = true
+		}
+		for id := range property.AlternativeNodeShapes {
+			ids[id] = true
+		}
+		if property.QualifiedValueShape != "" {
+			ids[property.QualifiedValueShape] = true
+		}
+	}
+	shapes := make([]*shacl.NodeShape, 0, len(ids))
+	for _, id := range slices.Sorted(maps.Keys(ids)) {
+		if shape, ok := rdf.Profiles[id]; ok {
+			shapes = append(shapes, shape)
+		}
+	}
+	return shapes
+}
+
+// leafPaths lists the paths of all properties without nested shapes below the
+// shape, sorted by path.
+func leafPaths(shape *shacl.NodeShape, prefix []string, active map[string]bool) [][]string {
+	active[shape.Id.RawValue()] = true
+	defer delete(active, shape.Id.RawValue())
+	bySegment := make(map[string][]*shacl.Property)
+	for _, property := range shapeProperties([]*shacl.NodeShape{shape}) {
+		segment := propertySegment(property)
+		bySegment[segment] = append(bySegment[segment], property)
+	}
+	segments := make([]string, 0, len(bySegment))
+	for segment := range bySegment {
+		segments = append(segments, segment)
+	}
+	slices.Sort(segments)
+	result := make([][]string, 0)
+	for _, segment := range segments {
+		path := append(slices.Clone(prefix), segment)
+		children := slices.DeleteFunc(childShapes(bySegment[segment]), func(child *shacl.NodeShape) bool {
+			return active[child.Id.RawValue()]
+		})
+		// recursive references and paths at the depth limit export the referenced nodes
+		if len(children) == 0 || len(path) >= maxColumnDepth {
+			result = append(result, path)
+			continue
+		}
+		for _, child := range children {
+			result = append(result, leafPaths(child, path, active)...)
+		}
+	}
+	return result
+}
+
+// labelColumns labels columns with the labels of their path segments.
+func labelColumns(ctx context.Context, columns []*column, language string) error {
+	ids := make([]string, 0)
+	for _, column := range columns {
+		for _, segment := range column.path {
+			if shacl.IsPredicateKey(segment) {
+				ids = append(ids, rdf2go.NewResource(segment).String())
+			}
+		}
+	}
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+	var labels map[string]string
+	var err error
+	if language == "" {
+		labels, err = rdf.GetDefaultLabels(ctx, ids)
+	} else {
+		labels, err = rdf.GetLabels(ctx, language, ids)
+	}
+	if err != nil {
+		return fmt.Errorf("loading column labels: %w", err)
+	}
+	for _, column := range columns {
+		parts := make([]string, 0, len(column.path))
+		for _, segment := range column.path {
+			label := labels[rdf2go.NewResource(segment).String()]
+			if label == "" {
+				label = segment
+			}
+			parts = append(parts, label)
+		}
+		column.label = strings.Join(parts, " / ")
+	}
+	return nil
+}
+
+// values resolves the column for an entity of a resource graph.
+func (column *column) values(graph *rdf2go.Graph, metadata *rdf.ResourceMetadata, subject rdf2go.Term) string {
+	nodes := []rdf2go.Term{subject}
+	for _, step := range column.steps {
+		next := make([]rdf2go.Term, 0)
+		for _, node := range nodes {
+			for _, value := range step.path.Evaluate(node, graph) {
+				if step.qualifiedShape != "" && !slices.Contains(metadata.Conformance[value.RawValue()], step.qualifiedShape) {
+					continue
+				}
+				next = append(next, value)
+			}
+		}
+		nodes = next
+	}
+	values := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		values = append(values, node.RawValue())
+	}
+	slices.Sort(values)
+	return strings.Join(slices.Compact(values), valueSeparator)
+}
+
+// writeCSV writes one row per entity with its subject, label and columns.
+func (export *Export) writeCSV(ctx context.Context, w io.Writer) error {
+	writer := csv.NewWriter(w)
+	header := []string{"subject", "label"}
+	for _, column := range export.columns {
+		header = append(header, column.label)
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	var resourceID string
+	var graph *rdf2go.Graph
+	var metadata *rdf.ResourceMetadata
+	err := export.forEachHit(ctx, func(hit search.SearchHit) error {
+		// hits are sorted by ID, so entities of a resource are adjacent
+		if hit.ResourceID != resourceID {
+			turtle, resourceMetadata, err := rdf.GetResource(ctx, hit.ResourceID, false)
+			if err != nil {
+				return fmt.Errorf("loading resource %s: %w", hit.ResourceID, err)
+			}
+			if graph, err = base.ParseGraph(bytes.NewReader(turtle)); err != nil {
+				return fmt.Errorf("parsing resource %s: %w", hit.ResourceID, err)
+			}
+			resourceID, metadata = hit.ResourceID, resourceMetadata
+		}
+		return writer.Write(export.row(hit, graph, metadata))
+	})
+	if err != nil {
+		return err
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func (export *Export) row(hit search.SearchHit, graph *rdf2go.Graph, metadata *rdf.ResourceMetadata) []string {
+	row := []string{hit.Subject, hit.Label}
+	subject := rdf2go.NewResource(hit.Subject)
+	for _, column := range export.columns {
+		row = append(row, column.values(graph, metadata, subject))
+	}
+	return row
+}
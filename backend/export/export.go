@@ -0,0 +1,175 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"rdf-store-backend/base"
+	"rdf-store-backend/rdf"
+	"rdf-store-backend/search"
+
+	"github.com/deiu/rdf2go"
+)
+
+// Export formats.
+const (
+	FormatCSV    = "csv"
+	FormatTurtle = "turtle"
+	FormatNQuads = "nquads"
+	FormatJSONLD = "jsonld"
+)
+
+const pageSize = 100
+
+// MaxEntities bounds the number of entities of a single export.
+var MaxEntities = base.EnvVarAsInt("EXPORT_MAX_ENTITIES", 10000)
+
+// ErrInvalidExport wraps errors caused by malformed export options.
+var ErrInvalidExport = errors.New("invalid export")
+
+// ErrTooManyEntities is returned when a search matches more than MaxEntities entities.
+var ErrTooManyEntities = errors.New("too many entities")
+
+var contentTypes = map[string]string{
+	FormatCSV:    "text/csv; charset=utf-8",
+	FormatTurtle: "application/zip",
+	FormatNQuads: "application/n-quads",
+	FormatJSONLD: "application/ld+json",
+}
+
+var extensions = map[string]string{
+	FormatCSV:    "csv",
+	FormatTurtle: "zip",
+	FormatNQuads: "nq",
+	FormatJSONLD: "jsonld",
+}
+
+// Options selects the entities and the format of an export.
+type Options struct {
+	Query  search.SolrQuery
+	Format string
+	// Profile is the node shape whose property paths are the CSV columns.
+	Profile string
+	// Columns are the SHACL paths exported as CSV columns, in the segment
+	// notation of search criteria. All leaf paths of Profile are exported
+	// when empty.
+	Columns [][]string
+	// Language selects the language of the CSV column labels.
+	Language string
+}
+
+// Export is a prepared export whose first page of entities has been fetched.
+type Export struct {
+	options Options
+	cursor  *search.Cursor
+	first   []search.SearchHit
+	columns []*column
+}
+
+// Prepare validates the options and fetches the first page of matching entities.
+// It returns an error wrapping ErrInvalidExport for malformed options and
+// ErrTooManyEntities when more than MaxEntities entities match.
+func Prepare(ctx context.Context, options Options) (*Export, error) {
+	if _, ok := contentTypes[options.Format]; !ok {
+		return nil, fmt.Errorf("%w: unsupported format %q, expected %s, %s, %s or %s", ErrInvalidExport, options.Format, FormatCSV, FormatTurtle, FormatNQuads, FormatJSONLD)
+	}
+	export := &Export{options: options, cursor: search.NewCursor(options.Query, pageSize)}
+	if options.Format == FormatCSV {
+		columns, err := resolveColumns(options.Profile, options.Columns)
+		if err != nil {
+			return nil, err
+		}
+		if err := labelColumns(ctx, columns, options.Language); err != nil {
+			return nil, err
+		}
+		export.columns = columns
+	}
+	first, err := export.cursor.Next(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if export.cursor.Total > MaxEntities {
+		return nil, fmt.Errorf("%w: the search matches %d entities, exports are limited to %d", ErrTooManyEntities, export.cursor.Total, MaxEntities)
+	}
+	export.first = first
+	return export, nil
+}
+
+// Total returns the number of exported entities.
+func (export *Export) Total() int {
+	return export.cursor.Total
+}
+
+// ContentType returns the media type of the export.
+func (export *Export) ContentType() string {
+	return contentTypes[export.options.Format]
+}
+
+// Filename returns a file name for the export.
+func (export *Export) Filename() string {
+	return "export." + extensions[export.options.Format]
+}
+
+// Write streams the export. Entities are written page by page, so a failure
+// leaves a truncated export behind.
+func (export *Export) Write(ctx context.Context, w io.Writer) error {
+	switch export.options.Format {
+	case FormatCSV:
+		return export.writeCSV(ctx, w)
+	case FormatTurtle:
+		return export.writeResources(ctx, newTurtleZipWriter(w))
+	case FormatNQuads:
+		return export.writeResources(ctx, &nQuadsWriter{w: w})
+	default:
+		return export.writeResources(ctx, &jsonLDWriter{w: w})
+	}
+}
+
+// forEachHit calls fn for every matching entity.
+func (export *Export) forEachHit(ctx context.Context, fn func(search.SearchHit) error) error {
+	hits := export.first
+	for len(hits) > 0 {
+		for _, hit := range hits {
+			if err := fn(hit); err != nil {
+				return err
+			}
+		}
+		var err error
+		if hits, err = export.cursor.Next(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resourceWriter serializes resource graphs into an export format.
+type resourceWriter interface {
+	writeResource(id string, turtle []byte, graph *rdf2go.Graph) error
+	close() error
+}
+
+// writeResources writes the resource graph of every matching entity once.
+func (export *Export) writeResources(ctx context.Context, writer resourceWriter) error {
+	written := make(map[string]bool)
+	err := export.forEachHit(ctx, func(hit search.SearchHit) error {
+		if written[hit.ResourceID] {
+			return nil
+		}
+		written[hit.ResourceID] = true
+		turtle, _, err := rdf.GetResource(ctx, hit.ResourceID, false)
+		if err != nil {
+			return fmt.Errorf("loading resource %s: %w", hit.ResourceID, err)
+		}
+		graph, err := base.ParseGraph(bytes.NewReader(turtle))
+		if err != nil {
+			return fmt.Errorf("parsing resource %s: %w", hit.ResourceID, err)
+		}
+		return writer.writeResource(hit.ResourceID, turtle, graph)
+	})
+	if err != nil {
+		return err
+	}
+	return writer.close()
+}
@@ -0,0 +1,202 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"rdf-store-backend/rdf"
+	"rdf-store-backend/shacl"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/deiu/rdf2go"
+)
+
+const (
+	datasetID    = "http://example.org/Dataset"
+	personID     = "http://example.org/Person"
+	titlePath    = "http://purl.org/dc/terms/title"
+	creatorPath  = "http://purl.org/dc/terms/creator"
+	namePath     = "http://xmlns.com/foaf/0.1/name"
+	contactShape = "urn:property:contact"
+)
+
+func useTestProfiles(t *testing.T) {
+	dataset := &shacl.NodeShape{
+		Id: rdf2go.NewResource(datasetID), Parents: map[string]bool{}, Alternatives: map[string]bool{},
+		Properties: map[string][]*shacl.Property{
+			titlePath: {{Id: rdf2go.NewResource("urn:property:title"), Path: titlePath}},
+			creatorPath: {
+				{Id: rdf2go.NewResource("urn:property:creator"), Path: creatorPath, NodeShapes: map[string]bool{personID: true}},
+				{Id: rdf2go.NewResource(contactShape), Path: creatorPath, QualifiedValueShape: personID},
+			},
+		},
+	}
+	person := &shacl.NodeShape{
+		Id: rdf2go.NewResource(personID), Parents: map[string]bool{}, Alternatives: map[string]bool{},
+		Properties: map[string][]*shacl.Property{
+			namePath: {{Id: rdf2go.NewResource("urn:property:name"), Path: namePath}},
+			// recursive reference, which must not be followed for default columns
+			creatorPath: {{Id: rdf2go.NewResource("urn:property:personCreator"), Path: creatorPath, NodeShapes: map[string]bool{personID: true}}},
+		},
+	}
+	previousProfiles := rdf.Profiles
+	rdf.Profiles = map[string]*shacl.NodeShape{datasetID: dataset, personID: person}
+	t.Cleanup(func() { rdf.Profiles = previousProfiles })
+}
+
+func TestResolveColumnsDefaultsToLeafPaths(t *testing.T) {
+	useTestProfiles(t)
+	columns, err := resolveColumns(datasetID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths := make([]string, 0, len(columns))
+	for _, column := range columns {
+		paths = append(paths, strings.Join(column.path, " "))
+	}
+	expected := []string{
+		creatorPath + " " + creatorPath,
+		creatorPath + " " + namePath,
+		titlePath,
+		contactShape + " " + creatorPath,
+		contactShape + " " + namePath,
+	}
+	if !slices.Equal(paths, expected) {
+		t.Fatalf("unexpected default columns:\n%s", strings.Join(paths, "\n"))
+	}
+
+	for _, invalid := range [][]string{{}, {"http://example.org/unknown"}, {titlePath, namePath}} {
+		if _, err := resolveColumns(datasetID, [][]string{invalid}); err == nil {
+			t.Errorf("expected error for path %v", invalid)
+		}
+	}
+	if _, err := resolveColumns("http://example.org/Unknown", nil); err == nil {
+		t.Error("expected error for unknown profile")
+	}
+}
+
+func TestColumnValuesFollowQualifiedShapes(t *testing.T) {
+	useTestProfiles(t)
+	columns, err := resolveColumns(datasetID, [][]string{{titlePath}, {creatorPath, namePath}, {contactShape, namePath}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dataset := rdf2go.NewResource("http://example.org/dataset/1")
+	alice := rdf2go.NewResource("http://example.org/alice")
+	bob := rdf2go.NewBlankNode("bob")
+	graph := rdf2go.NewGraph("")
+	graph.AddTriple(dataset, rdf2go.NewResource(titlePath), rdf2go.NewLiteralWithLanguage("Soil samples", "en"))
+	graph.AddTriple(dataset, rdf2go.NewResource(creatorPath), alice)
+	graph.AddTriple(dataset, rdf2go.NewResource(creatorPath), bob)
+	graph.AddTriple(alice, rdf2go.NewResource(namePath), rdf2go.NewLiteral("Alice"))
+	graph.AddTriple(bob, rdf2go.NewResource(namePath), rdf2go.NewLiteral("Bob"))
+	metadata := &rdf.ResourceMetadata{Conformance: map[string][]string{alice.RawValue(): {personID}}}
+
+	values := make([]string, 0, len(columns))
+	for _, column := range columns {
+		values = append(values, column.values(graph, metadata, dataset))
+	}
+	if expected := []string{"Soil samples", "Alice | Bob", "Alice"}; !slices.Equal(values, expected) {
+		t.Fatalf("expected %q, got %q", expected, values)
+	}
+}
+
+func testGraph(t *testing.T) ([]byte, *rdf2go.Graph) {
+	turtle := []byte(`<http://example.org/r> <http://purl.org/dc/terms/title> "100% \"done\""@en ;
+	<http://purl.org/dc/terms/creator> _:b .
+_:b <http://xmlns.com/foaf/0.1/age> "42"^^<http://www.w3.org/2001/XMLSchema#integer> .
+`)
+	graph := rdf2go.NewGraph("")
+	if err := graph.Parse(bytes.NewReader(turtle), "text/turtle"); err != nil {
+		t.Fatal(err)
+	}
+	return turtle, graph
+}
+
+func TestNQuadsWriterScopesBlankNodes(t *testing.T) {
+	turtle, graph := testGraph(t)
+	var buf bytes.Buffer
+	writer := &nQuadsWriter{w: &buf}
+	for _, id := range []string{"http://example.org/r", "http://example.org/s"} {
+		if err := writer.writeResource(id, turtle, graph); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 6 {
+		t.Fatalf("expected 6 quads, got:\n%s", buf.String())
+	}
+	for _, expected := range []string{
+		`<http://example.org/r> <http://purl.org/dc/terms/creator> _:r1_`,
+		`"100% \"done\""@en <http://example.org/r> .`,
+		`"42"^^<http://www.w3.org/2001/XMLSchema#integer> <http://example.org/s> .`,
+		`<http://example.org/r> <http://purl.org/dc/terms/creator> _:r2_`,
+	} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("missing %q in:\n%s", expected, buf.String())
+		}
+	}
+}
+
+func TestJSONLDWriterWritesNamedGraphs(t *testing.T) {
+	turtle, graph := testGraph(t)
+	var buf bytes.Buffer
+	writer := &jsonLDWriter{w: &buf}
+	for _, id := range []string{"http://example.org/r", "http://example.org/s"} {
+		if err := writer.writeResource(id, turtle, graph); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.close(); err != nil {
+		t.Fatal(err)
+	}
+	var document []struct {
+		ID    string           `json:"@id"`
+		Graph []map[string]any `json:"@graph"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &document); err != nil {
+		t.Fatalf("invalid JSON-LD: %v\n%s", err, buf.String())
+	}
+	if len(document) != 2 || document[1].ID != "http://example.org/s" || len(document[1].Graph) != 2 {
+		t.Fatalf("unexpected document %s", buf.String())
+	}
+	if !strings.Contains(buf.String(), `{"@language":"en","@value":"100% \"done\""}`) {
+		t.Errorf("missing literal in %s", buf.String())
+	}
+
+	buf.Reset()
+	if err := (&jsonLDWriter{w: &buf}).close(); err != nil || strings.TrimSpace(buf.String()) != "[]" {
+		t.Fatalf("unexpected empty document %q, %v", buf.String(), err)
+	}
+}
+
+func TestTurtleZipWriterStoresResources(t *testing.T) {
+	turtle, graph := testGraph(t)
+	var buf bytes.Buffer
+	writer := newTurtleZipWriter(&buf)
+	if err := writer.writeResource("http://example.org/r", turtle, graph); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.close(); err != nil {
+		t.Fatal(err)
+	}
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(archive.File) != 1 || archive.File[0].Name != "http%3A%2F%2Fexample.org%2Fr.ttl" {
+		t.Fatalf("unexpected archive content %v", archive.File)
+	}
+	file, err := archive.File[0].Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	data, _ := io.ReadAll(file)
+	if !bytes.Equal(data, turtle) {
+		t.Fatalf("unexpected file content %s", data)
+	}
+}
@@ -0,0 +1,144 @@
+package export
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/deiu/rdf2go"
+)
+
+// turtleZipWriter writes every resource as a Turtle file into a zip archive.
+type turtleZipWriter struct {
+	archive *zip.Writer
+}
+
+func newTurtleZipWriter(w io.Writer) *turtleZipWriter {
+	return &turtleZipWriter{archive: zip.NewWriter(w)}
+}
+
+func (writer *turtleZipWriter) writeResource(id string, turtle []byte, graph *rdf2go.Graph) error {
+	file, err := writer.archive.Create(url.QueryEscape(id) + ".ttl")
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(turtle)
+	return err
+}
+
+func (writer *turtleZipWriter) close() error {
+	return writer.archive.Close()
+}
+
+// nQuadsWriter writes all resources as one N-Quads dump with a named graph
+// per resource.
+type nQuadsWriter struct {
+	w         io.Writer
+	resources int
+}
+
+func (writer *nQuadsWriter) writeResource(id string, turtle []byte, graph *rdf2go.Graph) error {
+	writer.resources++
+	graphName := rdf2go.NewResource(id).String()
+	lines := make([]string, 0, graph.Len())
+	for triple := range graph.IterTriples() {
+		lines = append(lines, fmt.Sprintf("%s %s %s %s .\n",
+			scopeTerm(triple.Subject, writer.resources).String(),
+			triple.Predicate.String(),
+			scopeTerm(triple.Object, writer.resources).String(),
+			graphName))
+	}
+	slices.Sort(lines)
+	_, err := io.WriteString(writer.w, strings.Join(lines, ""))
+	return err
+}
+
+func (writer *nQuadsWriter) close() error {
+	return nil
+}
+
+// jsonLDWriter writes all resources as one expanded JSON-LD document with a
+// named graph per resource.
+type jsonLDWriter struct {
+	w         io.Writer
+	resources int
+}
+
+func (writer *jsonLDWriter) writeResource(id string, turtle []byte, graph *rdf2go.Graph) error {
+	writer.resources++
+	nodes := make(map[string]map[string]any)
+	order := make([]string, 0)
+	for triple := range graph.IterTriples() {
+		subject := jsonLDID(scopeTerm(triple.Subject, writer.resources))
+		node, ok := nodes[subject]
+		if !ok {
+			node = map[string]any{"@id": subject}
+			nodes[subject] = node
+			order = append(order, subject)
+		}
+		predicate := triple.Predicate.RawValue()
+		values, _ := node[predicate].([]map[string]string)
+		node[predicate] = append(values, jsonLDValue(scopeTerm(triple.Object, writer.resources)))
+	}
+	slices.Sort(order)
+	namedGraph := make([]map[string]any, 0, len(order))
+	for _, subject := range order {
+		namedGraph = append(namedGraph, nodes[subject])
+	}
+	data, err := json.Marshal(map[string]any{"@id": id, "@graph": namedGraph})
+	if err != nil {
+		return err
+	}
+	separator := ",\n"
+	if writer.resources == 1 {
+		separator = "[\n"
+	}
+	if _, err := io.WriteString(writer.w, separator); err != nil {
+		return err
+	}
+	_, err = writer.w.Write(data)
+	return err
+}
+
+func (writer *jsonLDWriter) close() error {
+	closing := "\n]\n"
+	if writer.resources == 0 {
+		closing = "[]\n"
+	}
+	_, err := io.WriteString(writer.w, closing)
+	return err
+}
+
+// scopeTerm prefixes blank node labels with the resource number, so blank
+// nodes of different resources stay distinct in a combined dump.
+func scopeTerm(term rdf2go.Term, resource int) rdf2go.Term {
+	if blank, ok := term.(*rdf2go.BlankNode); ok {
+		return rdf2go.NewBlankNode(fmt.Sprintf("r%d_%s", resource, blank.ID))
+	}
+	return term
+}
+
+func jsonLDID(term rdf2go.Term) string {
+	if _, ok := term.(*rdf2go.BlankNode); ok {
+		return term.String()
+	}
+	return term.RawValue()
+}
+
+func jsonLDValue(term rdf2go.Term) map[string]string {
+	literal, ok := term.(*rdf2go.Literal)
+	if !ok {
+		return map[string]string{"@id": jsonLDID(term)}
+	}
+	value := map[string]string{"@value": literal.Value}
+	if literal.Language != "" {
+		value["@language"] = literal.Language
+	} else if literal.Datatype != nil {
+		value["@type"] = literal.Datatype.RawValue()
+	}
+	return value
+}
@@ -0,0 +1,89 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"rdf-store-backend/base"
+	"rdf-store-backend/tracing"
+	"time"
+)
+
+var maxRetries = base.EnvVarAsInt("HTTP_MAX_RETRIES", 3)
+var maxIdleConnsPerHost = base.EnvVarAsInt("HTTP_MAX_IDLE_CONNS_PER_HOST", 32)
+var retryBackoff = 200 * time.Millisecond
+
+// New creates a client for a backend service. It keeps a pool of idle
+// connections to the service, bounds each request by timeout and records
+// a trace span per request.
+func New(service string, timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: tracing.Transport(NewTransport(), service),
+	}
+}
+
+// NewTransport creates a transport that keeps enough idle connections per
+// host for the concurrent requests the backend sends to a single service.
+func NewTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = maxIdleConnsPerHost
+	return transport
+}
+
+// Timeout reads a timeout in seconds from the environment variable key.
+// It returns the default when the variable is not set.
+func Timeout(key string, defaultSeconds int) time.Duration {
+	return time.Duration(base.EnvVarAsInt(key, defaultSeconds)) * time.Second
+}
+
+// Do sends a request with the client. Idempotent requests are retried with
+// exponential backoff when the connection fails or the service responds with
+// 502, 503 or 504. Timeouts and canceled requests are not retried.
+// It returns the last response and error.
+func Do(client *http.Client, req *http.Request, idempotent bool) (*http.Response, error) {
+	canRewind := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+	for attempt := 0; ; attempt++ {
+		resp, err := client.Do(req)
+		if !idempotent || !canRewind || attempt >= maxRetries || !shouldRetry(req.Context(), resp, err) {
+			return resp, err
+		}
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		if req.GetBody != nil {
+			body, bodyErr := req.GetBody()
+			if bodyErr != nil {
+				return nil, bodyErr
+			}
+			req.Body = body
+		}
+		delay := retryBackoff << attempt
+		slog.Debug("retrying request", "method", req.Method, "url", req.URL.Redacted(), "attempt", attempt+1, "delay", delay, "error", err)
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// shouldRetry reports whether a failed attempt is likely to succeed when repeated.
+func shouldRetry(ctx context.Context, resp *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		var netErr net.Error
+		return !(errors.As(err, &netErr) && netErr.Timeout())
+	}
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+package httpclient
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func flakyServer(t *testing.T, failures int32) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if calls.Add(1) <= failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func TestDoRetriesIdempotentRequests(t *testing.T) {
+	retryBackoff = time.Millisecond
+	server, calls := flakyServer(t, 2)
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodPost, server.URL, strings.NewReader("query"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := Do(New("test", time.Second), req, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || string(body) != "query" {
+		t.Fatalf("expected replayed body after retries, got %d %q", resp.StatusCode, body)
+	}
+	if calls.Load() != 3 {
+		t.Fatalf("expected 3 attempts, got %d", calls.Load())
+	}
+}
+
+func TestDoDoesNotRetryNonIdempotentRequests(t *testing.T) {
+	retryBackoff = time.Millisecond
+	server, calls := flakyServer(t, 1)
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodPost, server.URL, strings.NewReader("update"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := Do(New("test", time.Second), req, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable || calls.Load() != 1 {
+		t.Fatalf("expected a single failed attempt, got status %d after %d attempts", resp.StatusCode, calls.Load())
+	}
+}
@@ -1,51 +1,119 @@
 package main
 
 import (
+	"context"
 	"embed"
+	"errors"
 	"io/fs"
 	"log"
 	"log/slog"
 	"mime"
 	"net/http"
 	"os"
+	"os/signal"
 	"path"
 	"path/filepath"
+	"rdf-store-backend/alerts"
 	"rdf-store-backend/api"
 	"rdf-store-backend/base"
 	"rdf-store-backend/profilesync"
 	"rdf-store-backend/rdf"
 	"rdf-store-backend/search"
+	"rdf-store-backend/tracing"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/robfig/cron/v3"
 )
 
+// shutdownTimeout bounds finishing pending requests and flushing traces on shutdown.
+const shutdownTimeout = 10 * time.Second
+
 // main starts background tasks and serves the HTTP API plus static files.
 func main() {
+	shutdownTracing, err := tracing.Init()
+	if err != nil {
+		log.Fatal(err)
+	}
+	// unit catalogs are merged before requests may convert units
+	if err := search.LoadUnitCatalogs(search.UnitCatalogFiles); err != nil {
+		log.Fatal(err)
+	}
 	// handle non-API requests by trying to serve embedded static files (frontend and swagger UI)
 	api.Router.NoRoute(serveStaticFiles())
 	go func() {
-		if err := search.Init(false); err != nil {
+		err := initialize(context.Background())
+		api.StartupFinished(err)
+		if err != nil {
+			// exit to be restarted instead of serving without index or profiles
 			log.Fatal(err)
 		}
-		if err := startSyncProfiles(); err != nil {
+	}()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	server := &http.Server{Addr: ":3000", Handler: api.Router.Handler()}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			log.Fatal(err)
 		}
-		importLocalResources()
 	}()
-	if err := api.Router.Run(":3000"); err != nil {
-		log.Fatal(err)
+	<-ctx.Done()
+	stop()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		slog.Error("failed shutting down server", "error", err)
+	}
+	// flush the spans still buffered by the exporter
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		slog.Error("failed shutting down tracing", "error", err)
+	}
+}
+
+// initialize prepares the search index, profiles and local resources.
+// It returns an error when the search index or profile sync cannot be set up.
+func initialize(ctx context.Context) error {
+	if err := search.LoadRanking(search.RankingFile); err != nil {
+		return err
+	}
+	if err := search.LoadQuantityPredicates(search.QuantityPredicatesFile); err != nil {
+		return err
+	}
+	if err := search.Init(ctx, false); err != nil {
+		return err
+	}
+	if err := startSyncProfiles(ctx); err != nil {
+		return err
+	}
+	importLocalResources(ctx)
+	startAlerts()
+	return nil
+}
+
+// startAlerts schedules checking subscribed saved searches when an alert sink is configured.
+func startAlerts() {
+	if !alerts.Enabled() {
+		return
+	}
+	c := cron.New()
+	if _, err := c.AddFunc(alerts.Schedule, alerts.Run); err != nil {
+		slog.Error("failed scheduling saved search alerts", "cron", alerts.Schedule, "error", err)
+		return
 	}
+	c.Start()
+	slog.Info("started scheduled saved search alerts", "cron", alerts.Schedule, "sink", alerts.SinkType)
 }
 
 // startSyncProfiles loads profiles and starts the optional scheduled sync loop.
 // It returns an error when profile parsing fails or scheduling cannot be set up.
-func startSyncProfiles() error {
-	profiles, err := rdf.ParseAllProfiles()
+func startSyncProfiles(ctx context.Context) error {
+	profiles, err := rdf.ParseAllProfiles(ctx)
 	if err != nil {
 		return err
 	}
+	search.UpdateRankedPaths()
 
 	if len(base.SyncSchedule) > 0 {
 		c := cron.New()
@@ -118,15 +186,15 @@ func serveStaticFiles() func(c *gin.Context) {
 
 // importLocalResources loads local RDF graphs into the resource dataset.
 // It returns an error if any local graph cannot be read or uploaded.
-func importLocalResources() {
+func importLocalResources(ctx context.Context) {
 	baseDir := path.Join("local", "datagraph")
 	if files, err := os.ReadDir(baseDir); err == nil {
 		for _, file := range files {
 			if !file.IsDir() && strings.HasSuffix(file.Name(), ".ttl") {
 				slog.Info("importing resource graph", "file", file.Name())
 				if data, err := os.ReadFile(path.Join(baseDir, file.Name())); err == nil {
-					if resource, metadata, err := rdf.CreateResource(data, ""); err == nil {
-						err = search.IndexResource(resource, metadata)
+					if resource, metadata, err := rdf.CreateResource(ctx, data, ""); err == nil {
+						err = search.IndexResource(ctx, resource, metadata)
 					}
 				}
 				if err != nil {
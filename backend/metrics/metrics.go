@@ -0,0 +1,120 @@
+package metrics
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "rdfstore"
+
+// Backend service names used as the "service" label of backend call metrics.
+const (
+	ServiceFuseki    = "fuseki"
+	ServiceSolr      = "solr"
+	ServiceValidator = "validator"
+)
+
+var (
+	// HTTPRequests counts API requests by method, route template and status code.
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "Number of HTTP requests by method, route and status code.",
+	}, []string{"method", "route", "status"})
+	// HTTPRequestDuration observes API request latencies by method and route template.
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of HTTP requests by method and route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+	// BackendRequestDuration observes calls to Fuseki, Solr and the validator.
+	BackendRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "backend",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of requests to backend services by service and operation.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"service", "operation"})
+	// BackendRequestErrors counts failed calls to Fuseki, Solr and the validator.
+	BackendRequestErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "backend",
+		Name:      "request_errors_total",
+		Help:      "Number of failed requests to backend services by service and operation.",
+	}, []string{"service", "operation"})
+	// IndexedDocuments counts Solr documents submitted by document type.
+	IndexedDocuments = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "search",
+		Name:      "indexed_documents_total",
+		Help:      "Number of documents submitted to the search index by document type.",
+	}, []string{"doc_type"})
+	// ProfileSyncChanges counts profiles detected by profile synchronization.
+	ProfileSyncChanges = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "profile_sync",
+		Name:      "profiles_total",
+		Help:      "Number of new, changed and deleted profiles detected by profile synchronization.",
+	}, []string{"result"})
+	// AlertDeliveries counts saved search alerts by sink and result.
+	AlertDeliveries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "alerts",
+		Name:      "deliveries_total",
+		Help:      "Number of saved search alert deliveries by sink and result (ok or error).",
+	}, []string{"sink", "result"})
+	// CacheLoads counts lookups of the on-disk URL cache by result.
+	CacheLoads = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "cache",
+		Name:      "loads_total",
+		Help:      "Number of URL cache lookups by result (hit or miss).",
+	}, []string{"result"})
+)
+
+// Handler serves all registered metrics in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// ObserveBackendCall records the duration of a backend call started at start.
+// Transport errors and server errors (5xx) count as failures.
+func ObserveBackendCall(service, operation string, start time.Time, status int, err error) {
+	BackendRequestDuration.WithLabelValues(service, operation).Observe(time.Since(start).Seconds())
+	if err != nil || status >= http.StatusInternalServerError {
+		BackendRequestErrors.WithLabelValues(service, operation).Inc()
+	}
+}
+
+// ObserveBackendWrite records the duration of a backend write started at
+// start. Unlike reads, whose client errors may be expected lookups, every
+// response outside 2xx counts as a failure, as the write was not applied.
+func ObserveBackendWrite(service, operation string, start time.Time, status int, err error) {
+	BackendRequestDuration.WithLabelValues(service, operation).Observe(time.Since(start).Seconds())
+	if err != nil || status < http.StatusOK || status >= http.StatusMultipleChoices {
+		BackendRequestErrors.WithLabelValues(service, operation).Inc()
+	}
+}
+
+// FusekiOperation classifies a Fuseki request path into a low-cardinality
+// operation label.
+func FusekiOperation(path string) string {
+	switch {
+	case strings.Contains(path, "/$/"):
+		return "admin"
+	case strings.HasSuffix(path, "/update"):
+		return "update"
+	case strings.HasSuffix(path, "/data"):
+		return "data"
+	default:
+		return "query"
+	}
+}
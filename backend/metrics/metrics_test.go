@@ -0,0 +1,33 @@
+package metrics
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestFusekiOperation(t *testing.T) {
+	for path, expected := range map[string]string{
+		"/$/stats/resource": "admin",
+		"/$/datasets":       "admin",
+		"/resource/update":  "update",
+		"/resource/data":    "data",
+		"/resource":         "query",
+	} {
+		if operation := FusekiOperation(path); operation != expected {
+			t.Errorf("expected operation %q for %s, got %q", expected, path, operation)
+		}
+	}
+}
+
+func TestObserveBackendWriteCountsClientErrors(t *testing.T) {
+	errors := BackendRequestErrors.WithLabelValues(ServiceSolr, "test-update")
+	for _, status := range []int{http.StatusOK, http.StatusNoContent, http.StatusBadRequest, http.StatusConflict, http.StatusInternalServerError} {
+		ObserveBackendWrite(ServiceSolr, "test-update", time.Now(), status, nil)
+	}
+	if count := testutil.ToFloat64(errors); count != 3 {
+		t.Fatalf("expected 3 failed writes, got %v", count)
+	}
+}
@@ -1,6 +1,7 @@
 package profilesync
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -9,6 +10,8 @@ import (
 	"os"
 	"path"
 	"rdf-store-backend/base"
+	"rdf-store-backend/httpclient"
+	"rdf-store-backend/metrics"
 	"rdf-store-backend/rdf"
 	"rdf-store-backend/search"
 	"rdf-store-backend/shacl"
@@ -27,35 +30,28 @@ type MPSSearchResultItem struct {
 
 var findBaseRegex = regexp.MustCompile(`@base <(.*)>`)
 var lock sync.Mutex
+var mpsClient = httpclient.New("mps", httpclient.Timeout("MPS_TIMEOUT", 20))
 
 // Synchronize runs profile sync and triggers reindexing when changes are detected.
 func Synchronize() {
 	if lock.TryLock() {
 		defer lock.Unlock()
-		profilesAdded, changedOrDeletedProfiles, err := synchronizeProfiles()
+		ctx := context.Background()
+		profilesAdded, changedOrDeletedProfiles, err := synchronizeProfiles(ctx)
 		if err != nil {
 			slog.Error("failed syncing profiles", "error", err)
 		} else if len(changedOrDeletedProfiles) > 0 || profilesAdded {
-			_, err := rdf.ParseAllProfiles()
+			_, err := rdf.ParseAllProfiles(ctx)
 			if err != nil {
 				slog.Error("failed parsing profiles", "error", err)
 			} else {
+				search.UpdateRankedPaths()
 				for _, profileId := range changedOrDeletedProfiles {
-					resourcesToUpdate, err := rdf.FindConformingResources(profileId)
+					resourcesToUpdate, err := rdf.FindConformingResources(ctx, profileId)
 					if err != nil {
 						slog.Error("failed getting conforming resources for changed profile", "id", profileId, "error", err)
 					} else {
-						for _, resourceId := range resourcesToUpdate {
-							slog.Debug("updating metadata and search index for resource", "id", resourceId)
-							metadata, graph, err := rdf.RebuildResourceConformance(resourceId)
-							if err != nil {
-								slog.Error("failed updating resource metadata", "id", resourceId, "error", err)
-							} else {
-								if err := search.IndexResource(graph, metadata); err != nil {
-									slog.Error("failed updating search index for resource", "id", resourceId, "error", err)
-								}
-							}
-						}
+						search.ReindexResources(ctx, resourcesToUpdate)
 					}
 				}
 			}
@@ -67,7 +63,7 @@ func Synchronize() {
 
 // synchronizeProfiles fetches profiles from sources and updates datasets.
 // It returns IDs of changed or deleted profiles along with any error encountered.
-func synchronizeProfiles() (profilesAdded bool, changedOrDeletedProfiles []string, err error) {
+func synchronizeProfiles(ctx context.Context) (profilesAdded bool, changedOrDeletedProfiles []string, err error) {
 	slog.Info("syncing profiles...")
 	start := time.Now()
 
@@ -104,12 +100,14 @@ func synchronizeProfiles() (profilesAdded bool, changedOrDeletedProfiles []strin
 
 	// load profiles from NFDI4Ing metadata profile service
 	if base.MPSEnabled {
+		var req *http.Request
 		var resp *http.Response
 		slog.Debug("loading remote profiles", "endpoint", base.MPSUrl)
-		client := http.Client{
-			Timeout: 20 * time.Second,
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, base.MPSUrl, nil)
+		if err != nil {
+			return
 		}
-		resp, err = client.Get(base.MPSUrl)
+		resp, err = httpclient.Do(mpsClient, req, true)
 		if err != nil {
 			return
 		}
@@ -150,20 +148,20 @@ func synchronizeProfiles() (profilesAdded bool, changedOrDeletedProfiles []strin
 		profileData := []byte(profile.Definition)
 		profileData = base.FixBooleansInRDF(profileData)
 		inputHash := base.Hash(profileData)
-		existingHash, hashErr := rdf.GetProfileHash(profile.BaseUrl)
+		existingHash, hashErr := rdf.GetProfileHash(ctx, profile.BaseUrl)
 		if hashErr != nil {
 			slog.Warn("failed retrieving hash for profile", "id", profile.BaseUrl)
 		} else {
 			if existingHash == nil {
 				// no hash -> new profile, so store it
-				graph, err := rdf.UpdateProfile(profile.BaseUrl, profileData)
+				graph, err := rdf.UpdateProfile(ctx, profile.BaseUrl, profileData)
 				if err != nil {
 					return false, nil, err
 				}
 				newProfiles[profile.BaseUrl] = graph
 			} else if inputHash != *existingHash {
 				// hash changed -> profile changed, so update it
-				graph, err := rdf.UpdateProfile(profile.BaseUrl, profileData)
+				graph, err := rdf.UpdateProfile(ctx, profile.BaseUrl, profileData)
 				if err != nil {
 					return false, nil, err
 				}
@@ -173,14 +171,14 @@ func synchronizeProfiles() (profilesAdded bool, changedOrDeletedProfiles []strin
 	}
 
 	// second pass: delete profiles that do not exist anymore
-	existingProfileIds, err := rdf.GetAllProfileIds()
+	existingProfileIds, err := rdf.GetAllProfileIds(ctx)
 	if err != nil {
 		slog.Error("failed loading profile IDs", "error", err)
 	} else {
 		for _, existingProfileId := range existingProfileIds {
 			if _, ok := profiles[existingProfileId]; !ok {
 				slog.Info("deleting existing profile", "id", existingProfileId)
-				if err := rdf.DeleteProfile(existingProfileId); err != nil {
+				if err := rdf.DeleteProfile(ctx, existingProfileId); err != nil {
 					slog.Error("failed deleting existing profile", "id", existingProfileId, "error", err)
 				} else {
 					deletedProfiles[existingProfileId] = true
@@ -191,12 +189,12 @@ func synchronizeProfiles() (profilesAdded bool, changedOrDeletedProfiles []strin
 
 	// third pass: extract labels from owl:imports of changed or new profiles
 	for _, graph := range changedProfiles {
-		extractLabelsFromOwlImports(graph, profiles)
+		extractLabelsFromOwlImports(ctx, graph, profiles)
 	}
 	if len(newProfiles) > 0 {
 		profilesAdded = true
 		for _, graph := range newProfiles {
-			extractLabelsFromOwlImports(graph, profiles)
+			extractLabelsFromOwlImports(ctx, graph, profiles)
 		}
 	}
 	changedOrDeletedProfiles = make([]string, 0)
@@ -207,25 +205,28 @@ func synchronizeProfiles() (profilesAdded bool, changedOrDeletedProfiles []strin
 		changedOrDeletedProfiles = append(changedOrDeletedProfiles, id)
 	}
 
+	metrics.ProfileSyncChanges.WithLabelValues("new").Add(float64(len(newProfiles)))
+	metrics.ProfileSyncChanges.WithLabelValues("changed").Add(float64(len(changedProfiles)))
+	metrics.ProfileSyncChanges.WithLabelValues("deleted").Add(float64(len(deletedProfiles)))
 	slog.Info("syncing profiles finished", "profiles", len(profiles), "#new", len(newProfiles), "#changed", len(changedProfiles), "#deleted", len(deletedProfiles), "duration", time.Since(start))
 	return
 }
 
 // extractLabelsFromOwlImports recursively imports labels from owl:imports.
-func extractLabelsFromOwlImports(graph *rdf2go.Graph, profileIds map[string]MPSSearchResultItem) {
+func extractLabelsFromOwlImports(ctx context.Context, graph *rdf2go.Graph, profileIds map[string]MPSSearchResultItem) {
 	for _, importsStatement := range graph.All(nil, shacl.OWL_IMPORTS, nil) {
 		url := importsStatement.Object.RawValue()
 		// ignore owl:imports that reference profiles
 		if _, ok := profileIds[url]; !ok {
 			// load owl:imports only once
-			if exist, err := rdf.CheckLabelsExist(url); err == nil && !exist {
+			if exist, err := rdf.CheckLabelsExist(ctx, url); err == nil && !exist {
 				slog.Debug("loading owl:imports", "url", url)
-				graph, err := rdf.ImportLabelsFromUrl(url)
+				graph, err := rdf.ImportLabelsFromUrl(ctx, url)
 				if err != nil {
 					slog.Debug("failed loading owl:imports", "url", url, "error", err)
 				} else {
 					// recurse
-					extractLabelsFromOwlImports(graph, profileIds)
+					extractLabelsFromOwlImports(ctx, graph, profileIds)
 				}
 			}
 		}
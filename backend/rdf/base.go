@@ -2,6 +2,7 @@ package rdf
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
@@ -32,20 +33,21 @@ var prefixQualifiedPropertyLabels = base.EnvVarAsBool("PREFIX_QUALIFIED_PROPERTY
 
 // init prepares datasets and imports local resources and labels.
 func init() {
-	if err := initDatasets(); err != nil {
+	ctx := context.Background()
+	if err := initDatasets(ctx); err != nil {
 		log.Fatal("failed initializing datasets", err)
 	}
-	if err := importLabelsFromStandardTaxonomies(); err != nil {
+	if err := importLabelsFromStandardTaxonomies(ctx); err != nil {
 		slog.Error("failed importing standard taxonomies", "error", err)
 	}
 }
 
 // initDatasets ensures required Fuseki datasets exist.
 // It returns an error when dataset creation or checks fail.
-func initDatasets() error {
-	for _, dataset := range []string{ResourceDataset, resourceMetaDataset, profileDataset, labelDataset} {
+func initDatasets(ctx context.Context) error {
+	for _, dataset := range []string{ResourceDataset, resourceMetaDataset, profileDataset, labelDataset, savedSearchDataset} {
 		// check if dataset exists
-		req, err := http.NewRequest("GET", fmt.Sprintf("%s/$/stats/%s", FusekiEndpoint, dataset), nil)
+		req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/$/stats/%s", FusekiEndpoint, dataset), nil)
 		if err != nil {
 			return err
 		}
@@ -56,7 +58,7 @@ func initDatasets() error {
 		}
 		if status != http.StatusOK {
 			// dataset does not exist, so create it
-			req, err = http.NewRequest(http.MethodPost, fmt.Sprintf("%s/$/datasets?dbName=%s&dbType=TDB2", FusekiEndpoint, dataset), nil)
+			req, err = http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/$/datasets?dbName=%s&dbType=TDB2", FusekiEndpoint, dataset), nil)
 			if err != nil {
 				return err
 			}
@@ -74,23 +76,41 @@ func initDatasets() error {
 	return nil
 }
 
+// Ping checks that Fuseki is reachable and answers the server statistics endpoint.
+// It returns an error when the request fails or Fuseki responds with a non-2xx status.
+func Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/$/stats", FusekiEndpoint), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", AuthHeader)
+	status, body, err := doRequest(req)
+	if err != nil {
+		return err
+	}
+	if !statusIsOK(status) {
+		return newHTTPError("failed reading fuseki stats", status, body)
+	}
+	return nil
+}
+
 // createGraph creates a new named graph in the target dataset.
 // It returns an error if the graph already exists or upload fails.
-func createGraph(dataset string, id string, data []byte) error {
-	exists, err := checkGraphExists(dataset, id)
+func createGraph(ctx context.Context, dataset string, id string, data []byte) error {
+	exists, err := checkGraphExists(ctx, dataset, id)
 	if err != nil {
 		return err
 	}
 	if exists {
 		return fmt.Errorf("graph %s already exists in dataset %s", id, dataset)
 	}
-	return uploadGraph(dataset, id, data, nil)
+	return uploadGraph(ctx, dataset, id, data, nil)
 }
 
 // loadGraph fetches a graph's triples from a dataset.
 // It returns the serialized graph bytes and any error encountered.
-func loadGraph(dataset string, id string) (data []byte, err error) {
-	exists, err := checkGraphExists(dataset, id)
+func loadGraph(ctx context.Context, dataset string, id string) (data []byte, err error) {
+	exists, err := checkGraphExists(ctx, dataset, id)
 	if err != nil {
 		return nil, err
 	}
@@ -98,13 +118,13 @@ func loadGraph(dataset string, id string) (data []byte, err error) {
 		slog.Info("graph not found", "id", id, "dataset", dataset)
 		return nil, ErrNotFound
 	}
-	return queryDataset(dataset, fmt.Sprintf(`CONSTRUCT { ?s ?p ?o } WHERE { GRAPH <%s> { ?s ?p ?o } }`, id))
+	return queryDataset(ctx, dataset, fmt.Sprintf(`CONSTRUCT { ?s ?p ?o } WHERE { GRAPH <%s> { ?s ?p ?o } }`, id))
 }
 
 // uploadGraph replaces a named graph and optionally extracts labels.
 // It returns an error if the upload or label extraction fails.
-func uploadGraph(dataset string, id string, data []byte, graph *rdf2go.Graph) (err error) {
-	if err = deleteGraph(dataset, id); err != nil {
+func uploadGraph(ctx context.Context, dataset string, id string, data []byte, graph *rdf2go.Graph) (err error) {
+	if err = deleteGraph(ctx, dataset, id); err != nil {
 		return
 	}
 	body := new(bytes.Buffer)
@@ -119,7 +139,7 @@ func uploadGraph(dataset string, id string, data []byte, graph *rdf2go.Graph) (e
 	part.Write(data)
 	writer.Close()
 
-	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/%s/data?graph=%s", FusekiEndpoint, dataset, id), body)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/%s/data?graph=%s", FusekiEndpoint, dataset, id), body)
 	if err != nil {
 		return err
 	}
@@ -142,7 +162,7 @@ func uploadGraph(dataset string, id string, data []byte, graph *rdf2go.Graph) (e
 			}
 		}
 		if err == nil {
-			if err := ExtractLabels(id, graph, dataset == profileDataset); err != nil {
+			if err := ExtractLabels(ctx, id, graph, dataset == profileDataset); err != nil {
 				slog.Error("failed extracting labels.", "id", id, "error", err)
 			}
 		}
@@ -152,14 +172,14 @@ func uploadGraph(dataset string, id string, data []byte, graph *rdf2go.Graph) (e
 
 // deleteGraph removes a named graph, associated labels and resource metadata.
 // It returns an error if the deletion fails.
-func deleteGraph(dataset string, id string) (err error) {
-	err = updateDataset(dataset, fmt.Sprintf(`DROP GRAPH <%s>`, id))
+func deleteGraph(ctx context.Context, dataset string, id string) (err error) {
+	err = updateDataset(ctx, dataset, fmt.Sprintf(`DROP GRAPH <%s>`, id))
 	if err != nil {
 		return
 	}
 	// delete labels that were sourced from the deleted graph
 	if dataset == profileDataset || dataset == ResourceDataset {
-		if err = deleteGraph(labelDataset, id); err != nil {
+		if err = deleteGraph(ctx, labelDataset, id); err != nil {
 			slog.Warn("failed deleting labels extracted from", "id", id)
 		}
 	}
@@ -168,7 +188,7 @@ func deleteGraph(dataset string, id string) (err error) {
 
 // checkGraphExists asks the dataset whether a named graph exists.
 // It returns a boolean flag and any error encountered.
-func checkGraphExists(dataset string, id string) (exists bool, err error) {
+func checkGraphExists(ctx context.Context, dataset string, id string) (exists bool, err error) {
 	// prevent SPARQL injection
 	if !isValidIRI(id) {
 		return false, fmt.Errorf("invalid id IRI: %v", id)
@@ -176,7 +196,7 @@ func checkGraphExists(dataset string, id string) (exists bool, err error) {
 	exists = false
 	body := url.Values{}
 	body.Set("query", fmt.Sprintf("ASK WHERE { GRAPH <%s> { ?s ?p ?o } }", id))
-	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/%s", FusekiEndpoint, dataset), strings.NewReader(body.Encode()))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/%s", FusekiEndpoint, dataset), strings.NewReader(body.Encode()))
 	if err != nil {
 		return
 	}
@@ -204,8 +224,8 @@ func checkGraphExists(dataset string, id string) (exists bool, err error) {
 
 // getAllGraphIds lists graph identifiers in a dataset.
 // It returns the slice of graph IDs and any error encountered.
-func getAllGraphIds(dataset string) ([]string, error) {
-	bindings, err := queryDataset(dataset, "SELECT DISTINCT ?g WHERE { GRAPH ?g { } }")
+func getAllGraphIds(ctx context.Context, dataset string) ([]string, error) {
+	bindings, err := queryDataset(ctx, dataset, "SELECT DISTINCT ?g WHERE { GRAPH ?g { } }")
 	if err != nil {
 		return nil, err
 	}
@@ -226,10 +246,10 @@ func getAllGraphIds(dataset string) ([]string, error) {
 
 // queryDataset executes a SPARQL query and returns the JSON response bytes.
 // It returns the raw response data and any error encountered.
-func queryDataset(dataset string, query string) (data []byte, err error) {
+func queryDataset(ctx context.Context, dataset string, query string) (data []byte, err error) {
 	body := url.Values{}
 	body.Set("query", query)
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s/%s", FusekiEndpoint, dataset), strings.NewReader(body.Encode()))
+	req, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s/%s", FusekiEndpoint, dataset), strings.NewReader(body.Encode()))
 	if err != nil {
 		return
 	}
@@ -249,10 +269,10 @@ func queryDataset(dataset string, query string) (data []byte, err error) {
 
 // updateDataset executes a SPARQL update query.
 // It returns an error if the update request fails.
-func updateDataset(dataset string, query string) (err error) {
+func updateDataset(ctx context.Context, dataset string, query string) (err error) {
 	form := url.Values{}
 	form.Set("update", query)
-	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/%s/update", FusekiEndpoint, dataset), strings.NewReader(form.Encode()))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/%s/update", FusekiEndpoint, dataset), strings.NewReader(form.Encode()))
 	if err != nil {
 		return err
 	}
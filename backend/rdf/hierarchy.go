@@ -0,0 +1,78 @@
+package rdf
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"rdf-store-backend/shacl"
+	"text/template"
+
+	"github.com/deiu/rdf2go"
+	"github.com/knakk/rdf"
+	"github.com/knakk/sparql"
+)
+
+// broaderQuery finds the skos:broader relations of concepts and all their
+// ancestors across the imported taxonomies.
+var broaderQuery = `
+SELECT DISTINCT ?narrower ?broader
+WHERE {
+  GRAPH <urn:x-arq:UnionGraph> {
+	VALUES ?concept { {{range .Ids}}{{.}} {{end}} }
+	?concept <{{.Broader}}>* ?narrower .
+	?narrower <{{.Broader}}> ?broader .
+  }
+}
+`
+var broaderQueryTemplate = template.Must(template.New("broaderQuery").Parse(broaderQuery))
+
+// writeBroader keeps the concept hierarchy of taxonomies besides their labels.
+// skos:narrower relations are stored as the inverse skos:broader relation.
+// It reports whether the triple is a hierarchy relation.
+func writeBroader(triple *rdf2go.Triple, result *bytes.Buffer) bool {
+	narrower, broader := triple.Subject, triple.Object
+	if triple.Predicate.Equal(shacl.SKOS_NARROWER) {
+		narrower, broader = broader, narrower
+	} else if !triple.Predicate.Equal(shacl.SKOS_BROADER) {
+		return false
+	}
+	_, narrowerIRI := narrower.(*rdf2go.Resource)
+	_, broaderIRI := broader.(*rdf2go.Resource)
+	if narrowerIRI && broaderIRI {
+		fmt.Fprintf(result, "%s %s %s .\n", narrower.String(), shacl.SKOS_BROADER.String(), broader.String())
+	}
+	return true
+}
+
+// GetBroaderConcepts retrieves the skos:broader relations of concepts and of
+// all their ancestors. IDs are IRIs in angle brackets.
+// It returns a map of concept to its direct broader concepts, both in angle
+// brackets, and any error encountered.
+func GetBroaderConcepts(ctx context.Context, ids []string) (map[string][]string, error) {
+	result := make(map[string][]string)
+	if len(ids) == 0 {
+		return result, nil
+	}
+	var query bytes.Buffer
+	if err := broaderQueryTemplate.Execute(&query, map[string]any{"Ids": ids, "Broader": shacl.SKOS_BROADER.RawValue()}); err != nil {
+		return nil, err
+	}
+	bindings, err := queryDataset(ctx, labelDataset, query.String())
+	if err != nil {
+		return nil, err
+	}
+	res, err := sparql.ParseJSON(bytes.NewReader(bindings))
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range res.Solutions() {
+		narrower, okN := row["narrower"].(rdf.IRI)
+		broader, okB := row["broader"].(rdf.IRI)
+		if !okN || !okB {
+			return nil, fmt.Errorf("invalid binding: %v", row)
+		}
+		id := "<" + narrower.String() + ">"
+		result[id] = append(result[id], "<"+broader.String()+">")
+	}
+	return result, nil
+}
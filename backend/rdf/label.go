@@ -2,6 +2,7 @@ package rdf
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -77,7 +78,7 @@ func FindLabels(subject rdf2go.Term, graph *rdf2go.Graph) []string {
 
 // GetLabels retrieves preferred labels for IDs in the given language.
 // It returns a map of ID to label and any error encountered.
-func GetLabels(language string, ids []string) (map[string]string, error) {
+func GetLabels(ctx context.Context, language string, ids []string) (map[string]string, error) {
 	result := make(map[string]string)
 	if len(ids) > 0 {
 		languagePriorities := preferredLanguagePriorities(language)
@@ -88,7 +89,7 @@ func GetLabels(language string, ids []string) (map[string]string, error) {
 			return nil, err
 		}
 
-		bindings, err := queryDataset(labelDataset, query.String())
+		bindings, err := queryDataset(ctx, labelDataset, query.String())
 		if err != nil {
 			return nil, err
 		}
@@ -128,8 +129,8 @@ func GetLabels(language string, ids []string) (map[string]string, error) {
 
 // GetDefaultLabels retrieves preferred labels using the configured primary
 // label language and the standard fallback order.
-func GetDefaultLabels(ids []string) (map[string]string, error) {
-	return GetLabels(defaultLabelLanguage(), ids)
+func GetDefaultLabels(ctx context.Context, ids []string) (map[string]string, error) {
+	return GetLabels(ctx, defaultLabelLanguage(), ids)
 }
 
 type labelCandidate struct {
@@ -206,18 +207,18 @@ func defaultLabelLanguage() string {
 
 // CheckLabelsExist checks whether labels for a URL were already imported.
 // It returns a boolean flag and any error from the dataset lookup.
-func CheckLabelsExist(url string) (bool, error) {
-	return checkGraphExists(labelDataset, url)
+func CheckLabelsExist(ctx context.Context, url string) (bool, error) {
+	return checkGraphExists(ctx, labelDataset, url)
 }
 
 // ExtractLabels stores label triples and optional SHACL-derived labels.
 // It returns an error if label extraction or upload fails.
-func ExtractLabels(id string, graph *rdf2go.Graph, convertShaclProperties bool) error {
+func ExtractLabels(ctx context.Context, id string, graph *rdf2go.Graph, convertShaclProperties bool) error {
 	labels := serializeLabels(id, graph, convertShaclProperties)
 	if len(labels) == 0 {
 		return nil
 	}
-	return uploadGraph(labelDataset, id, labels, nil)
+	return uploadGraph(ctx, labelDataset, id, labels, nil)
 }
 
 func serializeLabels(id string, graph *rdf2go.Graph, convertShaclProperties bool) []byte {
@@ -227,6 +228,9 @@ func serializeLabels(id string, graph *rdf2go.Graph, convertShaclProperties bool
 		profileLabels = findProfileLabels(rdf2go.NewResource(id), graph)
 	}
 	for triple := range graph.IterTriples() {
+		if writeBroader(triple, &result) {
+			continue
+		}
 		if _, isLabel := LabelPredicates[triple.Predicate.RawValue()]; isLabel {
 			// check if triple object is a literal
 			if label, ok := triple.Object.(*rdf2go.Literal); ok {
@@ -329,11 +333,11 @@ func writeCombinedPersonLabels(graph *rdf2go.Graph, result *bytes.Buffer) {
 
 // ImportLabelsFromUrl loads an RDF graph from a URL and extracts labels.
 // It returns the parsed graph and any error encountered.
-func ImportLabelsFromUrl(url string) (*rdf2go.Graph, error) {
+func ImportLabelsFromUrl(ctx context.Context, url string) (*rdf2go.Graph, error) {
 	slog.Info("importing labels from", "url", url)
 	header := http.Header{}
 	header["Accept"] = []string{"text/turtle"}
-	data, err := base.CacheLoad(url, "text/turtle")
+	data, err := base.CacheLoad(ctx, url, "text/turtle")
 	if err != nil {
 		return nil, err
 	}
@@ -341,7 +345,7 @@ func ImportLabelsFromUrl(url string) (*rdf2go.Graph, error) {
 	if err != nil {
 		return nil, err
 	}
-	if err = ExtractLabels(url, graph, false); err != nil {
+	if err = ExtractLabels(ctx, url, graph, false); err != nil {
 		return nil, err
 	}
 	return graph, nil
@@ -375,14 +379,15 @@ func findProfileLabels(id rdf2go.Term, graph *rdf2go.Graph) map[string]string {
 	return labels
 }
 
-// importLabelsFromStandardTaxonomies loads labels from configured taxonomies.
+// importLabelsFromStandardTaxonomies loads labels and concept hierarchies from
+// configured taxonomies.
 // It returns an error if any taxonomy import fails.
-func importLabelsFromStandardTaxonomies() error {
+func importLabelsFromStandardTaxonomies(ctx context.Context) error {
 	for _, url := range base.RdfStandardTaxonomies {
 		url = strings.TrimSpace(url)
 		if url != "" {
-			if exist, err := CheckLabelsExist(url); err == nil && !exist {
-				if _, err := ImportLabelsFromUrl(url); err != nil {
+			if exist, err := CheckLabelsExist(ctx, url); err == nil && !exist {
+				if _, err := ImportLabelsFromUrl(ctx, url); err != nil {
 					slog.Warn("failed importing labels from standard taxonomy", "url", url)
 				}
 			}
@@ -172,3 +172,28 @@ ex:resource skos:prefLabel "Preferred"@en ;
 		t.Errorf("expected the unsupported French label to be ignored, got:\n%s", output)
 	}
 }
+
+func TestSerializeLabelsKeepsConceptHierarchy(t *testing.T) {
+	graph := rdf2go.NewGraph("")
+	data := `
+@prefix skos: <http://www.w3.org/2004/02/skos/core#> .
+@prefix ex: <http://example.org/> .
+ex:metal skos:prefLabel "Metal"@en ; skos:narrower ex:steel .
+ex:alloy skos:broader ex:material, "not a concept" .
+`
+	if err := graph.Parse(strings.NewReader(data), "text/turtle"); err != nil {
+		t.Fatal(err)
+	}
+	output := string(serializeLabels("http://example.org/graph", graph, false))
+	for _, expected := range []string{
+		`<http://example.org/steel> <http://www.w3.org/2004/02/skos/core#broader> <http://example.org/metal> .`,
+		`<http://example.org/alloy> <http://www.w3.org/2004/02/skos/core#broader> <http://example.org/material> .`,
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("expected %s, got:\n%s", expected, output)
+		}
+	}
+	if strings.Contains(output, "not a concept") || strings.Contains(output, "narrower") {
+		t.Errorf("expected only broader relations between concepts, got:\n%s", output)
+	}
+}
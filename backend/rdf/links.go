@@ -2,6 +2,7 @@ package rdf
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"sort"
@@ -10,14 +11,16 @@ import (
 	"github.com/knakk/rdf"
 )
 
-func resolveLinks(graph *rdf2go.Graph, resource []byte) ([]byte, []string, error) {
+func resolveLinks(ctx context.Context, graph *rdf2go.Graph, resource []byte) ([]byte, []string, error) {
 	var linkedResources []string
 	visited := make(map[string]struct{})
 
 	var walkLink func(string) error
 	var walkLocalCandidates func(map[string]struct{}) error
 	walkLocalCandidates = func(candidates map[string]struct{}) error {
-		return walkLocalLinks(candidates, visited, GetLocalSubjects, walkLink)
+		return walkLocalLinks(candidates, visited, func(ids []string) ([]string, error) {
+			return GetLocalSubjects(ctx, ids)
+		}, walkLink)
 	}
 	walkLink = func(link string) error {
 		if _, seen := visited[link]; seen {
@@ -25,7 +28,7 @@ func resolveLinks(graph *rdf2go.Graph, resource []byte) ([]byte, []string, error
 		}
 		visited[link] = struct{}{}
 
-		bindings, err := queryDataset(ResourceDataset, fmt.Sprintf(`SELECT ?s ?p ?o ?g WHERE { GRAPH ?g { <%s> (<>|!<>)* ?s . GRAPH ?g { ?s ?p ?o } } }`, link))
+		bindings, err := queryDataset(ctx, ResourceDataset, fmt.Sprintf(`SELECT ?s ?p ?o ?g WHERE { GRAPH ?g { <%s> (<>|!<>)* ?s . GRAPH ?g { ?s ?p ?o } } }`, link))
 		if err != nil {
 			return err
 		}
@@ -2,6 +2,7 @@ package rdf
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"sort"
@@ -32,12 +33,12 @@ type GraphNeighborhood struct {
 
 // GetLocalSubjects returns the candidates that can be resolved from the local
 // resource dataset, either as a described subject or as a stored named graph.
-func GetLocalSubjects(ids []string) ([]string, error) {
+func GetLocalSubjects(ctx context.Context, ids []string) ([]string, error) {
 	query, err := buildLocalSubjectsQuery(ids)
 	if err != nil || query == "" {
 		return []string{}, err
 	}
-	bindings, err := queryDataset(ResourceDataset, query)
+	bindings, err := queryDataset(ctx, ResourceDataset, query)
 	if err != nil {
 		return nil, err
 	}
@@ -90,13 +91,13 @@ func parseLocalSubjects(bindings []byte) ([]string, error) {
 // GetGraphNeighborhood returns one deterministic page of direct RDF
 // statements and identifies the named subjects in that page that are locally
 // available for further traversal.
-func GetGraphNeighborhood(subject, direction string, offset, limit int) (*GraphNeighborhood, error) {
+func GetGraphNeighborhood(ctx context.Context, subject, direction string, offset, limit int) (*GraphNeighborhood, error) {
 	query, err := buildNeighborhoodQuery(subject, direction, offset, limit)
 	if err != nil {
 		return nil, err
 	}
 
-	bindings, err := queryDataset(ResourceDataset, query)
+	bindings, err := queryDataset(ctx, ResourceDataset, query)
 	if err != nil {
 		return nil, err
 	}
@@ -104,7 +105,7 @@ func GetGraphNeighborhood(subject, direction string, offset, limit int) (*GraphN
 	if err != nil {
 		return nil, err
 	}
-	localSubjects, err := GetLocalSubjects(adjacent)
+	localSubjects, err := GetLocalSubjects(ctx, adjacent)
 	if err != nil {
 		return nil, err
 	}
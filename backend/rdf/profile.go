@@ -2,6 +2,7 @@ package rdf
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"rdf-store-backend/base"
 	"rdf-store-backend/shacl"
@@ -20,8 +21,8 @@ var Profiles map[string]*shacl.NodeShape
 
 // ParseAllProfiles loads all profiles, parses shapes, and atomically replaces the cache.
 // It returns the parsed profile map and any error encountered.
-func ParseAllProfiles() (map[string]*shacl.NodeShape, error) {
-	profileIDs, err := GetAllProfileIds()
+func ParseAllProfiles(ctx context.Context) (map[string]*shacl.NodeShape, error) {
+	profileIDs, err := GetAllProfileIds(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -29,7 +30,7 @@ func ParseAllProfiles() (map[string]*shacl.NodeShape, error) {
 	profiles := make(map[string]*shacl.NodeShape, len(profileIDs))
 	configuredProfileIDs := append([]string(nil), profileIDs...)
 	for _, profileID := range profileIDs {
-		profile, err := GetProfile(profileID)
+		profile, err := GetProfile(ctx, profileID)
 		if err != nil {
 			return nil, err
 		}
@@ -74,8 +75,8 @@ func ParseAllProfiles() (map[string]*shacl.NodeShape, error) {
 
 // GetProfile loads a profile graph from the profile dataset storage.
 // It returns the serialized profile bytes and any error encountered.
-func GetProfile(id string) (profile []byte, err error) {
-	return loadGraph(profileDataset, id)
+func GetProfile(ctx context.Context, id string) (profile []byte, err error) {
+	return loadGraph(ctx, profileDataset, id)
 }
 
 // GetProfileClosure serializes a cached profile together with all locally
@@ -91,7 +92,7 @@ func GetProfileClosure(id string) ([]byte, error) {
 
 // UpdateProfile stores a profile after replacing blank nodes and calculating a hash.
 // It returns the parsed graph representation alongside any error.
-func UpdateProfile(id string, profile []byte) (*rdf2go.Graph, error) {
+func UpdateProfile(ctx context.Context, id string, profile []byte) (*rdf2go.Graph, error) {
 	graph, err := replaceBlankNodes(profile)
 	if err != nil {
 		return nil, err
@@ -100,16 +101,16 @@ func UpdateProfile(id string, profile []byte) (*rdf2go.Graph, error) {
 	if err = graph.Serialize(&buf, "text/turtle"); err != nil {
 		return nil, err
 	}
-	if err := deleteProfileHash(id); err != nil {
+	if err := deleteProfileHash(ctx, id); err != nil {
 		return nil, err
 	}
 	// build hash on original/unmodified profile
 	hash := base.Hash(profile)
 	// store profile with blank nodes replaced by proper IDs
-	if err := uploadGraph(profileDataset, id, buf.Bytes(), graph); err != nil {
+	if err := uploadGraph(ctx, profileDataset, id, buf.Bytes(), graph); err != nil {
 		return nil, err
 	}
-	if err = saveProfileHash(id, hash); err != nil {
+	if err = saveProfileHash(ctx, id, hash); err != nil {
 		return nil, err
 	}
 	return graph, nil
@@ -117,23 +118,23 @@ func UpdateProfile(id string, profile []byte) (*rdf2go.Graph, error) {
 
 // DeleteProfile removes a profile graph and its hash metadata from storage.
 // It returns an error if either deletion fails.
-func DeleteProfile(id string) error {
-	if err := deleteProfileHash(id); err != nil {
+func DeleteProfile(ctx context.Context, id string) error {
+	if err := deleteProfileHash(ctx, id); err != nil {
 		return err
 	}
-	return deleteGraph(profileDataset, id)
+	return deleteGraph(ctx, profileDataset, id)
 }
 
 // GetAllProfileIds lists all profile graph IDs in the dataset.
 // It returns the slice of profile IDs and any error encountered.
-func GetAllProfileIds() ([]string, error) {
-	return getAllGraphIds(profileDataset)
+func GetAllProfileIds(ctx context.Context) ([]string, error) {
+	return getAllGraphIds(ctx, profileDataset)
 }
 
 // GetProfileHash reads the stored hash for a profile when available.
 // It returns a pointer to the hash or nil when missing, plus any error.
-func GetProfileHash(id string) (*uint32, error) {
-	bindings, err := queryDataset(profileDataset, fmt.Sprintf("SELECT ?hash WHERE { <%s> %s ?hash }", id, hashPredicate))
+func GetProfileHash(ctx context.Context, id string) (*uint32, error) {
+	bindings, err := queryDataset(ctx, profileDataset, fmt.Sprintf("SELECT ?hash WHERE { <%s> %s ?hash }", id, hashPredicate))
 	if err != nil {
 		return nil, err
 	}
@@ -157,14 +158,14 @@ func GetProfileHash(id string) (*uint32, error) {
 
 // saveProfileHash persists the hash value for a profile in the dataset.
 // It returns an error if the SPARQL update fails.
-func saveProfileHash(id string, hash uint32) error {
-	return updateDataset(profileDataset, fmt.Sprintf("INSERT DATA { <%s> %s %d . }", id, hashPredicate, hash))
+func saveProfileHash(ctx context.Context, id string, hash uint32) error {
+	return updateDataset(ctx, profileDataset, fmt.Sprintf("INSERT DATA { <%s> %s %d . }", id, hashPredicate, hash))
 }
 
 // deleteProfileHash removes the stored hash for a profile from the dataset.
 // It returns an error if the SPARQL update fails.
-func deleteProfileHash(id string) error {
-	return updateDataset(profileDataset, fmt.Sprintf(`DELETE WHERE { <%s> %s ?hash . }`, id, hashPredicate))
+func deleteProfileHash(ctx context.Context, id string) error {
+	return updateDataset(ctx, profileDataset, fmt.Sprintf(`DELETE WHERE { <%s> %s ?hash . }`, id, hashPredicate))
 }
 
 // replaceBlankNodes substitutes blank nodes with stable resource identifiers for downstream lookups.
@@ -2,6 +2,7 @@ package rdf
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -19,12 +20,12 @@ var ErrResourceLinked = errors.New("resource is linked by other resources")
 
 // GetResource fetches an RDF resource graph with optional linked graph expansion.
 // It returns the resource bytes, metadata, and any error encountered.
-func GetResource(id string, includeLinked bool) (resource []byte, metadata *ResourceMetadata, err error) {
-	resource, err = loadGraph(ResourceDataset, id)
+func GetResource(ctx context.Context, id string, includeLinked bool) (resource []byte, metadata *ResourceMetadata, err error) {
+	resource, err = loadGraph(ctx, ResourceDataset, id)
 	if err != nil {
 		return
 	}
-	metadata, err = loadResourceMetadata(id)
+	metadata, err = loadResourceMetadata(ctx, id)
 	if err != nil {
 		return
 	}
@@ -34,20 +35,22 @@ func GetResource(id string, includeLinked bool) (resource []byte, metadata *Reso
 			err = innerErr
 			return
 		}
-		resource, _, err = resolveLinks(graph, resource)
+		resource, _, err = resolveLinks(ctx, graph, resource)
 	}
 	return
 }
 
 // CreateResource stores a new resource graph and updates its metadata record.
 // It returns the parsed graph, metadata, and any error encountered.
-func CreateResource(resource []byte, creator string) (graph *rdf2go.Graph, metadata *ResourceMetadata, err error) {
-	metadata, graph, err = createResourceMetadata(resource, creator)
+func CreateResource(ctx context.Context, resource []byte, creator string) (graph *rdf2go.Graph, metadata *ResourceMetadata, err error) {
+	metadata, graph, err = createResourceMetadata(ctx, resource, creator)
 	if err != nil {
 		return
 	}
-	if err = createGraph(ResourceDataset, metadata.Id.RawValue(), resource); err != nil {
-		deleteResourceMetadata(metadata.Id.RawValue())
+	// the metadata is stored, so complete or roll back the write even if the request is canceled
+	ctx = context.WithoutCancel(ctx)
+	if err = createGraph(ctx, ResourceDataset, metadata.Id.RawValue(), resource); err != nil {
+		deleteResourceMetadata(ctx, metadata.Id.RawValue())
 		return
 	}
 	return
@@ -55,27 +58,28 @@ func CreateResource(resource []byte, creator string) (graph *rdf2go.Graph, metad
 
 // UpdateResource validates permissions, updates the graph, and refreshes metadata.
 // It returns the updated graph, metadata, and any error encountered.
-func UpdateResource(id string, resource []byte, creator string) (graph *rdf2go.Graph, metadata *ResourceMetadata, err error) {
-	if err = validateCreator(id, creator); err != nil {
+func UpdateResource(ctx context.Context, id string, resource []byte, creator string) (graph *rdf2go.Graph, metadata *ResourceMetadata, err error) {
+	if err = validateCreator(ctx, id, creator); err != nil {
 		return
 	}
-	metadata, graph, err = updateResourceMetadata(rdf2go.NewResource(id), resource, false)
+	metadata, graph, err = updateResourceMetadata(ctx, rdf2go.NewResource(id), resource, false)
 	if err != nil {
 		return
 	}
-	if err = uploadGraph(ResourceDataset, id, resource, nil); err != nil {
-		deleteResourceMetadata(id)
+	ctx = context.WithoutCancel(ctx)
+	if err = uploadGraph(ctx, ResourceDataset, id, resource, nil); err != nil {
+		deleteResourceMetadata(ctx, id)
 	}
 	return
 }
 
 // DeleteResource removes a resource graph and its metadata after checking for incoming links.
 // It returns an error if the deletion fails or the resource is still linked.
-func DeleteResource(id string, creator string) error {
-	if err := validateCreator(id, creator); err != nil {
+func DeleteResource(ctx context.Context, id string, creator string) error {
+	if err := validateCreator(ctx, id, creator); err != nil {
 		return err
 	}
-	subjects, err := getGraphSubjects(id)
+	subjects, err := getGraphSubjects(ctx, id)
 	if err != nil {
 		return err
 	}
@@ -83,7 +87,7 @@ func DeleteResource(id string, creator string) error {
 		subjects = append(subjects, id)
 	}
 	for _, subject := range subjects {
-		linked, err := hasIncomingLinks(subject, id)
+		linked, err := hasIncomingLinks(ctx, subject, id)
 		if err != nil {
 			return err
 		}
@@ -91,25 +95,26 @@ func DeleteResource(id string, creator string) error {
 			return ErrResourceLinked
 		}
 	}
-	if err := deleteGraph(ResourceDataset, id); err != nil {
+	ctx = context.WithoutCancel(ctx)
+	if err := deleteGraph(ctx, ResourceDataset, id); err != nil {
 		return err
 	}
-	return deleteResourceMetadata(id)
+	return deleteResourceMetadata(ctx, id)
 }
 
 // GetAllResourceIds lists all resource graph IDs in the dataset.
 // It returns the slice of resource IDs and any error encountered.
-func GetAllResourceIds() ([]string, error) {
-	return getAllGraphIds(ResourceDataset)
+func GetAllResourceIds(ctx context.Context) ([]string, error) {
+	return getAllGraphIds(ctx, ResourceDataset)
 }
 
 // getGraphSubjects retrieves distinct subject IRIs from a resource graph.
 // It returns the subject list or an error when the ID is invalid or the query fails.
-func getGraphSubjects(id string) ([]string, error) {
+func getGraphSubjects(ctx context.Context, id string) ([]string, error) {
 	if !isValidIRI(id) {
 		return nil, fmt.Errorf("invalid id IRI: %v", id)
 	}
-	bindings, err := queryDataset(ResourceDataset, fmt.Sprintf(`SELECT DISTINCT ?s WHERE { GRAPH <%s> { ?s ?p ?o } }`, id))
+	bindings, err := queryDataset(ctx, ResourceDataset, fmt.Sprintf(`SELECT DISTINCT ?s WHERE { GRAPH <%s> { ?s ?p ?o } }`, id))
 	if err != nil {
 		return nil, err
 	}
@@ -139,14 +144,14 @@ func getGraphSubjects(id string) ([]string, error) {
 
 // hasIncomingLinks checks whether any graph links to the given subject.
 // It returns a boolean indicating linkage and an error for invalid input or query failures.
-func hasIncomingLinks(id string, excludeGraph string) (bool, error) {
+func hasIncomingLinks(ctx context.Context, id string, excludeGraph string) (bool, error) {
 	if !isValidIRI(id) {
 		return false, fmt.Errorf("invalid id IRI: %v", id)
 	}
 	if !isValidIRI(excludeGraph) {
 		return false, fmt.Errorf("invalid exclude graph IRI: %v", excludeGraph)
 	}
-	bindings, err := queryDataset(ResourceDataset, fmt.Sprintf(`ASK WHERE { GRAPH ?g { ?s ?p <%s> } FILTER (?g != <%s>) }`, id, excludeGraph))
+	bindings, err := queryDataset(ctx, ResourceDataset, fmt.Sprintf(`ASK WHERE { GRAPH ?g { ?s ?p <%s> } FILTER (?g != <%s>) }`, id, excludeGraph))
 	if err != nil {
 		return false, err
 	}
@@ -167,14 +172,14 @@ func hasIncomingLinks(id string, excludeGraph string) (bool, error) {
 
 // GetClassInstances retrieves all instances of a given RDF class across graphs.
 // It returns the instances as N-Quads bytes and any error encountered.
-func GetClassInstances(classes []string) ([]byte, error) {
+func GetClassInstances(ctx context.Context, classes []string) ([]byte, error) {
 	// prevent SPARQL injection
 	for _, class := range classes {
 		if !isValidIRI(class) {
 			return nil, fmt.Errorf("invalid class IRI: %v", class)
 		}
 	}
-	bindings, err := queryDataset(ResourceDataset, fmt.Sprintf(`SELECT DISTINCT ?s ?p ?o ?g WHERE  { GRAPH ?g { VALUES ?class { %s } ?instance a ?class . ?instance (<>|!<>)* ?s . ?s ?p ?o }}`, arrayToSparqlValues(classes)))
+	bindings, err := queryDataset(ctx, ResourceDataset, fmt.Sprintf(`SELECT DISTINCT ?s ?p ?o ?g WHERE  { GRAPH ?g { VALUES ?class { %s } ?instance a ?class . ?instance (<>|!<>)* ?s . ?s ?p ?o }}`, arrayToSparqlValues(classes)))
 	if err != nil {
 		return nil, err
 	}
@@ -183,12 +188,12 @@ func GetClassInstances(classes []string) ([]byte, error) {
 
 // ListConformingResources retrieves all instances that conform to a given SHACL shape.
 // It returns the map mapping from instance ID to its N-Quads bytes and any error encountered.
-func ListConformingResources(shape string) ([]string, error) {
+func ListConformingResources(ctx context.Context, shape string) ([]string, error) {
 	// prevent SPARQL injection
 	if !isValidIRI(shape) {
 		return nil, fmt.Errorf("invalid shape IRI: %v", shape)
 	}
-	bindings, err := queryDataset(resourceMetaDataset, fmt.Sprintf(`SELECT DISTINCT ?g WHERE { GRAPH ?g { ?g <%s> <%s> } }`, shacl.DCTERMS_CONFORMS_TO.RawValue(), shape))
+	bindings, err := queryDataset(ctx, resourceMetaDataset, fmt.Sprintf(`SELECT DISTINCT ?g WHERE { GRAPH ?g { ?g <%s> <%s> } }`, shacl.DCTERMS_CONFORMS_TO.RawValue(), shape))
 	if err != nil {
 		return nil, err
 	}
@@ -209,11 +214,11 @@ func ListConformingResources(shape string) ([]string, error) {
 
 // validateCreator ensures the requester matches stored creator metadata.
 // It returns nil when allowed or an error when the creator does not match.
-func validateCreator(id string, user string) error {
+func validateCreator(ctx context.Context, id string, user string) error {
 	if user == "" {
 		return nil
 	}
-	metadata, err := loadResourceMetadata(id)
+	metadata, err := loadResourceMetadata(ctx, id)
 	if err != nil {
 		return err
 	}
@@ -2,6 +2,7 @@ package rdf
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"rdf-store-backend/base"
@@ -31,8 +32,8 @@ type ResourceMetadata struct {
 
 // FindConformingResources returns IDs of resources that conform to a profile.
 // It returns the slice of matching resource IDs and any error encountered.
-func FindConformingResources(profileId string) ([]string, error) {
-	bindings, err := queryDataset(resourceMetaDataset, fmt.Sprintf(`SELECT ?g WHERE { GRAPH ?g { ?s <`+shacl.DCTERMS_CONFORMS_TO.RawValue()+`> <%s> } }`, profileId))
+func FindConformingResources(ctx context.Context, profileId string) ([]string, error) {
+	bindings, err := queryDataset(ctx, resourceMetaDataset, fmt.Sprintf(`SELECT ?g WHERE { GRAPH ?g { ?s <`+shacl.DCTERMS_CONFORMS_TO.RawValue()+`> <%s> } }`, profileId))
 	if err != nil {
 		return nil, err
 	}
@@ -51,14 +52,43 @@ func FindConformingResources(profileId string) ([]string, error) {
 	return conformingResources, nil
 }
 
+// FindResourcesModifiedSince returns IDs of resources whose last modification
+// timestamp is at or after since.
+// It returns the slice of matching resource IDs and any error encountered.
+func FindResourcesModifiedSince(ctx context.Context, since time.Time) ([]string, error) {
+	bindings, err := queryDataset(ctx, resourceMetaDataset, buildModifiedSinceQuery(since))
+	if err != nil {
+		return nil, err
+	}
+	res, err := sparql.ParseJSON(bytes.NewReader(bindings))
+	if err != nil {
+		return nil, err
+	}
+	var resources []string
+	for _, row := range res.Solutions() {
+		g, okG := row["g"]
+		if !okG {
+			return nil, fmt.Errorf("invalid binding: %v", row)
+		}
+		resources = append(resources, g.String())
+	}
+	return resources, nil
+}
+
+// buildModifiedSinceQuery selects metadata graphs by their dcterms:modified
+// value. The metadata graph name is the resource ID.
+func buildModifiedSinceQuery(since time.Time) string {
+	return fmt.Sprintf(`SELECT DISTINCT ?g WHERE { GRAPH ?g { ?g <%s> ?modified } FILTER (?modified >= "%s"^^<http://www.w3.org/2001/XMLSchema#dateTime>) }`, shacl.DCTERMS_MODIFIED.RawValue(), since.UTC().Format(time.RFC3339))
+}
+
 // RebuildResourceConformance rebuilds metadata for a resource.
 // It returns the updated metadata, parsed graph, and any error encountered.
-func RebuildResourceConformance(id string) (metadata *ResourceMetadata, graph *rdf2go.Graph, err error) {
-	resource, metadata, err := GetResource(id, false)
+func RebuildResourceConformance(ctx context.Context, id string) (metadata *ResourceMetadata, graph *rdf2go.Graph, err error) {
+	resource, metadata, err := GetResource(ctx, id, false)
 	if err != nil {
 		return nil, nil, err
 	}
-	return updateResourceMetadata(rdf2go.NewResource(id), resource, true)
+	return updateResourceMetadata(ctx, rdf2go.NewResource(id), resource, true)
 }
 
 // metadataUpdateTemplate renders the RDF triples persisted to the metadata dataset.
@@ -81,12 +111,12 @@ var metadataUpdateTemplate = template.Must(template.New("").Funcs(template.FuncM
 
 // loadResourceMetadata reads resource metadata triples.
 // It returns the parsed metadata and any error encountered.
-func loadResourceMetadata(id string) (metadata *ResourceMetadata, err error) {
+func loadResourceMetadata(ctx context.Context, id string) (metadata *ResourceMetadata, err error) {
 	metadata = &ResourceMetadata{
 		Id:          rdf2go.NewResource(id),
 		Conformance: make(map[string][]string),
 	}
-	bindings, err := queryDataset(resourceMetaDataset, fmt.Sprintf(`SELECT * WHERE { GRAPH <%s> { ?s ?p ?o } }`, id))
+	bindings, err := queryDataset(ctx, resourceMetaDataset, fmt.Sprintf(`SELECT * WHERE { GRAPH <%s> { ?s ?p ?o } }`, id))
 	if err != nil {
 		return
 	}
@@ -125,14 +155,16 @@ func loadResourceMetadata(id string) (metadata *ResourceMetadata, err error) {
 	return
 }
 
-func createResourceMetadata(resource []byte, creator string) (metadata *ResourceMetadata, graph *rdf2go.Graph, err error) {
-	metadata, graph, err = buildResourceConformance(nil, resource)
+func createResourceMetadata(ctx context.Context, resource []byte, creator string) (metadata *ResourceMetadata, graph *rdf2go.Graph, err error) {
+	metadata, graph, err = buildResourceConformance(ctx, nil, resource)
 	if err != nil {
 		return
 	}
-	if exists, err := checkGraphExists(resourceMetaDataset, metadata.Id.RawValue()); exists || err != nil {
+	if exists, err := checkGraphExists(ctx, resourceMetaDataset, metadata.Id.RawValue()); exists || err != nil {
 		return nil, nil, ErrExists
 	}
+	// validation is done, so do not cancel the following write halfway
+	ctx = context.WithoutCancel(ctx)
 	metadata.Creator = creator
 	metadata.Created = time.Now().UTC()
 	metadata.LastModified = metadata.Created
@@ -140,14 +172,14 @@ func createResourceMetadata(resource []byte, creator string) (metadata *Resource
 	if err = metadataUpdateTemplate.Execute(&buf, metadata); err != nil {
 		return
 	}
-	err = uploadGraph(resourceMetaDataset, metadata.Id.RawValue(), buf.Bytes(), nil)
+	err = uploadGraph(ctx, resourceMetaDataset, metadata.Id.RawValue(), buf.Bytes(), nil)
 	return
 }
 
 // updateResourceMetadata writes creator, modified timestamp, and shape conformance triples.
 // It returns the updated metadata, parsed graph, and any error encountered.
-func updateResourceMetadata(id rdf2go.Term, resource []byte, preserveLastModified bool) (metadata *ResourceMetadata, graph *rdf2go.Graph, err error) {
-	if metadata, err = loadResourceMetadata(id.RawValue()); err != nil {
+func updateResourceMetadata(ctx context.Context, id rdf2go.Term, resource []byte, preserveLastModified bool) (metadata *ResourceMetadata, graph *rdf2go.Graph, err error) {
+	if metadata, err = loadResourceMetadata(ctx, id.RawValue()); err != nil {
 		return
 	}
 	// check if exists
@@ -155,7 +187,7 @@ func updateResourceMetadata(id rdf2go.Term, resource []byte, preserveLastModifie
 		return nil, nil, ErrNotFound
 	}
 	var updatedMetadata *ResourceMetadata
-	updatedMetadata, graph, err = buildResourceConformance(id, resource)
+	updatedMetadata, graph, err = buildResourceConformance(ctx, id, resource)
 	if err != nil {
 		return
 	}
@@ -166,26 +198,27 @@ func updateResourceMetadata(id rdf2go.Term, resource []byte, preserveLastModifie
 		metadata.LastModified = time.Now().UTC()
 	}
 	metadata.Conformance = updatedMetadata.Conformance
-	if err = deleteResourceMetadata(id.RawValue()); err != nil {
+	ctx = context.WithoutCancel(ctx)
+	if err = deleteResourceMetadata(ctx, id.RawValue()); err != nil {
 		return
 	}
 	var buf bytes.Buffer
 	if err = metadataUpdateTemplate.Execute(&buf, metadata); err != nil {
 		return
 	}
-	err = uploadGraph(resourceMetaDataset, metadata.Id.RawValue(), buf.Bytes(), nil)
+	err = uploadGraph(ctx, resourceMetaDataset, metadata.Id.RawValue(), buf.Bytes(), nil)
 	return
 }
 
 // deleteResourceMetadata removes the named graph of the resource metadata.
 // It returns an error if the deletion fails.
-func deleteResourceMetadata(id string) error {
-	return deleteGraph(resourceMetaDataset, id)
+func deleteResourceMetadata(ctx context.Context, id string) error {
+	return deleteGraph(ctx, resourceMetaDataset, id)
 }
 
 // buildResourceConformance validates the resource and builds a shape conformance map for contained sub-resources.
 // It returns the metadata, parsed graph, and any error encountered.
-func buildResourceConformance(id rdf2go.Term, resource []byte) (metadata *ResourceMetadata, graph *rdf2go.Graph, err error) {
+func buildResourceConformance(ctx context.Context, id rdf2go.Term, resource []byte) (metadata *ResourceMetadata, graph *rdf2go.Graph, err error) {
 	graph, err = base.ParseGraph(bytes.NewReader(resource))
 	if err != nil {
 		return
@@ -210,11 +243,11 @@ func buildResourceConformance(id rdf2go.Term, resource []byte) (metadata *Resour
 
 	// resolve linked resources since they are needed for validation
 	var linkedResources []string
-	resource, linkedResources, err = resolveLinks(graph, resource)
+	resource, linkedResources, err = resolveLinks(ctx, graph, resource)
 	if err != nil {
 		return nil, nil, fmt.Errorf("resolving linked resources: %w", err)
 	}
-	strictConformance, err := shacl.Validate(string(*shapesGraph.RDF), profile.Id.RawValue(), string(resource), validID.RawValue())
+	strictConformance, err := shacl.Validate(ctx, string(*shapesGraph.RDF), profile.Id.RawValue(), string(resource), validID.RawValue())
 	if err != nil {
 		return
 	}
@@ -223,7 +256,7 @@ func buildResourceConformance(id rdf2go.Term, resource []byte) (metadata *Resour
 		err = fmt.Errorf("resource does not conform to expected shape %s", profile.Id.RawValue())
 		return
 	}
-	conformance, err := shacl.Validate(string(validationShapes), profile.Id.RawValue(), string(resource), validID.RawValue())
+	conformance, err := shacl.Validate(ctx, string(validationShapes), profile.Id.RawValue(), string(resource), validID.RawValue())
 	if err != nil {
 		return nil, nil, err
 	}
@@ -0,0 +1,17 @@
+package rdf
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildModifiedSinceQueryUsesUTCTimestamp(t *testing.T) {
+	query := buildModifiedSinceQuery(time.Date(2024, 5, 1, 10, 0, 0, 0, time.FixedZone("CEST", 2*60*60)))
+	if !strings.Contains(query, `<http://purl.org/dc/terms/modified> ?modified`) {
+		t.Fatalf("expected modification predicate in query: %s", query)
+	}
+	if !strings.Contains(query, `?modified >= "2024-05-01T08:00:00Z"^^<http://www.w3.org/2001/XMLSchema#dateTime>`) {
+		t.Fatalf("expected UTC timestamp filter in query: %s", query)
+	}
+}
@@ -0,0 +1,208 @@
+package rdf
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"rdf-store-backend/base"
+	"rdf-store-backend/shacl"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/deiu/rdf2go"
+	"github.com/google/uuid"
+	"github.com/knakk/rdf"
+	"github.com/knakk/sparql"
+)
+
+var savedSearchDataset = base.EnvVar("FUSEKI_SAVED_SEARCH_DATASET", "savedsearch")
+
+const savedSearchVocabulary = "urn:rdf-store:saved-search:"
+const savedSearchIDPrefix = "urn:uuid:"
+
+var savedSearchQuery = rdf2go.NewResource(savedSearchVocabulary + "query")
+var savedSearchSolrQuery = rdf2go.NewResource(savedSearchVocabulary + "solrQuery")
+var savedSearchSubscribed = rdf2go.NewResource(savedSearchVocabulary + "subscribed")
+var savedSearchEmail = rdf2go.NewResource(savedSearchVocabulary + "email")
+var savedSearchNotifiedUntil = rdf2go.NewResource(savedSearchVocabulary + "notifiedUntil")
+var xsdBoolean = rdf2go.NewResource("http://www.w3.org/2001/XMLSchema#boolean")
+var xsdDateTime = rdf2go.NewResource("http://www.w3.org/2001/XMLSchema#dateTime")
+
+// SavedSearch is a search stored by a user. Exactly one of Query and
+// SolrQuery holds the JSON encoded search.
+type SavedSearch struct {
+	Id      string    `json:"id"`
+	Name    string    `json:"name"`
+	Creator string    `json:"creator,omitempty"`
+	Created time.Time `json:"created"`
+	// Query is a structured search as accepted by POST /search.
+	Query string `json:"query,omitempty"`
+	// SolrQuery is a raw Solr JSON request as accepted by the Solr proxy.
+	SolrQuery string `json:"solrQuery,omitempty"`
+	// Subscribed enables change alerts.
+	Subscribed bool `json:"subscribed"`
+	// Email is the alert recipient for mail sinks.
+	Email string `json:"email,omitempty"`
+	// NotifiedUntil is the end of the last period alerts were delivered for.
+	NotifiedUntil time.Time `json:"notifiedUntil,omitzero"`
+}
+
+// CreateSavedSearch assigns an ID and creation time to a saved search and stores it.
+// It returns an error if storing fails.
+func CreateSavedSearch(ctx context.Context, search *SavedSearch) error {
+	search.Id = savedSearchIDPrefix + uuid.NewString()
+	search.Created = time.Now().UTC()
+	if search.Subscribed {
+		search.NotifiedUntil = search.Created
+	}
+	return storeSavedSearch(ctx, search)
+}
+
+// GetSavedSearch loads a saved search.
+// It returns ErrNotFound if it does not exist or belongs to another creator.
+func GetSavedSearch(ctx context.Context, id string, creator string) (*SavedSearch, error) {
+	// saved search IDs are generated, so anything else is unknown
+	if _, err := uuid.Parse(strings.TrimPrefix(id, savedSearchIDPrefix)); err != nil || !strings.HasPrefix(id, savedSearchIDPrefix) {
+		return nil, ErrNotFound
+	}
+	searches, err := querySavedSearches(ctx, fmt.Sprintf(`VALUES ?g { <%s> }`, id), "")
+	if err != nil {
+		return nil, err
+	}
+	if len(searches) == 0 || searches[0].Creator != creator {
+		return nil, ErrNotFound
+	}
+	return searches[0], nil
+}
+
+// ListSavedSearches returns the saved searches of a creator ordered by creation time.
+func ListSavedSearches(ctx context.Context, creator string) ([]*SavedSearch, error) {
+	return querySavedSearches(ctx, "", fmt.Sprintf(`?g <%s> %s .`, shacl.DCTERMS_CREATOR.RawValue(), rdf2go.NewLiteral(creator).String()))
+}
+
+// ListSubscribedSearches returns all saved searches with alerts enabled.
+func ListSubscribedSearches(ctx context.Context) ([]*SavedSearch, error) {
+	return querySavedSearches(ctx, "", fmt.Sprintf(`?g <%s> true .`, savedSearchSubscribed.RawValue()))
+}
+
+// SubscribeSavedSearch enables or disables alerts for a saved search. Enabling
+// starts alerting on changes made from now on.
+// It returns ErrNotFound if the search does not exist or belongs to another creator.
+func SubscribeSavedSearch(ctx context.Context, id string, creator string, subscribed bool, email string) (*SavedSearch, error) {
+	search, err := GetSavedSearch(ctx, id, creator)
+	if err != nil {
+		return nil, err
+	}
+	if subscribed && !search.Subscribed {
+		search.NotifiedUntil = time.Now().UTC()
+	}
+	search.Subscribed = subscribed
+	if email != "" {
+		search.Email = email
+	}
+	return search, storeSavedSearch(ctx, search)
+}
+
+// MarkSavedSearchNotified records that alerts up to until have been delivered.
+func MarkSavedSearchNotified(ctx context.Context, search *SavedSearch, until time.Time) error {
+	search.NotifiedUntil = until.UTC()
+	return storeSavedSearch(ctx, search)
+}
+
+// DeleteSavedSearch removes a saved search.
+// It returns ErrNotFound if the search does not exist or belongs to another creator.
+func DeleteSavedSearch(ctx context.Context, id string, creator string) error {
+	if _, err := GetSavedSearch(ctx, id, creator); err != nil {
+		return err
+	}
+	return deleteGraph(ctx, savedSearchDataset, id)
+}
+
+// storeSavedSearch replaces the named graph holding the saved search.
+func storeSavedSearch(ctx context.Context, search *SavedSearch) error {
+	return uploadGraph(ctx, savedSearchDataset, search.Id, serializeSavedSearch(search), nil)
+}
+
+// serializeSavedSearch encodes a saved search as N-Triples.
+func serializeSavedSearch(search *SavedSearch) []byte {
+	id := rdf2go.NewResource(search.Id)
+	triples := []*rdf2go.Triple{
+		rdf2go.NewTriple(id, shacl.DCTERMS_TITLE, rdf2go.NewLiteral(search.Name)),
+		rdf2go.NewTriple(id, shacl.DCTERMS_CREATOR, rdf2go.NewLiteral(search.Creator)),
+		rdf2go.NewTriple(id, shacl.DCTERMS_CREATED, rdf2go.NewLiteralWithDatatype(search.Created.UTC().Format(time.RFC3339), xsdDateTime)),
+		rdf2go.NewTriple(id, savedSearchSubscribed, rdf2go.NewLiteralWithDatatype(fmt.Sprint(search.Subscribed), xsdBoolean)),
+	}
+	if search.Query != "" {
+		triples = append(triples, rdf2go.NewTriple(id, savedSearchQuery, rdf2go.NewLiteral(search.Query)))
+	}
+	if search.SolrQuery != "" {
+		triples = append(triples, rdf2go.NewTriple(id, savedSearchSolrQuery, rdf2go.NewLiteral(search.SolrQuery)))
+	}
+	if search.Email != "" {
+		triples = append(triples, rdf2go.NewTriple(id, savedSearchEmail, rdf2go.NewLiteral(search.Email)))
+	}
+	if !search.NotifiedUntil.IsZero() {
+		triples = append(triples, rdf2go.NewTriple(id, savedSearchNotifiedUntil, rdf2go.NewLiteralWithDatatype(search.NotifiedUntil.UTC().Format(time.RFC3339Nano), xsdDateTime)))
+	}
+	var buf bytes.Buffer
+	for _, triple := range triples {
+		buf.WriteString(triple.String())
+		buf.WriteString("\n")
+	}
+	return buf.Bytes()
+}
+
+// querySavedSearches loads the saved searches selected by optional SPARQL
+// VALUES bindings and triple patterns on the saved search ?g.
+func querySavedSearches(ctx context.Context, values string, pattern string) ([]*SavedSearch, error) {
+	bindings, err := queryDataset(ctx, savedSearchDataset, fmt.Sprintf(`SELECT ?g ?p ?o WHERE { %s GRAPH ?g { %s ?g ?p ?o } }`, values, pattern))
+	if err != nil {
+		return nil, err
+	}
+	res, err := sparql.ParseJSON(bytes.NewReader(bindings))
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*SavedSearch)
+	for _, row := range res.Solutions() {
+		g, okG := row["g"].(rdf.Context)
+		p, okP := row["p"].(rdf.Predicate)
+		o, okO := row["o"].(rdf.Object)
+		if !okG || !okP || !okO {
+			return nil, fmt.Errorf("invalid binding: %v", row)
+		}
+		search, ok := byID[g.String()]
+		if !ok {
+			search = &SavedSearch{Id: g.String()}
+			byID[g.String()] = search
+		}
+		value := o.String()
+		switch p.String() {
+		case shacl.DCTERMS_TITLE.RawValue():
+			search.Name = value
+		case shacl.DCTERMS_CREATOR.RawValue():
+			search.Creator = value
+		case shacl.DCTERMS_CREATED.RawValue():
+			search.Created, _ = time.Parse(time.RFC3339, value)
+		case savedSearchQuery.RawValue():
+			search.Query = value
+		case savedSearchSolrQuery.RawValue():
+			search.SolrQuery = value
+		case savedSearchSubscribed.RawValue():
+			search.Subscribed = value == "true"
+		case savedSearchEmail.RawValue():
+			search.Email = value
+		case savedSearchNotifiedUntil.RawValue():
+			search.NotifiedUntil, _ = time.Parse(time.RFC3339Nano, value)
+		}
+	}
+	searches := make([]*SavedSearch, 0, len(byID))
+	for _, search := range byID {
+		searches = append(searches, search)
+	}
+	sort.Slice(searches, func(i, j int) bool {
+		return searches[i].Created.Before(searches[j].Created)
+	})
+	return searches, nil
+}
@@ -5,9 +5,14 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"rdf-store-backend/httpclient"
+	"rdf-store-backend/metrics"
 	"strings"
+	"time"
 )
 
+var httpClient = httpclient.New(metrics.ServiceFuseki, httpclient.Timeout("FUSEKI_TIMEOUT", 60))
+
 // isValidIRI validates that a value is a URL-like IRI.
 // It returns true when parsing succeeds and a scheme is present.
 func isValidIRI(value string) bool {
@@ -17,19 +22,29 @@ func isValidIRI(value string) bool {
 
 // doRequest executes an HTTP request and reads the response body.
 // It returns the status code, response bytes, and any error encountered.
-func doRequest(req *http.Request) (int, []byte, error) {
-	resp, err := http.DefaultClient.Do(req)
+func doRequest(req *http.Request) (status int, data []byte, err error) {
+	start := time.Now()
+	defer func() {
+		metrics.ObserveBackendCall(metrics.ServiceFuseki, metrics.FusekiOperation(req.URL.Path), start, status, err)
+	}()
+	resp, err := httpclient.Do(httpClient, req, isIdempotent(req))
 	if err != nil {
 		return 0, nil, err
 	}
 	defer resp.Body.Close()
-	data, err := io.ReadAll(resp.Body)
+	data, err = io.ReadAll(resp.Body)
 	if err != nil {
 		return resp.StatusCode, nil, err
 	}
 	return resp.StatusCode, data, nil
 }
 
+// isIdempotent reports whether a Fuseki request can be repeated safely.
+// Reads and SPARQL queries can, while updates and uploads cannot.
+func isIdempotent(req *http.Request) bool {
+	return req.Method == http.MethodGet || metrics.FusekiOperation(req.URL.Path) == "query"
+}
+
 // newHTTPError formats an HTTP error message using context, status, and body.
 // It returns an error with a formatted message.
 func newHTTPError(context string, status int, body []byte) error {
@@ -0,0 +1,186 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Seconds of the months and years of durations, which have no fixed length.
+// They are taken from the average Gregorian year.
+const (
+	secondsPerYear  = 31556952
+	secondsPerMonth = secondsPerYear / 12
+)
+
+var (
+	integerLexical = regexp.MustCompile(`^[+-]?\d+$`)
+	decimalLexical = regexp.MustCompile(`^[+-]?(\d+(\.\d*)?|\.\d+)$`)
+	floatLexical   = regexp.MustCompile(`^([+-]?(\d+(\.\d*)?|\.\d+)([eE][+-]?\d+)?|[+-]?INF|NaN)$`)
+	// durationLexical captures the sign, years, months, days, hours, minutes
+	// and seconds of an xsd:duration.
+	durationLexical = regexp.MustCompile(`^(-)?P(?:(\d+)Y)?(?:(\d+)M)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+(?:\.\d+)?)S)?)?$`)
+	timeLexical     = regexp.MustCompile(`^(\d{2}):(\d{2}):(\d{2}(?:\.\d+)?)(Z|[+-]\d{2}:\d{2})?$`)
+	htmlTags        = regexp.MustCompile(`<[^>]*>`)
+)
+
+// integerBounds are the value spaces of the bounded XSD integer types. A nil
+// bound is unbounded.
+var integerBounds = map[string][2]*big.Int{
+	fmt.Sprintf(prefixXSD, "nonNegativeInteger"): {big.NewInt(0), nil},
+	fmt.Sprintf(prefixXSD, "positiveInteger"):    {big.NewInt(1), nil},
+	fmt.Sprintf(prefixXSD, "nonPositiveInteger"): {nil, big.NewInt(0)},
+	fmt.Sprintf(prefixXSD, "negativeInteger"):    {nil, big.NewInt(-1)},
+	fmt.Sprintf(prefixXSD, "long"):               {big.NewInt(math.MinInt64), big.NewInt(math.MaxInt64)},
+	fmt.Sprintf(prefixXSD, "int"):                {big.NewInt(math.MinInt32), big.NewInt(math.MaxInt32)},
+	fmt.Sprintf(prefixXSD, "short"):              {big.NewInt(math.MinInt16), big.NewInt(math.MaxInt16)},
+	fmt.Sprintf(prefixXSD, "byte"):               {big.NewInt(math.MinInt8), big.NewInt(math.MaxInt8)},
+	fmt.Sprintf(prefixXSD, "unsignedLong"):       {big.NewInt(0), new(big.Int).SetUint64(math.MaxUint64)},
+	fmt.Sprintf(prefixXSD, "unsignedInt"):        {big.NewInt(0), big.NewInt(math.MaxUint32)},
+	fmt.Sprintf(prefixXSD, "unsignedShort"):      {big.NewInt(0), big.NewInt(math.MaxUint16)},
+	fmt.Sprintf(prefixXSD, "unsignedByte"):       {big.NewInt(0), big.NewInt(math.MaxUint8)},
+}
+
+// valueConversions convert the lexical values of criteria to the indexed
+// representation of datatypes that are not indexed as is.
+var valueConversions = map[string]func(string) (string, error){
+	"dur": func(lexical string) (string, error) {
+		seconds, err := durationSeconds(lexical, "")
+		if err != nil {
+			return "", err
+		}
+		return strconv.FormatFloat(seconds, 'f', -1, 64), nil
+	},
+	"tms": timeValue,
+}
+
+// numberValue validates the lexical value of a numeric literal against its
+// datatype. Special float values are reported as not finite, as they cannot
+// be indexed as numbers.
+// It returns an error when the value is not in the lexical or value space.
+func numberValue(lexical string, datatype string) (number float64, finite bool, err error) {
+	switch {
+	case datatype == fmt.Sprintf(prefixXSD, "float") || datatype == fmt.Sprintf(prefixXSD, "double"):
+		if !floatLexical.MatchString(lexical) {
+			return 0, false, fmt.Errorf("invalid floating point number %q", lexical)
+		}
+		if strings.HasSuffix(lexical, "INF") || lexical == "NaN" {
+			return 0, false, nil
+		}
+	case datatype == fmt.Sprintf(prefixXSD, "decimal"):
+		if !decimalLexical.MatchString(lexical) {
+			return 0, false, fmt.Errorf("invalid decimal %q", lexical)
+		}
+	default:
+		if !integerLexical.MatchString(lexical) {
+			return 0, false, fmt.Errorf("invalid integer %q", lexical)
+		}
+		if bounds, ok := integerBounds[datatype]; ok {
+			value, _ := new(big.Int).SetString(strings.TrimPrefix(lexical, "+"), 10)
+			if (bounds[0] != nil && value.Cmp(bounds[0]) < 0) || (bounds[1] != nil && value.Cmp(bounds[1]) > 0) {
+				return 0, false, fmt.Errorf("integer %s out of range", lexical)
+			}
+		}
+	}
+	number, err = strconv.ParseFloat(lexical, 64)
+	if err != nil && !errors.Is(err, strconv.ErrRange) {
+		return 0, false, fmt.Errorf("invalid number %q", lexical)
+	}
+	return number, !math.IsInf(number, 0), nil
+}
+
+// durationSeconds converts an xsd:duration or one of its derived types to
+// seconds, counting months and years by their average length.
+// It returns an error when the value is not a valid duration of the datatype.
+func durationSeconds(lexical string, datatype string) (float64, error) {
+	match := durationLexical.FindStringSubmatch(lexical)
+	if match == nil || strings.HasSuffix(lexical, "P") || strings.HasSuffix(lexical, "T") {
+		return 0, fmt.Errorf("invalid duration %q", lexical)
+	}
+	yearMonth := match[2] != "" || match[3] != ""
+	dayTime := match[4] != "" || match[5] != "" || match[6] != "" || match[7] != ""
+	if (datatype == fmt.Sprintf(prefixXSD, "yearMonthDuration") && dayTime) || (datatype == fmt.Sprintf(prefixXSD, "dayTimeDuration") && yearMonth) {
+		return 0, fmt.Errorf("invalid duration %q for %s", lexical, datatype)
+	}
+	seconds := 0.0
+	for i, unit := range []float64{secondsPerYear, secondsPerMonth, 86400, 3600, 60, 1} {
+		if match[i+2] != "" {
+			value, err := strconv.ParseFloat(match[i+2], 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid duration %q", lexical)
+			}
+			seconds += value * unit
+		}
+	}
+	if match[1] != "" {
+		seconds = -seconds
+	}
+	return seconds, nil
+}
+
+// timeValue converts an xsd:time to the instant of that time on 1970-01-01
+// in UTC, so times are indexed and compared as dates.
+// It returns an error when the value is not a valid time.
+func timeValue(lexical string) (string, error) {
+	match := timeLexical.FindStringSubmatch(lexical)
+	if match == nil {
+		return "", fmt.Errorf("invalid time %q", lexical)
+	}
+	zone := match[4]
+	if zone == "" {
+		zone = "Z"
+	}
+	value, err := time.Parse(time.RFC3339Nano, "1970-01-01T"+match[1]+":"+match[2]+":"+match[3]+zone)
+	if err != nil {
+		return "", fmt.Errorf("invalid time %q", lexical)
+	}
+	return value.UTC().Format(time.RFC3339Nano), nil
+}
+
+// dateValue converts an xsd:date, xsd:dateTime or xsd:dateTimeStamp to the
+// UTC instant indexed in valueDate. Dates start at midnight, ignoring their
+// timezone, and date times without timezone are taken as UTC.
+// It returns an error when the value is not a valid date of the datatype.
+func dateValue(lexical string, datatype string) (string, error) {
+	if match := partialDate.FindStringSubmatch(lexical); match != nil && len(match[1]) == 10 {
+		if _, err := time.Parse(time.DateOnly, match[1]); err != nil {
+			return "", fmt.Errorf("invalid date %q", lexical)
+		}
+		return match[1] + "T00:00:00Z", nil
+	}
+	value := lexical
+	if !strings.HasSuffix(value, "Z") && !hasTimezoneOffset(value) {
+		if datatype == fmt.Sprintf(prefixXSD, "dateTimeStamp") {
+			return "", fmt.Errorf("date time stamp %q without timezone", lexical)
+		}
+		value += "Z"
+	}
+	date, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return "", fmt.Errorf("invalid date %q", lexical)
+	}
+	return date.UTC().Format(time.RFC3339Nano), nil
+}
+
+// booleanValue returns the canonical form of an xsd:boolean.
+// It returns an error when the value is not a valid boolean.
+func booleanValue(lexical string) (string, error) {
+	switch lexical {
+	case "true", "1":
+		return "true", nil
+	case "false", "0":
+		return "false", nil
+	}
+	return "", fmt.Errorf("invalid boolean %q", lexical)
+}
+
+// htmlText returns the text content of an rdf:HTML literal.
+func htmlText(lexical string) string {
+	return strings.Join(strings.Fields(html.UnescapeString(htmlTags.ReplaceAllString(lexical, " "))), " ")
+}
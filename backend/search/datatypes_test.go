@@ -0,0 +1,141 @@
+package search
+
+import (
+	"fmt"
+	"rdf-store-backend/rdf"
+	"rdf-store-backend/shacl"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/deiu/rdf2go"
+)
+
+func TestNumberValueValidatesXSDNumericTypes(t *testing.T) {
+	for _, test := range []struct {
+		lexical  string
+		datatype string
+		valid    bool
+	}{
+		{"42", "nonNegativeInteger", true},
+		{"0", "positiveInteger", false},
+		{"-1", "negativeInteger", true},
+		{"1", "nonPositiveInteger", false},
+		{"128", "byte", false},
+		{"+127", "byte", true},
+		{"18446744073709551615", "unsignedLong", true},
+		{"-1", "unsignedInt", false},
+		{"1.5", "integer", false},
+		{".5", "decimal", true},
+		{"1e3", "decimal", false},
+		{"-1.5E-3", "double", true},
+		{"INF", "float", true},
+		{"abc", "double", false},
+	} {
+		_, _, err := numberValue(test.lexical, fmt.Sprintf(prefixXSD, test.datatype))
+		if (err == nil) != test.valid {
+			t.Errorf("%s as %s: unexpected result %v", test.lexical, test.datatype, err)
+		}
+	}
+	if _, finite, _ := numberValue("-INF", fmt.Sprintf(prefixXSD, "double")); finite {
+		t.Fatal("expected -INF not to be finite")
+	}
+}
+
+func TestDurationSecondsNormalizesDurations(t *testing.T) {
+	for lexical, expected := range map[string]float64{
+		"PT1H30M":  5400,
+		"P1DT0.5S": 86400.5,
+		"-P2D":     -172800,
+		"P1Y":      secondsPerYear,
+		"P1M":      secondsPerMonth,
+	} {
+		if seconds, err := durationSeconds(lexical, ""); err != nil || seconds != expected {
+			t.Errorf("%s: expected %v, got %v (%v)", lexical, expected, seconds, err)
+		}
+	}
+	for _, invalid := range []struct{ lexical, datatype string }{
+		{"P", ""}, {"PT", ""}, {"P1DT", ""}, {"1D", ""}, {"PT1.5M", ""},
+		{"P1Y", fmt.Sprintf(prefixXSD, "dayTimeDuration")},
+		{"PT1H", fmt.Sprintf(prefixXSD, "yearMonthDuration")},
+	} {
+		if _, err := durationSeconds(invalid.lexical, invalid.datatype); err == nil {
+			t.Errorf("expected error for %q as %q", invalid.lexical, invalid.datatype)
+		}
+	}
+}
+
+func TestTemporalValuesNormalizeToUTC(t *testing.T) {
+	for _, test := range []struct {
+		value    func() (string, error)
+		expected string
+	}{
+		{func() (string, error) { return timeValue("10:30:00+02:00") }, "1970-01-01T08:30:00Z"},
+		{func() (string, error) { return timeValue("23:59:59.5") }, "1970-01-01T23:59:59.5Z"},
+		{func() (string, error) { return dateValue("2024-05-01+02:00", "") }, "2024-05-01T00:00:00Z"},
+		{func() (string, error) { return dateValue("2024-05-01T10:00:00-01:00", "") }, "2024-05-01T11:00:00Z"},
+	} {
+		if value, err := test.value(); err != nil || value != test.expected {
+			t.Errorf("expected %s, got %q (%v)", test.expected, value, err)
+		}
+	}
+	if _, err := timeValue("25:00:00"); err == nil {
+		t.Error("expected error for invalid time")
+	}
+	if _, err := dateValue("2024-02-30", ""); err == nil {
+		t.Error("expected error for invalid date")
+	}
+	if _, err := dateValue("2024-05-01T10:00:00", fmt.Sprintf(prefixXSD, "dateTimeStamp")); err == nil {
+		t.Error("expected error for date time stamp without timezone")
+	}
+}
+
+func TestCriterionFilterConvertsDurations(t *testing.T) {
+	filter, err := criterionFilter(Criterion{Path: []string{"p"}, Operator: OperatorRange, Min: "PT1H", Max: "P1D", Datatype: fmt.Sprintf(prefixXSD, "duration")})
+	if err != nil || !strings.Contains(filter, "valueNumber:[\"3600\" TO \"86400\"]") {
+		t.Fatalf("unexpected filter %q (%v)", filter, err)
+	}
+	if _, err := criterionFilter(Criterion{Path: []string{"p"}, Operator: OperatorEquals, Value: "1 hour", Datatype: fmt.Sprintf(prefixXSD, "duration")}); err == nil {
+		t.Fatal("expected error for invalid duration")
+	}
+}
+
+func TestIndexerSkipsInvalidTypedLiterals(t *testing.T) {
+	const (
+		shapeID  = "http://example.org/Run"
+		duration = "http://example.org/duration"
+		count    = "http://example.org/count"
+		summary  = "http://example.org/summary"
+	)
+	shape := &shacl.NodeShape{
+		Id: rdf2go.NewResource(shapeID), Parents: map[string]bool{}, Alternatives: map[string]bool{},
+		Properties: map[string][]*shacl.Property{
+			duration: {{Id: rdf2go.NewResource("urn:property:duration"), Path: duration}},
+			count:    {{Id: rdf2go.NewResource("urn:property:count"), Path: count}},
+			summary:  {{Id: rdf2go.NewResource("urn:property:summary"), Path: summary}},
+		},
+	}
+	previousProfiles := rdf.Profiles
+	rdf.Profiles = map[string]*shacl.NodeShape{shapeID: shape}
+	t.Cleanup(func() { rdf.Profiles = previousProfiles })
+
+	subject := rdf2go.NewResource("http://example.org/run")
+	graph := rdf2go.NewGraph("")
+	graph.AddTriple(subject, rdf2go.NewResource(duration), rdf2go.NewLiteralWithDatatype("PT2M", rdf2go.NewResource(fmt.Sprintf(prefixXSD, "duration"))))
+	graph.AddTriple(subject, rdf2go.NewResource(count), rdf2go.NewLiteralWithDatatype("7", rdf2go.NewResource(fmt.Sprintf(prefixXSD, "positiveInteger"))))
+	graph.AddTriple(subject, rdf2go.NewResource(count), rdf2go.NewLiteralWithDatatype("-7", rdf2go.NewResource(fmt.Sprintf(prefixXSD, "positiveInteger"))))
+	graph.AddTriple(subject, rdf2go.NewResource(summary), rdf2go.NewLiteralWithDatatype("<p>Fast &amp; <b>stable</b></p>", rdf2go.NewResource(fmt.Sprintf(prefixRDF, "HTML"))))
+	metadata := &rdf.ResourceMetadata{Id: subject, Conformance: map[string][]string{subject.RawValue(): {shapeID}}}
+	doc := document{"id": "run"}
+	newQueryIndexer(graph, metadata, shapeID, newQueryTraversalState(), nil).index(subject, shape, &doc)
+
+	if values := valueChildren(doc, []string{duration}, "valueNumber"); !slices.Equal(values, []any{"120"}) {
+		t.Fatalf("expected the duration in seconds, got %#v", values)
+	}
+	if values := valueChildren(doc, []string{count}, "valueNumber"); !slices.Equal(values, []any{"7"}) {
+		t.Fatalf("expected the negative positive integer to be skipped, got %#v", values)
+	}
+	if values := valueChildren(doc, []string{summary}, "valueText"); !slices.Equal(values, []any{"Fast & stable"}) {
+		t.Fatalf("expected the HTML text, got %#v", values)
+	}
+}
@@ -0,0 +1,548 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"rdf-store-backend/base"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// GeoJSONDataType is the GeoSPARQL datatype of GeoJSON literals. They are
+// converted to WKT when indexed.
+const GeoJSONDataType = "http://www.opengis.net/ont/geosparql#geoJSONLiteral"
+
+const (
+	// crs84 is the default reference system of GeoSPARQL WKT literals, which
+	// may name it explicitly.
+	crs84 = "<http://www.opengis.net/def/crs/OGC/1.3/CRS84>"
+	// kilometersPerDegree converts distances for Solr's geodetic shapes.
+	kilometersPerDegree = 111.1950802335329
+	// maxGeometriesPerHit bounds the geometries returned for a single hit.
+	maxGeometriesPerHit = 10
+)
+
+// geometryTypes maps WKT geometry types to their GeoJSON names and the nesting
+// depth of their coordinates, where a single position has depth 0.
+var geometryTypes = map[string]struct {
+	name  string
+	depth int
+}{
+	"POINT":              {"Point", 0},
+	"LINESTRING":         {"LineString", 1},
+	"MULTIPOINT":         {"MultiPoint", 1},
+	"POLYGON":            {"Polygon", 2},
+	"MULTILINESTRING":    {"MultiLineString", 2},
+	"MULTIPOLYGON":       {"MultiPolygon", 3},
+	"GEOMETRYCOLLECTION": {"GeometryCollection", -1},
+}
+
+// geometry is a parsed WKT or GeoJSON geometry. Coordinates nest []any lists
+// down to []float64 positions.
+type geometry struct {
+	wktType     string
+	coordinates any
+	geometries  []geometry
+}
+
+// GeometryValue is a geometry of a hit.
+type GeometryValue struct {
+	// Path holds the segments of the value's path in the notation of search
+	// criteria.
+	Path []string `json:"path"`
+	// Geometry is a GeoJSON geometry object.
+	Geometry json.RawMessage `json:"geometry"`
+}
+
+// geoDatatypes returns the datatypes of indexed geometry literals.
+func geoDatatypes() []string {
+	if base.Configuration.GeoDataType == GeoJSONDataType {
+		return []string{GeoJSONDataType}
+	}
+	return []string{base.Configuration.GeoDataType, GeoJSONDataType}
+}
+
+// parseGeometry reads a geometry in GeoJSON if it starts with "{", and in WKT
+// otherwise.
+// It returns an error if the geometry is malformed.
+func parseGeometry(value string) (geometry, error) {
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, "{") {
+		return parseGeoJSON([]byte(value))
+	}
+	return parseWKT(value)
+}
+
+// parseGeometryLiteral reads the lexical form of a geometry literal.
+// It returns an error if the literal is malformed.
+func parseGeometryLiteral(value string, datatype string) (geometry, error) {
+	if datatype == GeoJSONDataType {
+		return parseGeoJSON([]byte(value))
+	}
+	return parseWKT(value)
+}
+
+// parseWKT reads a 2D or 3D WKT geometry. GeoSPARQL literals may start with
+// their reference system, of which only the default CRS84 is supported.
+// It returns an error if the geometry is malformed or empty.
+func parseWKT(value string) (geometry, error) {
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, "<") {
+		if !strings.HasPrefix(value, crs84) {
+			return geometry{}, errors.New("only the CRS84 reference system is supported")
+		}
+		value = strings.TrimSpace(strings.TrimPrefix(value, crs84))
+	}
+	parser := &wktParser{tokens: tokenizeWKT(value)}
+	parsed, err := parser.geometry()
+	if err != nil {
+		return geometry{}, err
+	}
+	if token := parser.next(); token != "" {
+		return geometry{}, fmt.Errorf("unexpected %q after geometry", token)
+	}
+	return parsed, nil
+}
+
+func tokenizeWKT(value string) []string {
+	tokens := make([]string, 0)
+	start := -1
+	for i, r := range value {
+		if r == '(' || r == ')' || r == ',' || unicode.IsSpace(r) {
+			if start >= 0 {
+				tokens = append(tokens, value[start:i])
+				start = -1
+			}
+			if !unicode.IsSpace(r) {
+				tokens = append(tokens, string(r))
+			}
+		} else if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, value[start:])
+	}
+	return tokens
+}
+
+type wktParser struct {
+	tokens []string
+	pos    int
+}
+
+func (parser *wktParser) peek() string {
+	if parser.pos < len(parser.tokens) {
+		return parser.tokens[parser.pos]
+	}
+	return ""
+}
+
+func (parser *wktParser) next() string {
+	token := parser.peek()
+	if token != "" {
+		parser.pos++
+	}
+	return token
+}
+
+func (parser *wktParser) expect(token string) error {
+	if next := parser.next(); next != token {
+		return fmt.Errorf("expected %q, got %q", token, next)
+	}
+	return nil
+}
+
+func (parser *wktParser) geometry() (geometry, error) {
+	wktType := strings.ToUpper(parser.next())
+	geometryType, ok := geometryTypes[wktType]
+	if !ok {
+		return geometry{}, fmt.Errorf("unsupported geometry type %q", wktType)
+	}
+	switch strings.ToUpper(parser.peek()) {
+	case "Z", "M", "ZM":
+		parser.next()
+	case "EMPTY":
+		return geometry{}, errors.New("empty geometries are not supported")
+	}
+	parsed := geometry{wktType: wktType}
+	if geometryType.depth < 0 {
+		if err := parser.expect("("); err != nil {
+			return geometry{}, err
+		}
+		for {
+			member, err := parser.geometry()
+			if err != nil {
+				return geometry{}, err
+			}
+			parsed.geometries = append(parsed.geometries, member)
+			if token := parser.next(); token == ")" {
+				return parsed, nil
+			} else if token != "," {
+				return geometry{}, fmt.Errorf("expected \",\" or \")\", got %q", token)
+			}
+		}
+	}
+	coordinates, err := parser.list()
+	if err != nil {
+		return geometry{}, err
+	}
+	switch wktType {
+	case "POINT":
+		if len(coordinates) != 1 {
+			return geometry{}, errors.New("a point has a single position")
+		}
+		parsed.coordinates = coordinates[0]
+	case "MULTIPOINT":
+		// points may or may not be enclosed in parentheses
+		for i, point := range coordinates {
+			if list, ok := point.([]any); ok && len(list) == 1 {
+				coordinates[i] = list[0]
+			}
+		}
+		parsed.coordinates = coordinates
+	default:
+		parsed.coordinates = coordinates
+	}
+	if !hasDepth(parsed.coordinates, geometryType.depth) {
+		return geometry{}, fmt.Errorf("malformed %s coordinates", wktType)
+	}
+	return parsed, nil
+}
+
+// list parses a parenthesized list of positions or nested lists.
+func (parser *wktParser) list() ([]any, error) {
+	if err := parser.expect("("); err != nil {
+		return nil, err
+	}
+	items := make([]any, 0)
+	for {
+		if parser.peek() == "(" {
+			item, err := parser.list()
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		} else {
+			position := make([]float64, 0, 2)
+			for token := parser.peek(); token != "," && token != ")" && token != ""; token = parser.peek() {
+				number, err := strconv.ParseFloat(parser.next(), 64)
+				if err != nil || math.IsNaN(number) || math.IsInf(number, 0) {
+					return nil, fmt.Errorf("invalid coordinate %q", token)
+				}
+				position = append(position, number)
+			}
+			items = append(items, position)
+		}
+		if token := parser.next(); token == ")" {
+			return items, nil
+		} else if token != "," {
+			return nil, fmt.Errorf("expected \",\" or \")\", got %q", token)
+		}
+	}
+}
+
+// hasDepth reports whether coordinates nest positions of 2 or 3 numbers
+// exactly depth lists deep.
+func hasDepth(coordinates any, depth int) bool {
+	switch value := coordinates.(type) {
+	case []float64:
+		return depth == 0 && (len(value) == 2 || len(value) == 3)
+	case []any:
+		if depth == 0 || len(value) == 0 {
+			return false
+		}
+		for _, item := range value {
+			if !hasDepth(item, depth-1) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// parseGeoJSON reads a GeoJSON geometry, feature or feature collection.
+// It returns an error if the object is malformed.
+func parseGeoJSON(data []byte) (geometry, error) {
+	var object struct {
+		Type        string            `json:"type"`
+		Coordinates any               `json:"coordinates"`
+		Geometries  []json.RawMessage `json:"geometries"`
+		Geometry    json.RawMessage   `json:"geometry"`
+		Features    []json.RawMessage `json:"features"`
+	}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return geometry{}, fmt.Errorf("invalid GeoJSON: %w", err)
+	}
+	members := object.Geometries
+	switch object.Type {
+	case "Feature":
+		return parseGeoJSON(object.Geometry)
+	case "FeatureCollection":
+		members = object.Features
+		fallthrough
+	case "GeometryCollection":
+		if len(members) == 0 {
+			return geometry{}, errors.New("empty geometries are not supported")
+		}
+		parsed := geometry{wktType: "GEOMETRYCOLLECTION"}
+		for _, member := range members {
+			memberGeometry, err := parseGeoJSON(member)
+			if err != nil {
+				return geometry{}, err
+			}
+			parsed.geometries = append(parsed.geometries, memberGeometry)
+		}
+		return parsed, nil
+	}
+	for wktType, geometryType := range geometryTypes {
+		if geometryType.name == object.Type && geometryType.depth >= 0 {
+			parsed := geometry{wktType: wktType, coordinates: geoJSONCoordinates(object.Coordinates)}
+			if !hasDepth(parsed.coordinates, geometryType.depth) {
+				return geometry{}, fmt.Errorf("malformed %s coordinates", object.Type)
+			}
+			return parsed, nil
+		}
+	}
+	return geometry{}, fmt.Errorf("unsupported GeoJSON type %q", object.Type)
+}
+
+// geoJSONCoordinates converts decoded JSON arrays to nested lists and positions.
+func geoJSONCoordinates(value any) any {
+	list, ok := value.([]any)
+	if !ok {
+		return value
+	}
+	position := make([]float64, 0, len(list))
+	for _, item := range list {
+		number, ok := item.(float64)
+		if !ok {
+			break
+		}
+		position = append(position, number)
+	}
+	if len(list) > 0 && len(position) == len(list) {
+		return position
+	}
+	nested := make([]any, 0, len(list))
+	for _, item := range list {
+		nested = append(nested, geoJSONCoordinates(item))
+	}
+	return nested
+}
+
+// wkt formats the geometry in WKT.
+func (shape geometry) wkt() string {
+	if shape.wktType == "GEOMETRYCOLLECTION" {
+		members := make([]string, 0, len(shape.geometries))
+		for _, member := range shape.geometries {
+			members = append(members, member.wkt())
+		}
+		return shape.wktType + " (" + strings.Join(members, ", ") + ")"
+	}
+	if position, ok := shape.coordinates.([]float64); ok {
+		return shape.wktType + " (" + formatPosition(position) + ")"
+	}
+	if shape.wktType == "MULTIPOINT" {
+		points := make([]string, 0)
+		for _, point := range shape.coordinates.([]any) {
+			points = append(points, "("+formatPosition(point.([]float64))+")")
+		}
+		return shape.wktType + " (" + strings.Join(points, ", ") + ")"
+	}
+	return shape.wktType + " " + formatCoordinates(shape.coordinates)
+}
+
+func formatCoordinates(coordinates any) string {
+	if position, ok := coordinates.([]float64); ok {
+		return formatPosition(position)
+	}
+	items := coordinates.([]any)
+	formatted := make([]string, 0, len(items))
+	for _, item := range items {
+		formatted = append(formatted, formatCoordinates(item))
+	}
+	return "(" + strings.Join(formatted, ", ") + ")"
+}
+
+func formatPosition(position []float64) string {
+	numbers := make([]string, 0, len(position))
+	for _, number := range position {
+		numbers = append(numbers, strconv.FormatFloat(number, 'f', -1, 64))
+	}
+	return strings.Join(numbers, " ")
+}
+
+// MarshalJSON formats the geometry as a GeoJSON geometry object.
+func (shape geometry) MarshalJSON() ([]byte, error) {
+	name := geometryTypes[shape.wktType].name
+	if shape.wktType == "GEOMETRYCOLLECTION" {
+		return json.Marshal(map[string]any{"type": name, "geometries": shape.geometries})
+	}
+	return json.Marshal(map[string]any{"type": name, "coordinates": shape.coordinates})
+}
+
+// geoFilter builds the value filter of a spatial criterion on valueGeo.
+// It returns an error if the criterion's geometry or distance is invalid.
+func geoFilter(criterion Criterion) (string, error) {
+	if strings.TrimSpace(criterion.Value) == "" {
+		return "", errors.New("missing value")
+	}
+	switch criterion.Operator {
+	case OperatorGeoBBox:
+		west, south, east, north, err := parseBBox(criterion.Value)
+		if err != nil {
+			return "", err
+		}
+		// Solr's envelopes list minX, maxX, maxY, minY
+		return fmt.Sprintf(`valueGeo:"Intersects(ENVELOPE(%s, %s, %s, %s))"`,
+			formatBoost(west), formatBoost(east), formatBoost(north), formatBoost(south)), nil
+	case OperatorGeoDistance:
+		point, err := parseGeometry(criterion.Value)
+		if err != nil {
+			return "", fmt.Errorf("invalid point: %w", err)
+		}
+		position, ok := point.coordinates.([]float64)
+		if point.wktType != "POINT" || !ok {
+			return "", errors.New("distance criteria need a point")
+		}
+		if criterion.Distance <= 0 {
+			return "", errors.New("distance criteria need a positive distance")
+		}
+		return fmt.Sprintf(`valueGeo:"Intersects(BUFFER(POINT(%s), %s))"`,
+			formatPosition(position[:2]), formatBoost(criterion.Distance/kilometersPerDegree)), nil
+	}
+	shape, err := parseGeometry(criterion.Value)
+	if err != nil {
+		return "", fmt.Errorf("invalid geometry: %w", err)
+	}
+	predicate := "Intersects"
+	if criterion.Operator == OperatorGeoWithin {
+		predicate = "IsWithin"
+	}
+	return fmt.Sprintf(`valueGeo:"%s(%s)"`, predicate, shape.wkt()), nil
+}
+
+// parseBBox reads a "west,south,east,north" bounding box in degrees, which
+// may be enclosed in brackets like a GeoJSON bbox.
+// It returns an error if the box is malformed.
+func parseBBox(value string) (west, south, east, north float64, err error) {
+	parts := strings.Split(strings.Trim(strings.TrimSpace(value), "[]"), ",")
+	if len(parts) != 4 {
+		return 0, 0, 0, 0, errors.New("a bounding box needs west, south, east and north")
+	}
+	bounds := make([]float64, 4)
+	for i, part := range parts {
+		if bounds[i], err = strconv.ParseFloat(strings.TrimSpace(part), 64); err != nil {
+			return 0, 0, 0, 0, fmt.Errorf("invalid bounding box coordinate %q", part)
+		}
+	}
+	west, south, east, north = bounds[0], bounds[1], bounds[2], bounds[3]
+	if west < -180 || east > 180 || east < -180 || west > 180 || south < -90 || north > 90 || south > north {
+		return 0, 0, 0, 0, errors.New("bounding box out of range")
+	}
+	return west, south, east, north, nil
+}
+
+// buildGeometryRequest selects the geometry values of the hits. Values are
+// grouped by hit, so every hit gets up to maxGeometriesPerHit geometries
+// however many geometries other hits have.
+func buildGeometryRequest(hits []SearchHit) *searchRequest {
+	ids := make([]string, 0, len(hits))
+	groups := make([]string, 0, len(hits))
+	for _, hit := range hits {
+		ids = append(ids, escapeQueryValue(hit.ID))
+		groups = append(groups, hitValuesQuery(hit.ID))
+	}
+	datatypes := make([]string, 0)
+	for _, datatype := range geoDatatypes() {
+		datatypes = append(datatypes, escapeQueryValue(datatype))
+	}
+	return &searchRequest{
+		Query: "*:*",
+		Filter: []string{
+			"docType:value",
+			"datatype:(" + strings.Join(datatypes, " OR ") + ")",
+			"{!child of=docType:entity}id:(" + strings.Join(ids, " OR ") + ")",
+		},
+		Sort:   "id asc",
+		Limit:  len(hits),
+		Fields: []string{"id", "pathIris", "valueGeo"},
+		Params: map[string]any{
+			"group":       "true",
+			"group.query": groups,
+			"group.limit": maxGeometriesPerHit,
+			"group.sort":  "id asc",
+		},
+	}
+}
+
+// parseGeometries adds the geometries of value documents to their hits.
+func parseGeometries(hits []SearchHit, body []byte) error {
+	type valueDoc struct {
+		ID       string   `json:"id"`
+		PathIris []string `json:"pathIris"`
+		ValueGeo string   `json:"valueGeo"`
+	}
+	var payload struct {
+		Grouped map[string]struct {
+			Doclist struct {
+				Docs []valueDoc `json:"docs"`
+			} `json:"doclist"`
+		} `json:"grouped"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return err
+	}
+	for i := range hits {
+		hit := &hits[i]
+		for _, doc := range payload.Grouped[hitValuesQuery(hit.ID)].Doclist.Docs {
+			if len(hit.Geometries) >= maxGeometriesPerHit {
+				break
+			}
+			shape, err := parseWKT(doc.ValueGeo)
+			if err != nil {
+				// values indexed before geometries were stored have no WKT
+				continue
+			}
+			data, err := json.Marshal(shape)
+			if err != nil {
+				return err
+			}
+			hit.Geometries = append(hit.Geometries, GeometryValue{Path: doc.PathIris, Geometry: data})
+		}
+	}
+	return nil
+}
+
+// wantsGeometries reports whether the geometries of hits are returned, i.e.
+// they are requested or the query has a spatial criterion.
+func wantsGeometries(query Query) bool {
+	if query.Geometries {
+		return true
+	}
+	for _, criterion := range query.Criteria {
+		switch criterion.Operator {
+		case OperatorGeoIntersects, OperatorGeoWithin, OperatorGeoBBox, OperatorGeoDistance:
+			return true
+		}
+	}
+	return false
+}
+
+// addGeometries adds the geometry values of the hits as GeoJSON.
+func addGeometries(ctx context.Context, hits []SearchHit) error {
+	if len(hits) == 0 {
+		return nil
+	}
+	body, err := postQuery(ctx, buildGeometryRequest(hits))
+	if err != nil {
+		return fmt.Errorf("loading geometries of hits: %w", err)
+	}
+	return parseGeometries(hits, body)
+}
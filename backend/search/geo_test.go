@@ -0,0 +1,167 @@
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"rdf-store-backend/rdf"
+	"rdf-store-backend/shacl"
+	"strings"
+	"testing"
+
+	"github.com/deiu/rdf2go"
+)
+
+func TestGeometriesConvertBetweenWKTAndGeoJSON(t *testing.T) {
+	for _, test := range []struct {
+		wkt     string
+		geoJSON string
+	}{
+		{"POINT (8.65 49.87)", `{"coordinates":[8.65,49.87],"type":"Point"}`},
+		{"MULTIPOINT ((1 2), (3 4))", `{"coordinates":[[1,2],[3,4]],"type":"MultiPoint"}`},
+		{"POLYGON ((0 0, 4 0, 4 4, 0 0), (1 1, 2 1, 2 2, 1 1))", `{"coordinates":[[[0,0],[4,0],[4,4],[0,0]],[[1,1],[2,1],[2,2],[1,1]]],"type":"Polygon"}`},
+		{"GEOMETRYCOLLECTION (POINT (1 2), LINESTRING (0 0, 1 1))", `{"geometries":[{"coordinates":[1,2],"type":"Point"},{"coordinates":[[0,0],[1,1]],"type":"LineString"}],"type":"GeometryCollection"}`},
+	} {
+		shape, err := parseWKT(test.wkt)
+		if err != nil {
+			t.Fatalf("%s: %v", test.wkt, err)
+		}
+		data, err := json.Marshal(shape)
+		if err != nil || string(data) != test.geoJSON {
+			t.Fatalf("%s: unexpected GeoJSON %s", test.wkt, data)
+		}
+		parsed, err := parseGeometry(test.geoJSON)
+		if err != nil || parsed.wkt() != test.wkt {
+			t.Fatalf("%s: unexpected WKT %q (%v)", test.geoJSON, parsed.wkt(), err)
+		}
+	}
+}
+
+func TestParseGeometryAcceptsGeoSPARQLLiterals(t *testing.T) {
+	shape, err := parseWKT("<http://www.opengis.net/def/crs/OGC/1.3/CRS84> Point(8.65 49.87)")
+	if err != nil || shape.wkt() != "POINT (8.65 49.87)" {
+		t.Fatalf("unexpected geometry %q (%v)", shape.wkt(), err)
+	}
+	shape, err = parseGeometryLiteral(`{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]},"properties":{}}`, GeoJSONDataType)
+	if err != nil || shape.wkt() != "POINT (1 2)" {
+		t.Fatalf("unexpected feature geometry %q (%v)", shape.wkt(), err)
+	}
+	for _, invalid := range []string{
+		"<http://www.opengis.net/def/crs/EPSG/0/4326> POINT(1 2)",
+		`POINT(1 2)") OR *:*`,
+		"POINT EMPTY",
+		"POLYGON ((0 0, 1 1))x",
+		"LINESTRING (0 0 0 0, 1 1)",
+		`{"type":"Polygon","coordinates":[[0,0],[1,1]]}`,
+	} {
+		if _, err := parseGeometry(invalid); err == nil {
+			t.Errorf("expected error for %q", invalid)
+		}
+	}
+}
+
+func TestGeoFilterBuildsSpatialQueries(t *testing.T) {
+	for _, test := range []struct {
+		criterion Criterion
+		expected  string
+	}{
+		{Criterion{Operator: OperatorGeoIntersects, Value: `{"type":"Point","coordinates":[1,2]}`}, `valueGeo:"Intersects(POINT (1 2))"`},
+		{Criterion{Operator: OperatorGeoWithin, Value: "POLYGON((0 0, 4 0, 4 4, 0 0))"}, `valueGeo:"IsWithin(POLYGON ((0 0, 4 0, 4 4, 0 0)))"`},
+		{Criterion{Operator: OperatorGeoBBox, Value: "[5.9, 47.3, 15.0, 55.1]"}, `valueGeo:"Intersects(ENVELOPE(5.9, 15, 55.1, 47.3))"`},
+		{Criterion{Operator: OperatorGeoDistance, Value: "POINT(8.65 49.87)", Distance: kilometersPerDegree / 2}, `valueGeo:"Intersects(BUFFER(POINT(8.65 49.87), 0.5))"`},
+	} {
+		filter, err := geoFilter(test.criterion)
+		if err != nil || filter != test.expected {
+			t.Errorf("%s: unexpected filter %q (%v)", test.criterion.Operator, filter, err)
+		}
+	}
+	for _, invalid := range []Criterion{
+		{Operator: OperatorGeoBBox, Value: "0,10,5,5"},
+		{Operator: OperatorGeoBBox, Value: "0,0,5"},
+		{Operator: OperatorGeoDistance, Value: "POINT(1 2)"},
+		{Operator: OperatorGeoDistance, Value: "LINESTRING(0 0, 1 1)", Distance: 1},
+	} {
+		if _, err := geoFilter(invalid); err == nil {
+			t.Errorf("expected error for %+v", invalid)
+		}
+	}
+}
+
+func TestParseGeometriesAddsGeoJSONToHits(t *testing.T) {
+	hits := []SearchHit{{ID: "r|s"}, {ID: "r|t"}}
+	request := buildGeometryRequest(hits)
+	groups := request.Params["group.query"].([]string)
+	if request.Filter[2] != `{!child of=docType:entity}id:("r\|s" OR "r\|t")` || request.Limit != len(hits) ||
+		request.Params["group.limit"] != maxGeometriesPerHit || len(groups) != 2 || groups[0] != hitValuesQuery("r|s") {
+		t.Fatalf("unexpected geometry request %+v", request)
+	}
+	body := `{"grouped":{
+		"{!child of=docType:entity}id:\"r\\|s\"":{"doclist":{"docs":[
+			{"id":"r|s|value|1","pathIris":["http://example.org/location"],"valueGeo":"POINT (1 2)"}]}},
+		"{!child of=docType:entity}id:\"r\\|t\"":{"doclist":{"docs":[
+			{"id":"r|t|value|2","pathIris":["http://example.org/location"],"valueGeo":"POINT (3 4)"}]}}}}`
+	if err := parseGeometries(hits, []byte(body)); err != nil {
+		t.Fatal(err)
+	}
+	if len(hits[0].Geometries) != 1 || string(hits[0].Geometries[0].Geometry) != `{"coordinates":[1,2],"type":"Point"}` ||
+		len(hits[1].Geometries) != 1 || string(hits[1].Geometries[0].Geometry) != `{"coordinates":[3,4],"type":"Point"}` {
+		t.Fatalf("unexpected geometries %+v", hits)
+	}
+}
+
+func TestParseGeometriesLimitsGeometriesPerHit(t *testing.T) {
+	hits := []SearchHit{{ID: "r|s"}}
+	docs := make([]string, 0, maxGeometriesPerHit+5)
+	for i := range maxGeometriesPerHit + 5 {
+		docs = append(docs, fmt.Sprintf(`{"id":"r|s|value|%d","pathIris":["http://example.org/location"],"valueGeo":"POINT (%d 0)"}`, i, i))
+	}
+	body := `{"grouped":{"{!child of=docType:entity}id:\"r\\|s\"":{"doclist":{"docs":[` + strings.Join(docs, ",") + `]}}}}`
+	if err := parseGeometries(hits, []byte(body)); err != nil {
+		t.Fatal(err)
+	}
+	if len(hits[0].Geometries) != maxGeometriesPerHit {
+		t.Fatalf("expected %d geometries, got %d", maxGeometriesPerHit, len(hits[0].Geometries))
+	}
+}
+
+func TestWantsGeometriesForRequestsAndSpatialCriteria(t *testing.T) {
+	for _, tc := range []struct {
+		query Query
+		want  bool
+	}{
+		{Query{}, false},
+		{Query{Criteria: []Criterion{{Path: []string{"http://example.org/name"}, Operator: OperatorContains, Value: "x"}}}, false},
+		{Query{Geometries: true}, true},
+		{Query{Criteria: []Criterion{{Path: []string{"http://example.org/location"}, Operator: OperatorGeoBBox, Value: "0,0,1,1"}}}, true},
+	} {
+		if got := wantsGeometries(tc.query); got != tc.want {
+			t.Errorf("expected %v for %+v, got %v", tc.want, tc.query, got)
+		}
+	}
+}
+
+func TestIndexerConvertsGeoJSONLiteralsToWKT(t *testing.T) {
+	const (
+		siteID   = "http://example.org/Site"
+		location = "http://example.org/location"
+	)
+	site := &shacl.NodeShape{
+		Id: rdf2go.NewResource(siteID), Parents: map[string]bool{}, Alternatives: map[string]bool{},
+		Properties: map[string][]*shacl.Property{location: {{Id: rdf2go.NewResource("urn:property:location"), Path: location, Datatype: GeoJSONDataType}}},
+	}
+	previousProfiles := rdf.Profiles
+	rdf.Profiles = map[string]*shacl.NodeShape{siteID: site}
+	t.Cleanup(func() { rdf.Profiles = previousProfiles })
+
+	subject := rdf2go.NewResource("http://example.org/site")
+	graph := rdf2go.NewGraph("")
+	graph.AddTriple(subject, rdf2go.NewResource(location), rdf2go.NewLiteralWithDatatype(`{"type":"Point","coordinates":[8.65,49.87]}`, rdf2go.NewResource(GeoJSONDataType)))
+	graph.AddTriple(subject, rdf2go.NewResource(location), rdf2go.NewLiteralWithDatatype(`{"type":"Point"}`, rdf2go.NewResource(GeoJSONDataType)))
+	metadata := &rdf.ResourceMetadata{Id: subject, Conformance: map[string][]string{subject.RawValue(): {siteID}}}
+	doc := document{"id": "site"}
+	newQueryIndexer(graph, metadata, siteID, newQueryTraversalState(), nil).index(subject, site, &doc)
+
+	// the malformed geometry is skipped
+	if values := valueChildren(doc, []string{location}, "valueGeo"); len(values) != 1 || values[0] != "POINT (8.65 49.87)" {
+		t.Fatalf("expected the geometry indexed as WKT, got %#v", values)
+	}
+}
@@ -0,0 +1,254 @@
+package search
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"rdf-store-backend/rdf"
+	"slices"
+
+	"github.com/deiu/rdf2go"
+)
+
+// conceptHierarchy maps concepts to their direct broader concepts. Concepts
+// are IRIs in angle brackets, as IRI values are indexed.
+type conceptHierarchy map[string][]string
+
+// ancestors returns the transitive broader concepts of a concept, ordered.
+func (hierarchy conceptHierarchy) ancestors(concept string) []string {
+	seen := map[string]bool{concept: true}
+	result := make([]string, 0)
+	for frontier := []string{concept}; len(frontier) > 0; {
+		next := make([]string, 0)
+		for _, narrower := range frontier {
+			for _, broader := range hierarchy[narrower] {
+				if !seen[broader] {
+					seen[broader] = true
+					result = append(result, broader)
+					next = append(next, broader)
+				}
+			}
+		}
+		frontier = next
+	}
+	slices.Sort(result)
+	return result
+}
+
+// resourceIRIs returns the distinct IRI objects of a resource, which are the
+// candidates for concept expansion.
+func resourceIRIs(resource *rdf2go.Graph) []string {
+	seen := make(map[string]bool)
+	ids := make([]string, 0)
+	for triple := range resource.IterTriples() {
+		if _, ok := triple.Object.(*rdf2go.Resource); ok && !seen[triple.Object.String()] {
+			seen[triple.Object.String()] = true
+			ids = append(ids, triple.Object.String())
+		}
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// appendAncestors adds the broader concepts of an IRI value as value documents
+// flagged as expanded, so criteria on a concept also match its narrower
+// concepts.
+func (indexer *queryIndexer) appendAncestors(parent *document, shapePath []string, concept string) {
+	if len(indexer.traversal.broader) == 0 {
+		return
+	}
+	path := queryPathID(shapePath)
+	for _, ancestor := range indexer.traversal.broader.ancestors(concept) {
+		child := document{
+			"docType":     "value",
+			"resourceId":  (*parent)["resourceId"],
+			"path":        path,
+			"pathIris":    slices.Clone(shapePath),
+			"valueString": ancestor,
+			"expanded":    true,
+		}
+		indexer.addValueDocument(parent, child, path+"\x00expanded\x00"+ancestor)
+	}
+}
+
+// ConceptCount is the number of entities having a concept or one of its
+// narrower concepts at a path. Narrower concepts nest below their broader
+// ones; concepts with several broader concepts appear below each.
+type ConceptCount struct {
+	ValueCount
+	// Direct is the number of entities having the concept itself.
+	Direct   int            `json:"direct"`
+	Narrower []ConceptCount `json:"narrower,omitempty"`
+}
+
+// hierarchyFacet is the Solr facet response of hierarchical value counts.
+type hierarchyFacet struct {
+	Values struct {
+		Buckets []struct {
+			Val      string `json:"val"`
+			Entities int    `json:"entities"`
+			Direct   struct {
+				Entities int `json:"entities"`
+			} `json:"direct"`
+		} `json:"buckets"`
+	} `json:"values"`
+}
+
+// HierarchyFacets counts the entities conforming to a profile per concept at
+// a path, including the entities having narrower concepts, and nests the
+// counts along the concept hierarchy.
+// It returns an error wrapping ErrInvalidQuery for unknown profiles or paths.
+func HierarchyFacets(ctx context.Context, query StatisticsQuery) ([]ConceptCount, error) {
+	path, err := FindProfilePath(query.Profile, query.Path)
+	if err != nil {
+		return nil, err
+	}
+	if query.Limit <= 0 {
+		query.Limit = defaultValueLimit
+	}
+	body, err := postQuery(ctx, buildHierarchyRequest(query.Profile, path, query.Limit))
+	if err != nil {
+		return nil, err
+	}
+	counts, err := parseHierarchyCounts(body)
+	if err != nil {
+		return nil, err
+	}
+	if len(counts) == 0 {
+		return []ConceptCount{}, nil
+	}
+	ids := make([]string, 0, len(counts))
+	for id := range counts {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	hierarchy, err := rdf.GetBroaderConcepts(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("loading concept hierarchy: %w", err)
+	}
+	var labels map[string]string
+	if query.Language == "" {
+		labels, err = rdf.GetDefaultLabels(ctx, ids)
+	} else {
+		labels, err = rdf.GetLabels(ctx, query.Language, ids)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("loading value labels: %w", err)
+	}
+	for id, count := range counts {
+		count.Label = labels[id]
+		counts[id] = count
+	}
+	return buildConceptTree(counts, hierarchy), nil
+}
+
+func buildHierarchyRequest(profile string, path IndexedPath, limit int) map[string]any {
+	values := entityTerms("valueString", limit)
+	values["facet"] = map[string]any{
+		"entities": "uniqueBlock(_root_)",
+		"direct": map[string]any{
+			"type":  "query",
+			"q":     "*:* -expanded:true",
+			"facet": map[string]any{"entities": "uniqueBlock(_root_)"},
+		},
+	}
+	return statisticsRequest(profile, map[string]any{
+		"concepts": map[string]any{
+			"type": "query",
+			"q":    "*:*",
+			// unlike statistics, the domain includes the expanded concepts
+			"domain": map[string]any{"blockChildren": "docType:entity", "filter": []string{"docType:value", fmt.Sprintf(`path:"%s"`, path.ID)}},
+			"facet":  map[string]any{"values": values},
+		},
+	})
+}
+
+// parseHierarchyCounts returns the counts of the IRI values by value.
+func parseHierarchyCounts(body []byte) (map[string]ConceptCount, error) {
+	var payload struct {
+		Facets struct {
+			Concepts hierarchyFacet `json:"concepts"`
+		} `json:"facets"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+	counts := make(map[string]ConceptCount)
+	for _, bucket := range payload.Facets.Concepts.Values.Buckets {
+		if !isIRIValue(bucket.Val) {
+			continue
+		}
+		counts[bucket.Val] = ConceptCount{ValueCount: ValueCount{Value: bucket.Val, Count: bucket.Entities}, Direct: bucket.Direct.Entities}
+	}
+	return counts, nil
+}
+
+func isIRIValue(value string) bool {
+	return len(value) > 1 && value[0] == '<' && value[len(value)-1] == '>'
+}
+
+// buildConceptTree nests the counted concepts below their counted broader
+// concepts. Concepts without a counted broader concept are roots, and so is the
+// lowest concept of each cycle not reachable from them.
+func buildConceptTree(counts map[string]ConceptCount, hierarchy conceptHierarchy) []ConceptCount {
+	narrower := make(map[string][]string)
+	roots := make([]string, 0)
+	for concept := range counts {
+		root := true
+		for _, broader := range hierarchy[concept] {
+			if _, counted := counts[broader]; counted && broader != concept {
+				narrower[broader] = append(narrower[broader], concept)
+				root = false
+			}
+		}
+		if root {
+			roots = append(roots, concept)
+		}
+	}
+	reached := make(map[string]bool, len(counts))
+	var reach func(concept string)
+	reach = func(concept string) {
+		if reached[concept] {
+			return
+		}
+		reached[concept] = true
+		for _, child := range narrower[concept] {
+			reach(child)
+		}
+	}
+	for _, root := range roots {
+		reach(root)
+	}
+	for _, concept := range slices.Sorted(maps.Keys(counts)) {
+		// concepts of a broader cycle are all narrower ones, so none is a root
+		if !reached[concept] {
+			roots = append(roots, concept)
+			reach(concept)
+		}
+	}
+	var build func(concepts []string, ancestors map[string]bool) []ConceptCount
+	build = func(concepts []string, ancestors map[string]bool) []ConceptCount {
+		result := make([]ConceptCount, 0, len(concepts))
+		for _, concept := range concepts {
+			// cyclic hierarchies end where a concept repeats
+			if ancestors[concept] {
+				continue
+			}
+			count := counts[concept]
+			ancestors[concept] = true
+			count.Narrower = build(narrower[concept], ancestors)
+			delete(ancestors, concept)
+			if len(count.Narrower) == 0 {
+				count.Narrower = nil
+			}
+			result = append(result, count)
+		}
+		slices.SortFunc(result, func(a, b ConceptCount) int {
+			return cmp.Or(b.Count-a.Count, cmp.Compare(a.Value, b.Value))
+		})
+		return result
+	}
+	return build(roots, make(map[string]bool))
+}
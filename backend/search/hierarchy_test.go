@@ -0,0 +1,154 @@
+package search
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"rdf-store-backend/rdf"
+	"rdf-store-backend/shacl"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/deiu/rdf2go"
+)
+
+func TestConceptAncestorsFollowTransitiveBroader(t *testing.T) {
+	hierarchy := conceptHierarchy{
+		"<steel>": {"<alloy>", "<metal>"},
+		"<alloy>": {"<material>"},
+		"<metal>": {"<material>"},
+		// cycles end the expansion
+		"<material>": {"<steel>"},
+	}
+	if ancestors := hierarchy.ancestors("<steel>"); !slices.Equal(ancestors, []string{"<alloy>", "<material>", "<metal>"}) {
+		t.Fatalf("unexpected ancestors %v", ancestors)
+	}
+	if ancestors := hierarchy.ancestors("<unknown>"); len(ancestors) != 0 {
+		t.Fatalf("expected no ancestors, got %v", ancestors)
+	}
+}
+
+func TestIndexerExpandsIRIValuesWithBroaderConcepts(t *testing.T) {
+	const (
+		shapeID  = "http://example.org/Part"
+		material = "http://example.org/material"
+	)
+	part := &shacl.NodeShape{
+		Id: rdf2go.NewResource(shapeID), Parents: map[string]bool{}, Alternatives: map[string]bool{},
+		Properties: map[string][]*shacl.Property{material: {{Id: rdf2go.NewResource("urn:property:material"), Path: material}}},
+	}
+	previousProfiles := rdf.Profiles
+	rdf.Profiles = map[string]*shacl.NodeShape{shapeID: part}
+	t.Cleanup(func() { rdf.Profiles = previousProfiles })
+
+	subject := rdf2go.NewResource("http://example.org/part")
+	graph := rdf2go.NewGraph("")
+	graph.AddTriple(subject, rdf2go.NewResource(material), rdf2go.NewResource("http://example.org/steel"))
+	if ids := resourceIRIs(graph); !slices.Equal(ids, []string{"<http://example.org/steel>"}) {
+		t.Fatalf("unexpected expansion candidates %v", ids)
+	}
+	metadata := &rdf.ResourceMetadata{Id: subject, Conformance: map[string][]string{subject.RawValue(): {shapeID}}}
+	traversal := newQueryTraversalState()
+	traversal.broader = conceptHierarchy{"<http://example.org/steel>": {"<http://example.org/metal>"}}
+	doc := document{"id": "part"}
+	newQueryIndexer(graph, metadata, shapeID, traversal, nil).index(subject, part, &doc)
+
+	values := valueChildren(doc, []string{material}, "valueString")
+	if !slices.Equal(values, []any{"<http://example.org/steel>", "<http://example.org/metal>"}) {
+		t.Fatalf("expected the value and its broader concept, got %#v", values)
+	}
+	if expanded := valueChildren(doc, []string{material}, "expanded"); !slices.Equal(expanded, []any{true}) {
+		t.Fatalf("expected only the broader concept flagged, got %#v", expanded)
+	}
+}
+
+func TestCriterionFilterExcludesExpandedConcepts(t *testing.T) {
+	filter, err := criterionFilter(Criterion{Path: []string{"p"}, Operator: OperatorEquals, Value: "http://example.org/metal", Exact: true})
+	if err != nil || !strings.HasSuffix(filter, " AND -expanded:true)") {
+		t.Fatalf("unexpected filter %q (%v)", filter, err)
+	}
+}
+
+func TestBuildConceptTreeNestsNarrowerConcepts(t *testing.T) {
+	counts := map[string]ConceptCount{
+		"<material>": {ValueCount: ValueCount{Value: "<material>", Count: 3}},
+		"<metal>":    {ValueCount: ValueCount{Value: "<metal>", Count: 2}, Direct: 1},
+		"<alloy>":    {ValueCount: ValueCount{Value: "<alloy>", Count: 1}},
+		"<steel>":    {ValueCount: ValueCount{Value: "<steel>", Count: 1}, Direct: 1},
+		"<wood>":     {ValueCount: ValueCount{Value: "<wood>", Count: 1}, Direct: 1},
+	}
+	hierarchy := conceptHierarchy{
+		"<steel>": {"<alloy>", "<metal>"},
+		"<alloy>": {"<material>"},
+		"<metal>": {"<material>"},
+		"<wood>":  {"<plant>"},
+	}
+	tree := buildConceptTree(counts, hierarchy)
+	if len(tree) != 2 || tree[0].Value != "<material>" || tree[1].Value != "<wood>" || tree[1].Narrower != nil {
+		t.Fatalf("unexpected roots %+v", tree)
+	}
+	narrower := tree[0].Narrower
+	if len(narrower) != 2 || narrower[0].Value != "<metal>" || narrower[1].Value != "<alloy>" {
+		t.Fatalf("unexpected narrower concepts %+v", narrower)
+	}
+	// steel has two broader concepts and appears below both
+	if len(narrower[0].Narrower) != 1 || len(narrower[1].Narrower) != 1 || narrower[1].Narrower[0].Value != "<steel>" {
+		t.Fatalf("expected steel below metal and alloy, got %+v", narrower)
+	}
+}
+
+func TestBuildConceptTreeKeepsBroaderCycles(t *testing.T) {
+	counts := map[string]ConceptCount{
+		"<a>":    {ValueCount: ValueCount{Value: "<a>", Count: 2}, Direct: 1},
+		"<b>":    {ValueCount: ValueCount{Value: "<b>", Count: 2}, Direct: 1},
+		"<leaf>": {ValueCount: ValueCount{Value: "<leaf>", Count: 1}, Direct: 1},
+	}
+	hierarchy := conceptHierarchy{
+		"<a>":    {"<b>"},
+		"<b>":    {"<a>"},
+		"<leaf>": {"<b>"},
+	}
+	tree := buildConceptTree(counts, hierarchy)
+	// the lowest concept of the cycle becomes the root
+	if len(tree) != 1 || tree[0].Value != "<a>" {
+		t.Fatalf("expected the cycle rooted at a, got %+v", tree)
+	}
+	narrower := tree[0].Narrower
+	if len(narrower) != 1 || narrower[0].Value != "<b>" {
+		t.Fatalf("expected b below a, got %+v", narrower)
+	}
+	if children := narrower[0].Narrower; len(children) != 1 || children[0].Value != "<leaf>" {
+		t.Fatalf("expected leaf below b without repeating a, got %+v", children)
+	}
+}
+
+func TestHierarchyFacetsCountsExpandedConcepts(t *testing.T) {
+	useStatisticsProfiles(t)
+	var request map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&request)
+		w.Write([]byte(`{"facets":{"concepts":{"count":3,"values":{"buckets":[
+			{"val":"<http://example.org/metal>","count":2,"entities":2,"direct":{"count":1,"entities":1}},
+			{"val":"plain","count":1,"entities":1,"direct":{"count":1,"entities":1}}]}}}}`))
+	}))
+	defer server.Close()
+	previousEndpoint := Endpoint
+	Endpoint = server.URL
+	t.Cleanup(func() { Endpoint = previousEndpoint })
+
+	concepts, err := HierarchyFacets(t.Context(), StatisticsQuery{Profile: sampleID, Path: []string{ownerPath}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(concepts) != 1 || concepts[0].Value != "<http://example.org/metal>" || concepts[0].Count != 2 || concepts[0].Direct != 1 {
+		t.Fatalf("unexpected concepts %+v", concepts)
+	}
+	domain := request["facet"].(map[string]any)["concepts"].(map[string]any)["domain"].(map[string]any)
+	if filter := domain["filter"].([]any); len(filter) != 2 {
+		t.Fatalf("expected the domain to include expanded concepts, got %v", filter)
+	}
+	if _, err := HierarchyFacets(t.Context(), StatisticsQuery{Profile: sampleID, Path: []string{"http://example.org/unknown"}}); err == nil {
+		t.Fatal("expected error for unknown path")
+	}
+}
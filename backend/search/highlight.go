@@ -0,0 +1,119 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// maxHighlightsPerHit bounds the snippets returned for a single hit.
+const maxHighlightsPerHit = 3
+
+// Highlight is a text value snippet explaining why an entity matched, with
+// the matched terms enclosed in <em> tags.
+type Highlight struct {
+	// Path holds the segments of the value's path in the notation of search
+	// criteria.
+	Path    []string `json:"path"`
+	Snippet string   `json:"snippet"`
+}
+
+// buildHighlightRequest selects the text values of the hits that match the
+// full-text term or a contains criterion of the query. Values are grouped by
+// hit, so every hit gets up to maxHighlightsPerHit snippets however many
+// values of other hits match.
+// It returns nil if the query has no text to highlight.
+func buildHighlightRequest(query Query, hits []SearchHit) *searchRequest {
+	clauses := make([]string, 0)
+	if term := strings.TrimSpace(query.Fulltext); term != "" {
+		clauses = append(clauses, textContainsFilter(term))
+	}
+	for _, criterion := range query.Criteria {
+		if value := strings.TrimSpace(criterion.Value); criterion.Operator == OperatorContains && value != "" {
+			clauses = append(clauses, fmt.Sprintf(`(+path:"%s" +%s)`, queryPathID(criterion.Path), textContainsFilter(value)))
+		}
+	}
+	if len(clauses) == 0 || len(hits) == 0 {
+		return nil
+	}
+	ids := make([]string, 0, len(hits))
+	groups := make([]string, 0, len(hits))
+	for _, hit := range hits {
+		ids = append(ids, escapeQueryValue(hit.ID))
+		groups = append(groups, hitValuesQuery(hit.ID))
+	}
+	return &searchRequest{
+		Query: strings.Join(clauses, " OR "),
+		Filter: []string{
+			"docType:value",
+			"{!child of=docType:entity}id:(" + strings.Join(ids, " OR ") + ")",
+		},
+		Sort:   "score desc, id asc",
+		Limit:  len(hits),
+		Fields: []string{"id", "pathIris"},
+		Params: map[string]any{
+			"group":       "true",
+			"group.query": groups,
+			"group.limit": maxHighlightsPerHit,
+			"group.sort":  "score desc, id asc",
+			"hl":          "true",
+			"hl.method":   "unified",
+			"hl.fl":       strings.Join(textFields(), ","),
+			"hl.snippets": "1",
+			"hl.fragsize": "160",
+		},
+	}
+}
+
+// hitValuesQuery matches the value documents of a hit.
+func hitValuesQuery(id string) string {
+	return "{!child of=docType:entity}id:" + escapeQueryValue(id)
+}
+
+// parseHighlights adds the snippets of matching values to their hits.
+func parseHighlights(hits []SearchHit, body []byte) error {
+	type valueDoc struct {
+		ID       string   `json:"id"`
+		PathIris []string `json:"pathIris"`
+	}
+	var payload struct {
+		Grouped map[string]struct {
+			Doclist struct {
+				Docs []valueDoc `json:"docs"`
+			} `json:"doclist"`
+		} `json:"grouped"`
+		Highlighting map[string]map[string][]string `json:"highlighting"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return err
+	}
+	for i := range hits {
+		hit := &hits[i]
+		for _, doc := range payload.Grouped[hitValuesQuery(hit.ID)].Doclist.Docs {
+			if len(hit.Highlights) >= maxHighlightsPerHit {
+				break
+			}
+			for _, field := range textFields() {
+				if snippets := payload.Highlighting[doc.ID][field]; len(snippets) > 0 {
+					hit.Highlights = append(hit.Highlights, Highlight{Path: doc.PathIris, Snippet: snippets[0]})
+					break
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// highlightHits adds snippets of the values matching the query to the hits.
+func highlightHits(ctx context.Context, query Query, hits []SearchHit) error {
+	request := buildHighlightRequest(query, hits)
+	if request == nil {
+		return nil
+	}
+	body, err := postQuery(ctx, request)
+	if err != nil {
+		return fmt.Errorf("highlighting hits: %w", err)
+	}
+	return parseHighlights(hits, body)
+}
@@ -0,0 +1,94 @@
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestBuildHighlightRequestSelectsMatchingValuesOfHits(t *testing.T) {
+	useTextLanguages(t)
+	hits := []SearchHit{{ID: "r|http://example.org/s"}}
+	query := Query{
+		Fulltext: "soil",
+		Criteria: []Criterion{
+			{Path: []string{namePath}, Operator: OperatorContains, Value: "clay"},
+			{Path: []string{massPath}, Operator: OperatorRange, Min: "1"},
+		},
+	}
+	request := buildHighlightRequest(query, hits)
+	if request == nil {
+		t.Fatal("expected highlight request")
+	}
+	if expected := `(valueText:*soil*) OR (+path:"` + queryPathID([]string{namePath}) + `" +(valueText:*clay*))`; request.Query != expected {
+		t.Fatalf("unexpected query %q", request.Query)
+	}
+	if expected := []string{"docType:value", `{!child of=docType:entity}id:("r\|http\:\/\/example.org\/s")`}; !slices.Equal(request.Filter, expected) {
+		t.Fatalf("unexpected filters %q", request.Filter)
+	}
+	if request.Limit != len(hits) || request.Params["hl.fl"] != "valueText" || request.Params["group.limit"] != maxHighlightsPerHit {
+		t.Fatalf("unexpected request %+v", request)
+	}
+	if groups, _ := request.Params["group.query"].([]string); !slices.Equal(groups, []string{`{!child of=docType:entity}id:"r\|http\:\/\/example.org\/s"`}) {
+		t.Fatalf("unexpected group queries %q", request.Params["group.query"])
+	}
+	if buildHighlightRequest(Query{Criteria: query.Criteria[1:]}, hits) != nil {
+		t.Fatal("expected no highlight request without text criteria")
+	}
+}
+
+func TestParseHighlightsAttachesPathsToHits(t *testing.T) {
+	useTextLanguages(t, "de")
+	hits := []SearchHit{{ID: "r|s"}, {ID: "r|t"}}
+	body := []byte(`{"grouped":{
+		"{!child of=docType:entity}id:\"r\\|s\"":{"matches":2,"doclist":{"numFound":2,"docs":[
+			{"id":"r|s|value|1","pathIris":["http://example.org/location","http://www.w3.org/2000/01/rdf-schema#label"]},
+			{"id":"r|s|value|2","pathIris":["http://example.org/title"]}]}},
+		"{!child of=docType:entity}id:\"r\\|t\"":{"matches":0,"doclist":{"numFound":0,"docs":[]}}},
+		"highlighting":{
+			"r|s|value|1":{"valueText":[],"valueText_de":["Bodenprobe <em>Lehm</em>"]},
+			"r|s|value|2":{"valueText":["<em>Lehm</em>"]}}}`)
+	if err := parseHighlights(hits, body); err != nil {
+		t.Fatal(err)
+	}
+	if len(hits[0].Highlights) != 2 || hits[1].Highlights != nil {
+		t.Fatalf("unexpected highlights %+v", hits)
+	}
+	first := hits[0].Highlights[0]
+	if len(first.Path) != 2 || first.Path[0] != "http://example.org/location" || first.Snippet != "Bodenprobe <em>Lehm</em>" {
+		t.Fatalf("unexpected highlight %+v", first)
+	}
+}
+
+func TestParseHighlightsLimitsSnippetsPerHit(t *testing.T) {
+	useTextLanguages(t)
+	hits := []SearchHit{{ID: "r|s"}, {ID: "r|t"}}
+	// the first hit has more matching values than fit in a single page of all hits
+	groups := map[string]any{}
+	highlighting := map[string]any{}
+	for _, hit := range hits {
+		count := 1
+		if hit.ID == "r|s" {
+			count = 3 * len(hits) * maxHighlightsPerHit
+		}
+		docs := make([]map[string]any, 0, count)
+		for i := range count {
+			id := fmt.Sprintf("%s|value|%d", hit.ID, i)
+			docs = append(docs, map[string]any{"id": id, "pathIris": []string{"http://example.org/title"}})
+			highlighting[id] = map[string][]string{"valueText": {"<em>soil</em>"}}
+		}
+		// Solr returns at most group.limit documents per group
+		groups[hitValuesQuery(hit.ID)] = map[string]any{"doclist": map[string]any{"docs": docs[:min(len(docs), maxHighlightsPerHit)]}}
+	}
+	body, err := json.Marshal(map[string]any{"grouped": groups, "highlighting": highlighting})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := parseHighlights(hits, body); err != nil {
+		t.Fatal(err)
+	}
+	if len(hits[0].Highlights) != maxHighlightsPerHit || len(hits[1].Highlights) != 1 {
+		t.Fatalf("expected snippets for every hit, got %d and %d", len(hits[0].Highlights), len(hits[1].Highlights))
+	}
+}
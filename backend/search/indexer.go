@@ -20,49 +20,47 @@ import (
 	"github.com/deiu/rdf2go"
 )
 
-var defaultConversionPredicates = qudt.NewPredicateConfig(
-	base.Configuration.ConversionUnit,
-	base.Configuration.ConversionQuantity,
-	base.Configuration.ConversionValue,
-)
-
 // Init prepares the Solr collection and schema for indexing.
 // It returns an error if Solr cannot be reached or initialized.
-func Init(forceRecreate bool) error {
+func Init(ctx context.Context, forceRecreate bool) error {
 	if forceRecreate {
-		return recreateCollection()
+		return recreateCollection(ctx)
 	}
 	const maxAttempts = 30
 	for attempt := 1; attempt <= maxAttempts; attempt++ {
-		exists, err := checkCollectionExists(context.Background())
+		exists, err := checkCollectionExists(ctx)
 		if err != nil {
 			slog.Warn("solr not ready yet", "attempt", attempt, "max_attempts", maxAttempts, "error", err)
 		} else if exists {
 			return nil
 		} else {
-			return recreateCollection()
+			return recreateCollection(ctx)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
 		}
-		time.Sleep(time.Second)
 	}
 	return fmt.Errorf("solr not ready after %d attempts", maxAttempts)
 }
 
 // Reindex rebuilds the Solr index from all known resources.
-func Reindex() {
+func Reindex(ctx context.Context) {
 	slog.Info("reindexing...")
 	start := time.Now()
-	if err := Init(true); err != nil {
+	if err := Init(ctx, true); err != nil {
 		slog.Error("reindexing failed.", "error", err)
 		return
 	}
-	resourceIds, err := rdf.GetAllResourceIds()
+	resourceIds, err := rdf.GetAllResourceIds(ctx)
 	if err != nil {
 		slog.Error("reindexing failed.", "error", err)
 		return
 	}
 	resourceCount := 0
 	for _, id := range resourceIds {
-		data, metadata, err := rdf.GetResource(id, false)
+		data, metadata, err := rdf.GetResource(ctx, id, false)
 		if err != nil {
 			slog.Error("failed loading resource", "id", id, "error", err)
 		} else {
@@ -70,7 +68,7 @@ func Reindex() {
 			if err != nil {
 				slog.Error(err.Error())
 			} else {
-				if err = IndexResource(graph, metadata); err != nil {
+				if err = IndexResource(ctx, graph, metadata); err != nil {
 					slog.Error("failed indexing resource", "id", id, "error", err)
 				} else {
 					resourceCount = resourceCount + 1
@@ -81,24 +79,161 @@ func Reindex() {
 	slog.Info("reindexing finished", "resources", resourceCount, "duration", time.Since(start))
 }
 
+// ReindexScope selects the resources processed by ReindexScoped. Unset
+// criteria are ignored and set criteria must all match.
+type ReindexScope struct {
+	// Profile selects resources containing an entity that conforms to the profile.
+	Profile string
+	// Since selects resources modified at or after the timestamp.
+	Since time.Time
+	// Resource selects a single resource.
+	Resource string
+}
+
+// ReindexResult summarizes a scoped reindex run.
+type ReindexResult struct {
+	Resources int      `json:"resources"`
+	Indexed   int      `json:"indexed"`
+	Failed    []string `json:"failed"`
+}
+
+// ParseReindexScope builds a scope from textual options. since accepts an
+// RFC 3339 timestamp or a plain date.
+// It returns the scope or an error when since cannot be parsed.
+func ParseReindexScope(profile, since, resource string) (scope ReindexScope, err error) {
+	scope.Profile = strings.TrimSpace(profile)
+	scope.Resource = strings.TrimSpace(resource)
+	since = strings.TrimSpace(since)
+	if since != "" {
+		if scope.Since, err = time.Parse(time.RFC3339, since); err != nil {
+			if scope.Since, err = time.Parse(time.DateOnly, since); err != nil {
+				return scope, fmt.Errorf("invalid timestamp %q, expected RFC 3339 or YYYY-MM-DD", since)
+			}
+		}
+	}
+	return scope, nil
+}
+
+// IsEmpty reports whether the scope sets no criterion.
+func (scope ReindexScope) IsEmpty() bool {
+	return scope.Profile == "" && scope.Resource == "" && scope.Since.IsZero()
+}
+
+// SelectResources resolves the scope to resource IDs.
+// It returns the sorted, de-duplicated IDs and any lookup error.
+func (scope ReindexScope) SelectResources(ctx context.Context) ([]string, error) {
+	var selections [][]string
+	if scope.Resource != "" {
+		selections = append(selections, []string{scope.Resource})
+	}
+	if scope.Profile != "" {
+		ids, err := rdf.FindConformingResources(ctx, scope.Profile)
+		if err != nil {
+			return nil, err
+		}
+		selections = append(selections, ids)
+	}
+	if !scope.Since.IsZero() {
+		ids, err := rdf.FindResourcesModifiedSince(ctx, scope.Since)
+		if err != nil {
+			return nil, err
+		}
+		selections = append(selections, ids)
+	}
+	return intersectIDs(selections), nil
+}
+
+// ReindexScoped rebuilds conformance and search documents of the resources
+// selected by scope. Unlike Reindex, the collection is kept and resources
+// outside the scope are not touched.
+// It returns a summary of the run and any error raised while selecting resources.
+func ReindexScoped(ctx context.Context, scope ReindexScope) (ReindexResult, error) {
+	if scope.IsEmpty() {
+		return ReindexResult{}, fmt.Errorf("reindex scope is empty")
+	}
+	resourceIds, err := scope.SelectResources(ctx)
+	if err != nil {
+		return ReindexResult{}, err
+	}
+	slog.Info("reindexing resources...", "profile", scope.Profile, "since", scope.Since, "resource", scope.Resource, "resources", len(resourceIds))
+	start := time.Now()
+	result := ReindexResources(ctx, resourceIds)
+	slog.Info("reindexing resources finished", "indexed", result.Indexed, "failed", len(result.Failed), "duration", time.Since(start))
+	return result, nil
+}
+
+// ReindexResources rebuilds the conformance metadata of each resource and
+// replaces its search documents. Failures are logged and reported per resource.
+func ReindexResources(ctx context.Context, resourceIds []string) ReindexResult {
+	result := ReindexResult{Resources: len(resourceIds), Failed: make([]string, 0)}
+	for _, resourceId := range resourceIds {
+		slog.Debug("updating metadata and search index for resource", "id", resourceId)
+		metadata, graph, err := rdf.RebuildResourceConformance(ctx, resourceId)
+		if err != nil {
+			slog.Error("failed updating resource metadata", "id", resourceId, "error", err)
+			result.Failed = append(result.Failed, resourceId)
+			continue
+		}
+		if err := IndexResource(ctx, graph, metadata); err != nil {
+			slog.Error("failed updating search index for resource", "id", resourceId, "error", err)
+			result.Failed = append(result.Failed, resourceId)
+			continue
+		}
+		result.Indexed++
+	}
+	return result
+}
+
+// intersectIDs returns the IDs contained in every selection.
+func intersectIDs(selections [][]string) []string {
+	if len(selections) == 0 {
+		return []string{}
+	}
+	counts := make(map[string]int)
+	for _, selection := range selections {
+		seen := make(map[string]bool, len(selection))
+		for _, id := range selection {
+			if !seen[id] {
+				seen[id] = true
+				counts[id]++
+			}
+		}
+	}
+	result := make([]string, 0, len(counts))
+	for id, count := range counts {
+		if count == len(selections) {
+			result = append(result, id)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
 // IndexResource builds and submits search documents for a resource.
 // Every entity conforming to a SHACL shape becomes its own search document.
 // It returns an error when indexing or deindexing fails.
-func IndexResource(resource *rdf2go.Graph, metadata *rdf.ResourceMetadata) error {
+func IndexResource(ctx context.Context, resource *rdf2go.Graph, metadata *rdf.ResourceMetadata) error {
 	labelIDs := make([]string, 0, len(metadata.Conformance))
 	for subjectID := range metadata.Conformance {
 		labelIDs = append(labelIDs, rdf2go.NewResource(subjectID).String())
 	}
-	labels, err := rdf.GetDefaultLabels(labelIDs)
+	labels, err := rdf.GetDefaultLabels(ctx, labelIDs)
 	if err != nil {
 		return fmt.Errorf("loading extracted resource labels: %w", err)
 	}
-	if err := DeindexResource(metadata.Id.RawValue()); err != nil {
+	broader, err := rdf.GetBroaderConcepts(ctx, resourceIRIs(resource))
+	if err != nil {
+		return fmt.Errorf("loading concept hierarchy: %w", err)
+	}
+	if err := DeindexResource(ctx, metadata.Id.RawValue()); err != nil {
 		return err
 	}
+	paths := make(map[string]*document)
 	docs, err := buildResourceDocuments(resource, metadata, resourceIndexOptions{
-		conversionPredicates: defaultConversionPredicates,
+		conversionPredicates: conversionPredicates,
 		extractedLabels:      labels,
+		paths:                paths,
+		broader:              broader,
 	})
 	if err != nil {
 		return err
@@ -106,12 +241,16 @@ func IndexResource(resource *rdf2go.Graph, metadata *rdf.ResourceMetadata) error
 	if len(docs) == 0 {
 		return nil
 	}
-	return updateDocs(docs)
+	return updateDocs(ctx, append(docs, pathDocuments(paths)...))
 }
 
 type resourceIndexOptions struct {
-	conversionPredicates qudt.PredicateConfig
+	conversionPredicates qudt.PredicateSets
 	extractedLabels      map[string]string
+	// paths collects the path registry documents if not nil.
+	paths map[string]*document
+	// broader holds the broader concepts of the IRIs in the resource.
+	broader conceptHierarchy
 }
 
 // buildResourceDocuments creates one Solr document for every entity in the
@@ -173,6 +312,8 @@ func buildResourceDocuments(resource *rdf2go.Graph, metadata *rdf.ResourceMetada
 			visited:   make(map[string]bool),
 			entities:  docsBySubject,
 			valueKeys: valueKeys,
+			paths:     options.paths,
+			broader:   options.broader,
 		}
 		newQueryIndexer(resource, metadata, targetShape, traversal, options.conversionPredicates).index(metadata.Id, profile, rootDoc)
 	}
@@ -198,6 +339,8 @@ func buildResourceDocuments(resource *rdf2go.Graph, metadata *rdf.ResourceMetada
 			visited:   make(map[string]bool),
 			entities:  docsBySubject,
 			valueKeys: valueKeys,
+			paths:     options.paths,
+			broader:   options.broader,
 		}
 		newQueryIndexer(resource, metadata, topShapeID, traversal, options.conversionPredicates).index(rdf2go.NewResource(subjectID), topShape, entityDoc)
 	}
@@ -264,8 +407,8 @@ func collectRootProfileChain(profile *shacl.NodeShape) []string {
 
 // DeindexResource removes all search documents associated with a resource ID.
 // It returns an error if the deletion request fails.
-func DeindexResource(id string) error {
-	return deleteByResourceId(id)
+func DeindexResource(ctx context.Context, id string) error {
+	return deleteByResourceId(ctx, id)
 }
 
 // orderShapesBySpecificity reorders a shape conformance list so that the most
@@ -375,12 +518,11 @@ func buildDocRecursive(subject rdf2go.Term, profile *shacl.NodeShape, resource *
 
 	// append property values to document
 	for path, properties := range profile.Properties {
-		pathTerm := rdf2go.NewResource(path)
 		for _, property := range properties {
-			for _, value := range resource.All(subject, pathTerm, nil) {
-				if property.QualifiedValueShapeDenormalized != nil && conforms(value.Object.RawValue(), property.QualifiedValueShape, metadata) {
-					current.appendValue("_text_", rdf.FindLabels(value.Object, resource))
-					buildDocRecursive(value.Object, property.QualifiedValueShapeDenormalized, resource, metadata, current, active)
+			for _, value := range propertyValues(resource, subject, path, property) {
+				if property.QualifiedValueShapeDenormalized != nil && conforms(value.RawValue(), property.QualifiedValueShape, metadata) {
+					current.appendValue("_text_", rdf.FindLabels(value, resource))
+					buildDocRecursive(value, property.QualifiedValueShapeDenormalized, resource, metadata, current, active)
 				} else if len(property.NodeShapes) > 0 || len(property.AlternativeNodeShapes) > 0 {
 					childShapes := make(map[string]bool, len(property.NodeShapes)+len(property.AlternativeNodeShapes))
 					for shape := range property.NodeShapes {
@@ -390,18 +532,18 @@ func buildDocRecursive(subject rdf2go.Term, profile *shacl.NodeShape, resource *
 						childShapes[shape] = true
 					}
 					for shape := range childShapes {
-						if conforms(value.Object.RawValue(), shape, metadata) {
+						if conforms(value.RawValue(), shape, metadata) {
 							profile, ok := rdf.Profiles[shape]
 							if !ok {
 								slog.Error("profile not found", "id", shape)
 							} else {
-								current.appendValue("_text_", rdf.FindLabels(value.Object, resource))
-								buildDocRecursive(value.Object, profile, resource, metadata, current, active)
+								current.appendValue("_text_", rdf.FindLabels(value, resource))
+								buildDocRecursive(value, profile, resource, metadata, current, active)
 							}
 						}
 					}
 				} else {
-					current.appendValue("_text_", value.Object.RawValue())
+					current.appendValue("_text_", value.RawValue())
 				}
 			}
 		}
@@ -422,6 +564,10 @@ type queryTraversalState struct {
 	visited   map[string]bool
 	entities  map[string]*document
 	valueKeys map[string]map[string]bool
+	// paths collects the path registry documents, if not nil.
+	paths map[string]*document
+	// broader expands IRI values with their broader concepts.
+	broader conceptHierarchy
 }
 
 // queryIndexer owns the dependencies and mutable state shared by one query
@@ -431,9 +577,17 @@ type queryIndexer struct {
 	resource             *rdf2go.Graph
 	metadata             *rdf.ResourceMetadata
 	targetShape          string
+	rootShape            string
 	traversal            *queryTraversalState
-	conversionPredicates qudt.PredicateConfig
-	conversionContexts   map[string]*qudt.QuantityContext
+	conversionPredicates qudt.PredicateSets
+	conversionContexts   map[conversionKey]*qudt.QuantityContext
+}
+
+// conversionKey identifies a scanned measurement node and the quantity
+// predicates declared by its profile.
+type conversionKey struct {
+	subject  string
+	declared qudt.PredicateConfig
 }
 
 type queryIndexNode struct {
@@ -443,6 +597,9 @@ type queryIndexNode struct {
 	shapePath    []string
 	owner        *document
 	conforming   *document
+	// quantityPredicates are the quantity predicates declared by the profile
+	// of the node, which parent and alternative profiles share.
+	quantityPredicates *qudt.PredicateConfig
 }
 
 type queryIndexValue struct {
@@ -451,6 +608,8 @@ type queryIndexValue struct {
 	predicateURI string
 	term         rdf2go.Term
 	quantity     *qudt.QuantityContext
+	// intervalBound marks the start or end of a time interval.
+	intervalBound bool
 }
 
 func newQueryTraversalState() *queryTraversalState {
@@ -461,18 +620,19 @@ func newQueryTraversalState() *queryTraversalState {
 	}
 }
 
-func newQueryIndexer(resource *rdf2go.Graph, metadata *rdf.ResourceMetadata, targetShape string, traversal *queryTraversalState, quantityPredicates qudt.PredicateConfig) *queryIndexer {
+func newQueryIndexer(resource *rdf2go.Graph, metadata *rdf.ResourceMetadata, targetShape string, traversal *queryTraversalState, quantityPredicates qudt.PredicateSets) *queryIndexer {
 	return &queryIndexer{
 		resource:             resource,
 		metadata:             metadata,
 		targetShape:          targetShape,
 		traversal:            traversal,
 		conversionPredicates: quantityPredicates,
-		conversionContexts:   make(map[string]*qudt.QuantityContext),
+		conversionContexts:   make(map[conversionKey]*qudt.QuantityContext),
 	}
 }
 
 func (indexer *queryIndexer) index(subject rdf2go.Term, profile *shacl.NodeShape, current *document) {
+	indexer.rootShape = profile.Id.RawValue()
 	indexer.walk(queryIndexNode{
 		subject:    subject,
 		profile:    profile,
@@ -481,18 +641,35 @@ func (indexer *queryIndexer) index(subject rdf2go.Term, profile *shacl.NodeShape
 	})
 }
 
-func (indexer *queryIndexer) conversionContext(subject rdf2go.Term) *qudt.QuantityContext {
-	key := subject.String()
+// conversionContext scans a node for a quantity value. The quantity
+// predicates declared by the node's profile take precedence over the
+// configured and well-known ones.
+func (indexer *queryIndexer) conversionContext(node queryIndexNode) *qudt.QuantityContext {
+	key := conversionKey{subject: node.subject.String()}
+	if node.quantityPredicates != nil {
+		key.declared = *node.quantityPredicates
+	}
 	if context, scanned := indexer.conversionContexts[key]; scanned {
 		return context
 	}
-	context := indexer.conversionPredicates.ScanConversionContext(subject, indexer.resource)
+	var context *qudt.QuantityContext
+	if node.quantityPredicates != nil {
+		context = node.quantityPredicates.ScanConversionContext(node.subject, indexer.resource)
+	}
+	if context == nil {
+		context = indexer.conversionPredicates.ScanConversionContext(node.subject, indexer.resource)
+	}
 	indexer.conversionContexts[key] = context
 	return context
 }
 
 func (indexer *queryIndexer) walk(node queryIndexNode) {
-	quantity := indexer.conversionContext(node.subject)
+	if node.quantityPredicates == nil {
+		if declared, ok := declaredQuantityPredicates(node.profile, make(map[string]bool)); ok {
+			node.quantityPredicates = &declared
+		}
+	}
+	quantity := indexer.conversionContext(node)
 	activeKey := node.subject.RawValue() + "\x00" + node.profile.Id.RawValue()
 	if indexer.traversal.active[activeKey] {
 		slog.Warn("skipping recursive query-index shape", "subject", node.subject.RawValue(), "shape", node.profile.Id.RawValue())
@@ -521,8 +698,8 @@ func (indexer *queryIndexer) walk(node queryIndexNode) {
 		}
 	}
 
+	intervals := intervalPairs(node.profile)
 	for path, properties := range node.profile.Properties {
-		pathTerm := rdf2go.NewResource(path)
 		for _, property := range properties {
 			nextPropertyPath := appendPath(node.propertyPath, path)
 			shapePathSegment := path
@@ -530,14 +707,15 @@ func (indexer *queryIndexer) walk(node queryIndexNode) {
 				shapePathSegment = property.Id.RawValue()
 			}
 			nextShapePath := appendPath(node.shapePath, shapePathSegment)
-			for _, value := range indexer.resource.All(node.subject, pathTerm, nil) {
+			for _, value := range propertyValues(indexer.resource, node.subject, path, property) {
 				appendTo := func(document *document, quantity *qudt.QuantityContext) {
 					indexer.appendValue(queryIndexValue{
-						document:     document,
-						shapePath:    nextShapePath,
-						predicateURI: path,
-						term:         value.Object,
-						quantity:     quantity,
+						document:      document,
+						shapePath:     nextShapePath,
+						predicateURI:  path,
+						term:          value,
+						quantity:      quantity,
+						intervalBound: intervals.bounds(path),
 					})
 				}
 				childShapes := make(map[string]bool, len(property.NodeShapes)+len(property.AlternativeNodeShapes)+1)
@@ -552,8 +730,8 @@ func (indexer *queryIndexer) walk(node queryIndexNode) {
 				}
 
 				recursed := false
-				childDoc := indexer.traversal.entities[value.Object.RawValue()]
-				if _, literal := value.Object.(*rdf2go.Literal); !literal {
+				childDoc := indexer.traversal.entities[value.RawValue()]
+				if _, literal := value.(*rdf2go.Literal); !literal {
 					// A query field is only reachable by the SHACL query UI when the
 					// document that carries it belongs to a resource whose root profile
 					// chain includes targetShape. Keep a reference to the nearest entity
@@ -562,7 +740,7 @@ func (indexer *queryIndexer) walk(node queryIndexNode) {
 					// property (e.g. owner.firstName) would only match the nested entity
 					// document, which does not conform to the selected shape itself.
 					nextConforming := node.conforming
-					if childDoc != nil && conforms(value.Object.RawValue(), indexer.targetShape, indexer.metadata) {
+					if childDoc != nil && conforms(value.RawValue(), indexer.targetShape, indexer.metadata) {
 						nextConforming = childDoc
 					}
 					if structuredPropertyIsFacet(property, childShapes) {
@@ -570,7 +748,7 @@ func (indexer *queryIndexer) walk(node queryIndexNode) {
 						// over its referenced resources. Index the resource ID at the
 						// relationship path instead of recursing into the nested shape.
 						for shape := range childShapes {
-							if !conforms(value.Object.RawValue(), shape, indexer.metadata) {
+							if !conforms(value.RawValue(), shape, indexer.metadata) {
 								continue
 							}
 							if node.owner != nil {
@@ -585,9 +763,9 @@ func (indexer *queryIndexer) walk(node queryIndexNode) {
 					} else {
 						for shape := range childShapes {
 							child, ok := rdf.Profiles[shape]
-							if ok && conforms(value.Object.RawValue(), shape, indexer.metadata) {
+							if ok && conforms(value.RawValue(), shape, indexer.metadata) {
 								indexer.walk(queryIndexNode{
-									subject:      value.Object,
+									subject:      value,
 									profile:      child,
 									propertyPath: nextPropertyPath,
 									shapePath:    nextShapePath,
@@ -610,6 +788,17 @@ func (indexer *queryIndexer) walk(node queryIndexNode) {
 			}
 		}
 	}
+	indexer.appendIntervals(node, intervals)
+}
+
+// propertyValues evaluates the path of a property from subject. Properties
+// without a parsed path expression are predicate paths of their key.
+func propertyValues(resource *rdf2go.Graph, subject rdf2go.Term, path string, property *shacl.Property) []rdf2go.Term {
+	expression := property.PathExpression
+	if expression == nil {
+		expression = shacl.NewPredicatePath(path)
+	}
+	return expression.Evaluate(subject, resource)
 }
 
 func structuredPropertyIsFacet(property *shacl.Property, childShapes map[string]bool) bool {
@@ -651,6 +840,8 @@ func (indexer *queryIndexer) appendValue(value queryIndexValue) {
 		"docType":    "value",
 		"resourceId": (*value.document)["resourceId"],
 		"path":       path,
+		// the segments are kept to explain hits, as the path ID is a hash
+		"pathIris": slices.Clone(value.shapePath),
 	}
 	if literal, ok := value.term.(*rdf2go.Literal); ok {
 		datatype := ""
@@ -664,52 +855,120 @@ func (indexer *queryIndexer) appendValue(value queryIndexValue) {
 		suffix := datatypeMappings[datatype]
 		switch suffix {
 		case "ds":
+			num, finite, err := numberValue(literal.RawValue(), datatype)
+			if err != nil {
+				slog.Warn("skipping invalid literal", "path", value.shapePath, "value", literal.RawValue(), "datatype", datatype, "error", err)
+				return
+			}
+			if !finite {
+				// INF and NaN are only matched by their lexical value
+				storedValue = literal.RawValue()
+				break
+			}
 			field = "valueNumber"
 			storedValue = literal.RawValue()
 			// Convert to canonical SI unit when quantity context is available.
+			if converted, ok := value.quantity.ConvertNumeric(value.predicateURI, num); ok {
+				storedValue = strconv.FormatFloat(converted, 'f', -1, 64)
+				slog.Debug("converted quantity value", "original", literal.RawValue(), "unit", value.quantity.UnitURI, "canonical", storedValue)
+			}
+			// measurements with uncertainty or bounds also span an interval
 			if value.quantity.ConvertsNumericPredicate(value.predicateURI) {
-				if num, err := strconv.ParseFloat(literal.RawValue(), 64); err == nil {
-					if converted, ok := qudt.Convert(num, value.quantity.UnitURI, value.quantity.QuantityKindURI, value.quantity.IsDelta); ok {
-						storedValue = strconv.FormatFloat(converted, 'f', -1, 64)
-						slog.Debug("converted quantity value", "original", literal.RawValue(), "unit", value.quantity.UnitURI, "canonical", storedValue)
-					}
+				if lower, upper, ok := value.quantity.Interval(num); ok {
+					child["valueNumberMin"] = strconv.FormatFloat(lower, 'f', -1, 64)
+					child["valueNumberMax"] = strconv.FormatFloat(upper, 'f', -1, 64)
 				}
 			}
+		case "dur":
+			seconds, err := durationSeconds(literal.RawValue(), datatype)
+			if err != nil {
+				slog.Warn("skipping invalid literal", "path", value.shapePath, "value", literal.RawValue(), "datatype", datatype, "error", err)
+				return
+			}
+			field = "valueNumber"
+			storedValue = strconv.FormatFloat(seconds, 'f', -1, 64)
+			child["valueString"] = literal.RawValue()
 		case "bs":
+			boolean, err := booleanValue(literal.RawValue())
+			if err != nil {
+				slog.Warn("skipping invalid literal", "path", value.shapePath, "value", literal.RawValue(), "datatype", datatype, "error", err)
+				return
+			}
 			field = "valueBoolean"
-			storedValue = literal.RawValue()
+			storedValue = boolean
 		case "srpt":
 			field = "valueGeo"
-			storedValue = literal.RawValue()
+			shape, err := parseGeometryLiteral(literal.RawValue(), datatype)
+			if err != nil {
+				slog.Warn("skipping invalid geometry", "path", value.shapePath, "value", literal.RawValue(), "error", err)
+				return
+			}
+			storedValue = shape.wkt()
 		case "ss":
 			storedValue = literal.RawValue()
 		case "dts":
-			field = "valueDate"
-			date := literal.RawValue()
-			if len(date) == 10 {
-				date += "T00:00:00Z"
-			} else if !strings.HasSuffix(date, "Z") && !hasTimezoneOffset(date) {
-				date += "Z"
+			date, err := dateValue(literal.RawValue(), datatype)
+			if err != nil {
+				slog.Warn("skipping invalid literal", "path", value.shapePath, "value", literal.RawValue(), "datatype", datatype, "error", err)
+				return
 			}
+			field = "valueDate"
 			storedValue = date
+			// bounds of a time interval are only matched as part of it by
+			// interval criteria
+			if dateRange, err := dateRangeValue(literal.RawValue()); err == nil && !value.intervalBound {
+				child["valueDateRange"] = dateRange
+			}
+		case "dr":
+			dateRange, err := dateRangeValue(literal.RawValue())
+			if err != nil {
+				slog.Warn("skipping invalid partial date", "path", value.shapePath, "value", literal.RawValue(), "error", err)
+				return
+			}
+			field = "valueDateRange"
+			storedValue = dateRange
+			// the lexical value is kept for exact matches and facets
+			child["valueString"] = literal.RawValue()
+		case "tms":
+			instant, err := timeValue(literal.RawValue())
+			if err != nil {
+				slog.Warn("skipping invalid literal", "path", value.shapePath, "value", literal.RawValue(), "datatype", datatype, "error", err)
+				return
+			}
+			field = "valueDate"
+			storedValue = instant
+			child["valueString"] = literal.RawValue()
+		case "html":
+			field = textField(literal.Language)
+			storedValue = htmlText(literal.RawValue())
 		default:
-			field = "valueText"
+			field = textField(literal.Language)
 			storedValue = literal.RawValue()
 		}
 	}
 	child[field] = storedValue
+	datatype, _ := child["datatype"].(string)
+	indexer.registerPath(value.shapePath, path, datatype)
 	// Text literals need analyzed search and exact-value faceting. Keeping both
 	// representations in the same value document avoids another schema field or
 	// a second child document for the same RDF value.
-	if field == "valueText" {
+	if strings.HasPrefix(field, "valueText") {
 		child["valueString"] = storedValue
 	}
 
-	parentID := fmt.Sprint((*value.document)["id"])
-	key := path + "\x00" + field + "\x00" + storedValue
+	indexer.addValueDocument(value.document, child, path+"\x00"+field+"\x00"+storedValue)
+	if _, ok := value.term.(*rdf2go.Resource); ok {
+		indexer.appendAncestors(value.document, value.shapePath, storedValue)
+	}
+}
+
+// addValueDocument adds child as value document of parent, unless a value
+// with the same key was already added to it.
+func (indexer *queryIndexer) addValueDocument(parent *document, child document, key string) {
+	parentID := fmt.Sprint((*parent)["id"])
 	ownerKey := parentID
 	if ownerKey == "<nil>" || ownerKey == "" {
-		ownerKey = fmt.Sprintf("%p", value.document)
+		ownerKey = fmt.Sprintf("%p", parent)
 	}
 	parentKeys := indexer.traversal.valueKeys[ownerKey]
 	if parentKeys == nil {
@@ -722,8 +981,8 @@ func (indexer *queryIndexer) appendValue(value queryIndexValue) {
 	parentKeys[key] = true
 	digest := sha256.Sum256([]byte(parentID + "\x00" + key))
 	child["id"] = parentID + "|value|" + hex.EncodeToString(digest[:16])
-	children, _ := (*value.document)["_childDocuments_"].([]any)
-	(*value.document)["_childDocuments_"] = append(children, child)
+	children, _ := (*parent)["_childDocuments_"].([]any)
+	(*parent)["_childDocuments_"] = append(children, child)
 }
 
 func hasTimezoneOffset(value string) bool {
@@ -1,6 +1,7 @@
 package search
 
 import (
+	"slices"
 	"testing"
 
 	"github.com/deiu/rdf2go"
@@ -148,7 +149,7 @@ func TestBuildQueryDocIndexesNestedInheritedNumericValue(t *testing.T) {
 		childSubject.RawValue(): &childDoc,
 	}
 
-	newQueryIndexer(graph, metadata, rootID, traversal, qudt.PredicateConfig{}).index(rootSubject, root, &rootDoc)
+	newQueryIndexer(graph, metadata, rootID, traversal, nil).index(rootSubject, root, &rootDoc)
 
 	path := []string{childPath, scorePath}
 	if values := valueChildren(childDoc, path, "valueNumber"); len(values) != 1 || values[0] != "12.5" {
@@ -335,6 +336,9 @@ func TestBuildResourceDocumentsIndexesInheritedShapeContexts(t *testing.T) {
 	if values := valueChildren(doc, []string{namePath}, "valueText"); len(values) != 1 || values[0] != "Inherited result" {
 		t.Fatalf("expected inherited root value document, got %#v", values)
 	}
+	if values := valueChildren(doc, []string{namePath}, "pathIris"); len(values) != 1 || !slices.Equal(values[0].([]string), []string{namePath}) {
+		t.Fatalf("expected path IRIs on value document, got %#v", values)
+	}
 }
 
 func TestBuildResourceDocumentsCreatesEntityDocuments(t *testing.T) {
@@ -474,7 +478,7 @@ func TestBuildQueryDocSeparatesQualifiedBranchesWithSameRdfPath(t *testing.T) {
 		timeSubject.RawValue():        &timeDoc,
 	}
 
-	newQueryIndexer(graph, metadata, rootID, traversal, qudt.PredicateConfig{}).index(rootSubject, root, &rootDoc)
+	newQueryIndexer(graph, metadata, rootID, traversal, nil).index(rootSubject, root, &rootDoc)
 
 	temperaturePath := []string{temperatureProp, quantityKindPath}
 	timePath := []string{timeProp, quantityKindPath}
@@ -564,7 +568,7 @@ func TestBuildQueryDocIndexesStructuredDashFacetAsLeaf(t *testing.T) {
 		owner.RawValue():    &ownerDoc,
 	}
 
-	newQueryIndexer(graph, metadata, rootID, traversal, qudt.PredicateConfig{}).index(hardware, root, &hardwareDoc)
+	newQueryIndexer(graph, metadata, rootID, traversal, nil).index(hardware, root, &hardwareDoc)
 
 	// dash:facet marks the structured owner relationship as a leaf facet, so the
 	// owner resource ID is indexed at the [owner] path.
@@ -605,7 +609,7 @@ func TestBuildQueryDocUsesNodeShapeDashFacetAsDefault(t *testing.T) {
 	traversal := newQueryTraversalState()
 	traversal.entities = map[string]*document{hardware.RawValue(): &doc, owner.RawValue(): &ownerDoc}
 
-	newQueryIndexer(graph, metadata, rootID, traversal, qudt.PredicateConfig{}).index(hardware, root, &doc)
+	newQueryIndexer(graph, metadata, rootID, traversal, nil).index(hardware, root, &doc)
 	if values := valueChildren(doc, []string{ownerPath}, "valueString"); len(values) != 1 || values[0] != owner.String() {
 		t.Fatalf("expected node-shape facet value, got %#v", values)
 	}
@@ -673,7 +677,7 @@ func TestBuildQueryDocRecursesUnannotatedStructuredProperty(t *testing.T) {
 		partSubject.RawValue(): &partDoc,
 	}
 
-	newQueryIndexer(graph, metadata, rootID, traversal, qudt.PredicateConfig{}).index(hardware, root, &hardwareDoc)
+	newQueryIndexer(graph, metadata, rootID, traversal, nil).index(hardware, root, &hardwareDoc)
 
 	if values := valueChildren(partDoc, []string{partPath, modelPath}, "valueText"); len(values) != 1 || values[0] != modelValue {
 		t.Fatalf("expected nested part value, got %#v", values)
@@ -698,7 +702,7 @@ func TestBuildQueryDocConvertsQuantityValueToCanonical(t *testing.T) {
 		celsiusUnit           = "http://qudt.org/vocab/unit/DEG_C"
 		temperatureKind       = "http://qudt.org/vocab/quantitykind/Temperature"
 	)
-	quantityPredicates := qudt.NewPredicateConfig(hasUnitPath, hasKindOfQuantityPath, hasNumericalValuePath)
+	quantityPredicates := qudt.PredicateSets{qudt.NewPredicateConfig(hasUnitPath, hasKindOfQuantityPath, hasNumericalValuePath)}
 
 	previousProfiles := rdf.Profiles
 	rdf.Profiles = map[string]*shacl.NodeShape{
@@ -825,7 +829,7 @@ func TestBuildQueryDocConvertsDeltaQuantityWithoutOffset(t *testing.T) {
 		tempDiffKind          = "http://qudt.org/vocab/quantitykind/TemperatureDifference"
 		isDeltaProperty       = "http://qudt.org/schema/qudt#isDeltaQuantity"
 	)
-	quantityPredicates := qudt.NewPredicateConfig(hasUnitPath, hasKindOfQuantityPath, hasNumericalValuePath)
+	quantityPredicates := qudt.PredicateSets{qudt.NewPredicateConfig(hasUnitPath, hasKindOfQuantityPath, hasNumericalValuePath)}
 
 	previousProfiles := rdf.Profiles
 	rdf.Profiles = map[string]*shacl.NodeShape{
@@ -915,3 +919,33 @@ func TestBuildQueryDocConvertsDeltaQuantityWithoutOffset(t *testing.T) {
 		t.Fatalf("expected original unit %s, got %v", celsiusUnit, unitValues[0])
 	}
 }
+
+func TestBuildQueryDocEvaluatesInversePaths(t *testing.T) {
+	const (
+		partID  = "http://example.org/Part"
+		hasPart = "http://example.org/hasPart"
+	)
+	inverse := &shacl.PathExpression{Kind: shacl.InversePath, Elements: []*shacl.PathExpression{shacl.NewPredicatePath(hasPart)}}
+	part := &shacl.NodeShape{
+		Id:           rdf2go.NewResource(partID),
+		Parents:      map[string]bool{},
+		Alternatives: map[string]bool{},
+		Properties: map[string][]*shacl.Property{
+			inverse.Key(): {{Id: rdf2go.NewResource("urn:property:partOf"), Path: inverse.Key(), PathExpression: inverse}},
+		},
+	}
+	previousProfiles := rdf.Profiles
+	rdf.Profiles = map[string]*shacl.NodeShape{partID: part}
+	t.Cleanup(func() { rdf.Profiles = previousProfiles })
+
+	subject := rdf2go.NewResource("http://example.org/part")
+	graph := rdf2go.NewGraph("")
+	graph.AddTriple(rdf2go.NewResource("http://example.org/whole"), rdf2go.NewResource(hasPart), subject)
+	metadata := &rdf.ResourceMetadata{Id: subject, Conformance: map[string][]string{subject.RawValue(): {partID}}}
+	doc := document{"id": "part"}
+	newQueryIndexer(graph, metadata, partID, newQueryTraversalState(), nil).index(subject, part, &doc)
+
+	if values := valueChildren(doc, []string{"^<" + hasPart + ">"}, "valueString"); len(values) != 1 || values[0] != "<http://example.org/whole>" {
+		t.Fatalf("expected the whole indexed at the inverse path, got %#v", values)
+	}
+}
@@ -0,0 +1,262 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"rdf-store-backend/shacl"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/deiu/rdf2go"
+)
+
+// dateRangeLayout formats instants in date ranges. The fixed precision keeps
+// the lexical order of values in line with their time order.
+const dateRangeLayout = "2006-01-02T15:04:05.000Z"
+
+// knownIntervals maps the start predicates of common vocabularies to the
+// predicates of the matching interval ends.
+var knownIntervals = map[string]string{
+	"http://www.w3.org/ns/dcat#startDate":     "http://www.w3.org/ns/dcat#endDate",
+	"http://schema.org/startDate":             "http://schema.org/endDate",
+	"https://schema.org/startDate":            "https://schema.org/endDate",
+	"http://www.w3.org/ns/prov#startedAtTime": "http://www.w3.org/ns/prov#endedAtTime",
+}
+
+// intervalOperations are the DateRangeField operations of interval criteria.
+var intervalOperations = map[string]string{
+	OperatorIntervalOverlaps: "Intersects",
+	OperatorIntervalWithin:   "Within",
+	OperatorIntervalContains: "Contains",
+}
+
+// partialDate matches dates truncated to the year, month or day, with an
+// optional timezone that ranges ignore.
+var partialDate = regexp.MustCompile(`^(\d{4}(?:-\d{2}){0,2})(?:Z|[+-]\d{2}:\d{2})?$`)
+
+var partialDateLayouts = map[int]string{4: "2006", 7: "2006-01", 10: "2006-01-02"}
+
+// dateRangeValue converts a date, partial date or date time to the date range
+// it spans. Partial dates cover their whole year, month or day.
+// It returns an error when the value is not a valid date.
+func dateRangeValue(lexical string) (string, error) {
+	if match := partialDate.FindStringSubmatch(lexical); match != nil {
+		if _, err := time.Parse(partialDateLayouts[len(match[1])], match[1]); err != nil {
+			return "", fmt.Errorf("invalid date %q", lexical)
+		}
+		return match[1], nil
+	}
+	value := lexical
+	if !strings.HasSuffix(value, "Z") && !hasTimezoneOffset(value) {
+		value += "Z"
+	}
+	date, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return "", fmt.Errorf("invalid date %q", lexical)
+	}
+	return date.UTC().Format(dateRangeLayout), nil
+}
+
+// dateInterval returns the date range from start to end, where "*" is open.
+// It returns an error when start is after end.
+func dateInterval(start string, end string) (string, error) {
+	if start != "*" && end != "*" {
+		// truncated values compare on their common precision
+		length := min(len(start), len(end))
+		if start[:length] > end[:length] {
+			return "", fmt.Errorf("interval start %s is after its end %s", start, end)
+		}
+	}
+	return "[" + start + " TO " + end + "]", nil
+}
+
+// intervalFilter builds the value filter of an interval criterion. The
+// criterion interval is either a single (partial) date or spans from min to
+// max, where an empty bound is open. Criteria with a numeric datatype match
+// numbers instead.
+// It returns an error when a date is invalid.
+func intervalFilter(criterion Criterion) (string, error) {
+	if valueField(criterion.Datatype) == "valueNumber" {
+		return numberIntervalFilter(criterion)
+	}
+	var interval string
+	if criterion.Value != "" {
+		value, err := dateRangeValue(criterion.Value)
+		if err != nil {
+			return "", err
+		}
+		interval = value
+	} else {
+		if criterion.Min == "" && criterion.Max == "" {
+			return "", errors.New("missing value, min or max")
+		}
+		bounds := make([]string, 2)
+		for i, bound := range []string{criterion.Min, criterion.Max} {
+			bounds[i] = "*"
+			if bound != "" {
+				value, err := dateRangeValue(bound)
+				if err != nil {
+					return "", err
+				}
+				bounds[i] = value
+			}
+		}
+		value, err := dateInterval(bounds[0], bounds[1])
+		if err != nil {
+			return "", err
+		}
+		interval = value
+	}
+	return fmt.Sprintf(`_query_:"{!field f=valueDateRange op=%s}%s"`, intervalOperations[criterion.Operator], interval), nil
+}
+
+// numberIntervalFilter builds the value filter of an interval criterion on
+// numbers. Quantities with uncertainty or bounds are matched by the interval
+// from valueNumberMin to valueNumberMax, which contains their value, and other
+// numbers by their value. The criterion interval is either a single number or
+// spans from min to max, where an empty bound is open.
+// It returns an error when a number is invalid or a contained interval is
+// open.
+func numberIntervalFilter(criterion Criterion) (string, error) {
+	lower, upper := criterion.Min, criterion.Max
+	if criterion.Value != "" {
+		lower, upper = criterion.Value, criterion.Value
+	}
+	if lower == "" && upper == "" {
+		return "", errors.New("missing value, min or max")
+	}
+	min, err := rangeBound(lower, "valueNumber")
+	if err != nil {
+		return "", err
+	}
+	max, err := rangeBound(upper, "valueNumber")
+	if err != nil {
+		return "", err
+	}
+	switch criterion.Operator {
+	case OperatorIntervalOverlaps:
+		return fmt.Sprintf("(valueNumber:[%[1]s TO %[2]s] OR (valueNumberMin:[* TO %[2]s] AND valueNumberMax:[%[1]s TO *]))", min, max), nil
+	case OperatorIntervalWithin:
+		clauses := []string{fmt.Sprintf("valueNumber:[%s TO %s]", min, max)}
+		if min != "*" {
+			clauses = append(clauses, fmt.Sprintf("-valueNumberMin:{* TO %s}", min))
+		}
+		if max != "*" {
+			clauses = append(clauses, fmt.Sprintf("-valueNumberMax:{%s TO *}", max))
+		}
+		return "(" + strings.Join(clauses, " AND ") + ")", nil
+	}
+	if min == "*" || max == "*" {
+		return "", errors.New("contained interval needs min and max")
+	}
+	return fmt.Sprintf("((valueNumberMin:[* TO %[1]s] OR (valueNumber:[* TO %[1]s] AND -valueNumberMin:*)) AND (valueNumberMax:[%[2]s TO *] OR (valueNumber:[%[2]s TO *] AND -valueNumberMax:*)))", min, max), nil
+}
+
+// timeIntervals maps the start predicates of the time intervals of a node
+// shape to their end predicates.
+type timeIntervals map[string]string
+
+// intervalPairs returns the time intervals of a node shape. They are declared
+// with rdfstore:intervalEnd or are known start and end predicates that the
+// shape both declares.
+func intervalPairs(profile *shacl.NodeShape) timeIntervals {
+	intervals := make(timeIntervals)
+	for path, properties := range profile.Properties {
+		for _, property := range properties {
+			if property.IntervalEnd != "" && property.QualifiedValueShape == "" {
+				intervals[path] = property.IntervalEnd
+			}
+		}
+		if end, ok := knownIntervals[path]; ok && intervals[path] == "" {
+			if _, declared := profile.Properties[end]; declared {
+				intervals[path] = end
+			}
+		}
+	}
+	return intervals
+}
+
+// bounds reports whether path is the start or end of an interval.
+func (intervals timeIntervals) bounds(path string) bool {
+	for start, end := range intervals {
+		if path == start || path == end {
+			return true
+		}
+	}
+	return false
+}
+
+// appendIntervals indexes the time intervals of a node at the path of their
+// start. An interval without start or end is open on that side.
+func (indexer *queryIndexer) appendIntervals(node queryIndexNode, intervals timeIntervals) {
+	for _, start := range slices.Sorted(maps.Keys(intervals)) {
+		starts := indexer.intervalBounds(node, start)
+		ends := indexer.intervalBounds(node, intervals[start])
+		if len(starts) == 0 && len(ends) == 0 {
+			continue
+		}
+		if len(starts) == 0 {
+			starts = []string{"*"}
+		}
+		if len(ends) == 0 {
+			ends = []string{"*"}
+		}
+		shapePath := appendPath(node.shapePath, start)
+		for _, startValue := range starts {
+			for _, endValue := range ends {
+				interval, err := dateInterval(startValue, endValue)
+				if err != nil {
+					slog.Warn("skipping invalid time interval", "path", shapePath, "subject", node.subject.RawValue(), "error", err)
+					continue
+				}
+				if node.owner != nil {
+					indexer.appendInterval(node.owner, shapePath, interval)
+				}
+				if node.conforming != nil && node.conforming != node.owner {
+					indexer.appendInterval(node.conforming, shapePath, interval)
+				}
+			}
+		}
+	}
+}
+
+// intervalBounds returns the date ranges of the date values of a node at
+// path.
+func (indexer *queryIndexer) intervalBounds(node queryIndexNode, path string) []string {
+	expression := shacl.NewPredicatePath(path)
+	if properties := node.profile.Properties[path]; len(properties) > 0 && properties[0].PathExpression != nil {
+		expression = properties[0].PathExpression
+	}
+	bounds := make([]string, 0)
+	for _, value := range expression.Evaluate(node.subject, indexer.resource) {
+		literal, ok := value.(*rdf2go.Literal)
+		if !ok || literal.Datatype == nil {
+			continue
+		}
+		if mapping := datatypeMappings[literal.Datatype.RawValue()]; mapping != "dts" && mapping != "dr" {
+			continue
+		}
+		if bound, err := dateRangeValue(literal.RawValue()); err == nil {
+			bounds = append(bounds, bound)
+		}
+	}
+	return bounds
+}
+
+// appendInterval adds a time interval as value document of parent.
+func (indexer *queryIndexer) appendInterval(parent *document, shapePath []string, interval string) {
+	path := queryPathID(shapePath)
+	indexer.registerPath(shapePath, path, "")
+	child := document{
+		"docType":        "value",
+		"resourceId":     (*parent)["resourceId"],
+		"path":           path,
+		"pathIris":       slices.Clone(shapePath),
+		"valueDateRange": interval,
+	}
+	indexer.addValueDocument(parent, child, path+"\x00valueDateRange\x00"+interval)
+}
@@ -0,0 +1,131 @@
+package search
+
+import (
+	"rdf-store-backend/rdf"
+	"rdf-store-backend/shacl"
+	"slices"
+	"testing"
+
+	"github.com/deiu/rdf2go"
+)
+
+func TestDateRangeValueCoversPartialDates(t *testing.T) {
+	for lexical, expected := range map[string]string{
+		"2024":                      "2024",
+		"2024-05":                   "2024-05",
+		"2024-05Z":                  "2024-05",
+		"2024-05-01+02:00":          "2024-05-01",
+		"2024-05-01T10:30:00+02:00": "2024-05-01T08:30:00.000Z",
+		"2024-05-01T10:30:00.25":    "2024-05-01T10:30:00.250Z",
+	} {
+		if value, err := dateRangeValue(lexical); err != nil || value != expected {
+			t.Errorf("%s: expected %s, got %q (%v)", lexical, expected, value, err)
+		}
+	}
+	for _, invalid := range []string{"24", "2024-13", "2024-02-30", "2024-05-01T25:00:00Z", "2024] OR [*"} {
+		if _, err := dateRangeValue(invalid); err == nil {
+			t.Errorf("expected error for %q", invalid)
+		}
+	}
+}
+
+func TestIntervalFilterBuildsDateRangeQueries(t *testing.T) {
+	for _, test := range []struct {
+		criterion Criterion
+		expected  string
+	}{
+		{Criterion{Operator: OperatorIntervalOverlaps, Value: "2024"}, `_query_:"{!field f=valueDateRange op=Intersects}2024"`},
+		{Criterion{Operator: OperatorIntervalWithin, Min: "2020", Max: "2024-06"}, `_query_:"{!field f=valueDateRange op=Within}[2020 TO 2024-06]"`},
+		{Criterion{Operator: OperatorIntervalContains, Min: "2024-05-01T12:00:00Z"}, `_query_:"{!field f=valueDateRange op=Contains}[2024-05-01T12:00:00.000Z TO *]"`},
+	} {
+		filter, err := intervalFilter(test.criterion)
+		if err != nil || filter != test.expected {
+			t.Errorf("%+v: unexpected filter %q (%v)", test.criterion, filter, err)
+		}
+	}
+	for _, invalid := range []Criterion{
+		{Operator: OperatorIntervalOverlaps},
+		{Operator: OperatorIntervalOverlaps, Min: "2024", Max: "2023"},
+		{Operator: OperatorIntervalWithin, Value: "yesterday"},
+	} {
+		if _, err := intervalFilter(invalid); err == nil {
+			t.Errorf("expected error for %+v", invalid)
+		}
+	}
+}
+
+func TestIntervalFilterMatchesNumberIntervals(t *testing.T) {
+	double := "http://www.w3.org/2001/XMLSchema#double"
+	for _, test := range []struct {
+		criterion Criterion
+		expected  string
+	}{
+		{Criterion{Operator: OperatorIntervalOverlaps, Value: "5", Datatype: double}, `(valueNumber:["5" TO "5"] OR (valueNumberMin:[* TO "5"] AND valueNumberMax:["5" TO *]))`},
+		{Criterion{Operator: OperatorIntervalWithin, Min: "1", Max: "10", Datatype: double}, `(valueNumber:["1" TO "10"] AND -valueNumberMin:{* TO "1"} AND -valueNumberMax:{"10" TO *})`},
+		{Criterion{Operator: OperatorIntervalWithin, Max: "10", Datatype: double}, `(valueNumber:[* TO "10"] AND -valueNumberMax:{"10" TO *})`},
+		{Criterion{Operator: OperatorIntervalContains, Min: "1", Max: "2", Datatype: double}, `((valueNumberMin:[* TO "1"] OR (valueNumber:[* TO "1"] AND -valueNumberMin:*)) AND (valueNumberMax:["2" TO *] OR (valueNumber:["2" TO *] AND -valueNumberMax:*)))`},
+	} {
+		filter, err := intervalFilter(test.criterion)
+		if err != nil || filter != test.expected {
+			t.Errorf("%+v: unexpected filter %q (%v)", test.criterion, filter, err)
+		}
+	}
+	for _, invalid := range []Criterion{
+		{Operator: OperatorIntervalOverlaps, Datatype: double},
+		{Operator: OperatorIntervalWithin, Value: "ten", Datatype: double},
+		{Operator: OperatorIntervalContains, Min: "1", Datatype: double},
+	} {
+		if _, err := intervalFilter(invalid); err == nil {
+			t.Errorf("expected error for %+v", invalid)
+		}
+	}
+}
+
+func TestIndexerPairsIntervalBounds(t *testing.T) {
+	const (
+		projectID  = "http://example.org/Project"
+		startDate  = "http://www.w3.org/ns/dcat#startDate"
+		endDate    = "http://www.w3.org/ns/dcat#endDate"
+		validFrom  = "http://example.org/validFrom"
+		validUntil = "http://example.org/validUntil"
+		founded    = "http://example.org/founded"
+		xsd        = "http://www.w3.org/2001/XMLSchema#"
+	)
+	project := &shacl.NodeShape{
+		Id: rdf2go.NewResource(projectID), Parents: map[string]bool{}, Alternatives: map[string]bool{},
+		Properties: map[string][]*shacl.Property{
+			startDate: {{Id: rdf2go.NewResource("urn:property:start"), Path: startDate}},
+			endDate:   {{Id: rdf2go.NewResource("urn:property:end"), Path: endDate}},
+			validFrom: {{Id: rdf2go.NewResource("urn:property:validFrom"), Path: validFrom, IntervalEnd: validUntil}},
+			founded:   {{Id: rdf2go.NewResource("urn:property:founded"), Path: founded}},
+		},
+	}
+	previousProfiles := rdf.Profiles
+	rdf.Profiles = map[string]*shacl.NodeShape{projectID: project}
+	t.Cleanup(func() { rdf.Profiles = previousProfiles })
+
+	subject := rdf2go.NewResource("http://example.org/project")
+	graph := rdf2go.NewGraph("")
+	graph.AddTriple(subject, rdf2go.NewResource(startDate), rdf2go.NewLiteralWithDatatype("2023-01-15", rdf2go.NewResource(xsd+"date")))
+	graph.AddTriple(subject, rdf2go.NewResource(endDate), rdf2go.NewLiteralWithDatatype("2024-06-30", rdf2go.NewResource(xsd+"date")))
+	graph.AddTriple(subject, rdf2go.NewResource(validFrom), rdf2go.NewLiteralWithDatatype("2024-01-01T00:00:00Z", rdf2go.NewResource(xsd+"dateTime")))
+	graph.AddTriple(subject, rdf2go.NewResource(founded), rdf2go.NewLiteralWithDatatype("1999", rdf2go.NewResource(xsd+"gYear")))
+	metadata := &rdf.ResourceMetadata{Id: subject, Conformance: map[string][]string{subject.RawValue(): {projectID}}}
+	doc := document{"id": "project"}
+	newQueryIndexer(graph, metadata, projectID, newQueryTraversalState(), nil).index(subject, project, &doc)
+
+	if ranges := valueChildren(doc, []string{startDate}, "valueDateRange"); !slices.Equal(ranges, []any{"[2023-01-15 TO 2024-06-30]"}) {
+		t.Fatalf("expected the paired interval only, got %#v", ranges)
+	}
+	if dates := valueChildren(doc, []string{endDate}, "valueDate"); len(dates) != 1 || len(valueChildren(doc, []string{endDate}, "valueDateRange")) != 0 {
+		t.Fatalf("expected interval ends indexed as dates only, got %#v", dates)
+	}
+	// the declared interval has no end value, so it is open
+	if ranges := valueChildren(doc, []string{validFrom}, "valueDateRange"); !slices.Equal(ranges, []any{"[2024-01-01T00:00:00.000Z TO *]"}) {
+		t.Fatalf("expected an open interval, got %#v", ranges)
+	}
+	if ranges := valueChildren(doc, []string{founded}, "valueDateRange"); !slices.Equal(ranges, []any{"1999"}) ||
+		!slices.Equal(valueChildren(doc, []string{founded}, "valueString"), []any{"1999"}) {
+		t.Fatalf("expected the year indexed as range, got %#v", ranges)
+	}
+}
@@ -3,30 +3,60 @@ package search
 import (
 	"fmt"
 	"rdf-store-backend/base"
+	"slices"
+	"strings"
 
 	"github.com/stevenferrer/solr-go"
 )
 
 var prefixXSD = "http://www.w3.org/2001/XMLSchema#%s"
 
+var prefixRDF = "http://www.w3.org/1999/02/22-rdf-syntax-ns#%s"
+
+// datatypeMappings select the indexing of literals by their datatype:
+// t text, ss exact strings, ds numbers, dur durations in seconds, dts dates,
+// tms times of day, dr partial dates as ranges, bs booleans, srpt geometries
+// and html the text of HTML. Other datatypes are indexed as text.
 var datatypeMappings = map[string]string{
-	fmt.Sprintf(prefixXSD, "string"):        "t",
-	fmt.Sprintf(prefixXSD, "integer"):       "ds",
-	fmt.Sprintf(prefixXSD, "int"):           "ds",
-	fmt.Sprintf(prefixXSD, "short"):         "ds",
-	fmt.Sprintf(prefixXSD, "byte"):          "ds",
-	fmt.Sprintf(prefixXSD, "unsignedInt"):   "ds",
-	fmt.Sprintf(prefixXSD, "unsignedShort"): "ds",
-	fmt.Sprintf(prefixXSD, "unsignedByte"):  "ds",
-	fmt.Sprintf(prefixXSD, "long"):          "ds",
-	fmt.Sprintf(prefixXSD, "unsignedLong"):  "ds",
-	fmt.Sprintf(prefixXSD, "float"):         "ds",
-	fmt.Sprintf(prefixXSD, "double"):        "ds",
-	fmt.Sprintf(prefixXSD, "decimal"):       "ds",
-	fmt.Sprintf(prefixXSD, "date"):          "dts",
-	fmt.Sprintf(prefixXSD, "dateTime"):      "dts",
-	fmt.Sprintf(prefixXSD, "boolean"):       "bs",
-	base.Configuration.GeoDataType:          "srpt",
+	fmt.Sprintf(prefixXSD, "string"):             "t",
+	fmt.Sprintf(prefixXSD, "normalizedString"):   "t",
+	fmt.Sprintf(prefixRDF, "langString"):         "t",
+	fmt.Sprintf(prefixXSD, "token"):              "ss",
+	fmt.Sprintf(prefixXSD, "language"):           "ss",
+	fmt.Sprintf(prefixXSD, "anyURI"):             "ss",
+	fmt.Sprintf(prefixXSD, "integer"):            "ds",
+	fmt.Sprintf(prefixXSD, "nonNegativeInteger"): "ds",
+	fmt.Sprintf(prefixXSD, "positiveInteger"):    "ds",
+	fmt.Sprintf(prefixXSD, "nonPositiveInteger"): "ds",
+	fmt.Sprintf(prefixXSD, "negativeInteger"):    "ds",
+	fmt.Sprintf(prefixXSD, "int"):                "ds",
+	fmt.Sprintf(prefixXSD, "short"):              "ds",
+	fmt.Sprintf(prefixXSD, "byte"):               "ds",
+	fmt.Sprintf(prefixXSD, "unsignedInt"):        "ds",
+	fmt.Sprintf(prefixXSD, "unsignedShort"):      "ds",
+	fmt.Sprintf(prefixXSD, "unsignedByte"):       "ds",
+	fmt.Sprintf(prefixXSD, "long"):               "ds",
+	fmt.Sprintf(prefixXSD, "unsignedLong"):       "ds",
+	fmt.Sprintf(prefixXSD, "float"):              "ds",
+	fmt.Sprintf(prefixXSD, "double"):             "ds",
+	fmt.Sprintf(prefixXSD, "decimal"):            "ds",
+	fmt.Sprintf(prefixXSD, "duration"):           "dur",
+	fmt.Sprintf(prefixXSD, "dayTimeDuration"):    "dur",
+	fmt.Sprintf(prefixXSD, "yearMonthDuration"):  "dur",
+	fmt.Sprintf(prefixXSD, "date"):               "dts",
+	fmt.Sprintf(prefixXSD, "dateTime"):           "dts",
+	fmt.Sprintf(prefixXSD, "dateTimeStamp"):      "dts",
+	fmt.Sprintf(prefixXSD, "time"):               "tms",
+	fmt.Sprintf(prefixXSD, "gYear"):              "dr",
+	fmt.Sprintf(prefixXSD, "gYearMonth"):         "dr",
+	// recurring dates have no single range
+	fmt.Sprintf(prefixXSD, "gMonth"):    "ss",
+	fmt.Sprintf(prefixXSD, "gDay"):      "ss",
+	fmt.Sprintf(prefixXSD, "gMonthDay"): "ss",
+	fmt.Sprintf(prefixXSD, "boolean"):   "bs",
+	fmt.Sprintf(prefixRDF, "HTML"):      "html",
+	base.Configuration.GeoDataType:      "srpt",
+	GeoJSONDataType:                     "srpt",
 }
 
 // createCollectionSchema defines the Solr schema fields for the collection.
@@ -36,17 +66,161 @@ func createCollectionSchema() (fields []solr.Field) {
 	fields = append(fields, solr.Field{Name: "subject", Type: "string", Indexed: true, Stored: true, MultiValued: false})
 	fields = append(fields, solr.Field{Name: "docType", Type: "string", Indexed: true, Stored: true, DocValues: true, MultiValued: false})
 	fields = append(fields, solr.Field{Name: "label", Type: "string", Indexed: true, Stored: true, MultiValued: true})
+	fields = append(fields, solr.Field{Name: "labelText", Type: "text_general", Indexed: true, Stored: false, MultiValued: true})
 	fields = append(fields, solr.Field{Name: "shape", Type: "string", Indexed: true, Stored: true, MultiValued: true})
 	fields = append(fields, solr.Field{Name: "creator", Type: "string", Indexed: true, Stored: true, MultiValued: false})
 	fields = append(fields, solr.Field{Name: "lastModified", Type: "pdate", Indexed: true, Stored: true, MultiValued: false})
 	fields = append(fields, solr.Field{Name: "path", Type: "string", Indexed: true, Stored: false, DocValues: true, MultiValued: false})
+	// registration identifies the path and root shape of registry documents
+	fields = append(fields, solr.Field{Name: "registration", Type: "string", Indexed: true, Stored: false, DocValues: true, MultiValued: false})
+	fields = append(fields, solr.Field{Name: "pathIris", Type: "string", Indexed: false, Stored: true, MultiValued: true})
 	fields = append(fields, solr.Field{Name: "valueString", Type: "string", Indexed: true, Stored: false, DocValues: true, MultiValued: false})
-	fields = append(fields, solr.Field{Name: "valueText", Type: "text_general", Indexed: true, Stored: false, MultiValued: false})
+	// text values are stored for highlighting
+	fields = append(fields, solr.Field{Name: "valueText", Type: "text_general", Indexed: true, Stored: true, MultiValued: false})
+	for _, language := range base.Configuration.TextLanguages {
+		fields = append(fields, solr.Field{Name: "valueText_" + language, Type: "text_value_" + language, Indexed: true, Stored: true, MultiValued: false})
+	}
 	fields = append(fields, solr.Field{Name: "valueNumber", Type: "pdouble", Indexed: true, Stored: false, DocValues: true, MultiValued: false})
+	// quantities with uncertainty or bounds are also indexed as intervals
+	fields = append(fields, solr.Field{Name: "valueNumberMin", Type: "pdouble", Indexed: true, Stored: false, MultiValued: false})
+	fields = append(fields, solr.Field{Name: "valueNumberMax", Type: "pdouble", Indexed: true, Stored: false, MultiValued: false})
 	fields = append(fields, solr.Field{Name: "valueDate", Type: "pdate", Indexed: true, Stored: false, DocValues: true, MultiValued: false})
+	// dates, partial dates and time intervals are also indexed as ranges
+	fields = append(fields, solr.Field{Name: "valueDateRange", Type: dateRangeFieldType, Indexed: true, Stored: false, MultiValued: false})
 	fields = append(fields, solr.Field{Name: "valueBoolean", Type: "boolean", Indexed: true, Stored: false, DocValues: true, MultiValued: false})
-	fields = append(fields, solr.Field{Name: "valueGeo", Type: "location_rpt", Indexed: true, Stored: false, MultiValued: false})
+	// geometries are stored to return them as GeoJSON
+	fields = append(fields, solr.Field{Name: "valueGeo", Type: "location_rpt", Indexed: true, Stored: true, MultiValued: false})
+	// expanded marks the broader concepts added to IRI values
+	fields = append(fields, solr.Field{Name: "expanded", Type: "boolean", Indexed: true, Stored: false, MultiValued: false})
 	fields = append(fields, solr.Field{Name: "datatype", Type: "string", Indexed: true, Stored: false, DocValues: true, MultiValued: false})
 	fields = append(fields, solr.Field{Name: "language", Type: "string", Indexed: true, Stored: false, DocValues: true, MultiValued: false})
+	fields = append(fields, solr.Field{Name: "suggest", Type: suggestFieldType, Indexed: true, Stored: false, MultiValued: true})
+	return fields
+}
+
+// languageFilters are the token filters of language-specific text analysis.
+// Languages without an entry are only lowercased.
+var languageFilters = map[string][]map[string]any{
+	"en": {
+		{"class": "solr.EnglishPossessiveFilterFactory"},
+		{"class": "solr.LowerCaseFilterFactory"},
+		{"class": "solr.PorterStemFilterFactory"},
+	},
+	"de": {
+		{"class": "solr.LowerCaseFilterFactory"},
+		{"class": "solr.GermanNormalizationFilterFactory"},
+		{"class": "solr.GermanLightStemFilterFactory"},
+	},
+	"fr": {
+		{"class": "solr.ElisionFilterFactory", "ignoreCase": "true"},
+		{"class": "solr.LowerCaseFilterFactory"},
+		{"class": "solr.FrenchLightStemFilterFactory"},
+	},
+	"es": {
+		{"class": "solr.LowerCaseFilterFactory"},
+		{"class": "solr.SpanishLightStemFilterFactory"},
+	},
+	"it": {
+		{"class": "solr.LowerCaseFilterFactory"},
+		{"class": "solr.ItalianLightStemFilterFactory"},
+	},
+	"nl": {
+		{"class": "solr.LowerCaseFilterFactory"},
+		{"class": "solr.SnowballPorterFilterFactory", "language": "Dutch"},
+	},
+	"pt": {
+		{"class": "solr.LowerCaseFilterFactory"},
+		{"class": "solr.PortugueseLightStemFilterFactory"},
+	},
+}
+
+// createTextFieldTypes defines a Solr field type per text language.
+func createTextFieldTypes() []map[string]any {
+	types := make([]map[string]any, 0, len(base.Configuration.TextLanguages))
+	for _, language := range base.Configuration.TextLanguages {
+		filters, ok := languageFilters[language]
+		if !ok {
+			filters = []map[string]any{{"class": "solr.LowerCaseFilterFactory"}}
+		}
+		types = append(types, map[string]any{
+			"name":                 "text_value_" + language,
+			"class":                "solr.TextField",
+			"positionIncrementGap": "100",
+			"analyzer": map[string]any{
+				"tokenizer": map[string]any{"class": "solr.StandardTokenizerFactory"},
+				"filters":   filters,
+			},
+		})
+	}
+	return types
+}
+
+// textFields returns the fields of text literals: valueText for untagged
+// literals and literals in other languages, followed by the language fields.
+func textFields() []string {
+	fields := []string{"valueText"}
+	for _, language := range base.Configuration.TextLanguages {
+		fields = append(fields, "valueText_"+language)
+	}
 	return fields
 }
+
+// textField returns the field of a text literal with a language tag.
+func textField(languageTag string) string {
+	language, _, _ := strings.Cut(strings.ToLower(languageTag), "-")
+	if language != "" && slices.Contains(base.Configuration.TextLanguages, language) {
+		return "valueText_" + language
+	}
+	return "valueText"
+}
+
+// suggestFieldType indexes the edge n-grams of every word, so that a query
+// term matches all words it is a prefix of.
+const suggestFieldType = "text_suggest"
+
+// dateRangeFieldType is the Solr field type of date ranges.
+const dateRangeFieldType = "date_range"
+
+// createDateRangeFieldType defines the Solr field type of date ranges.
+func createDateRangeFieldType() map[string]any {
+	return map[string]any{"name": dateRangeFieldType, "class": "solr.DateRangeField"}
+}
+
+// maxSuggestGram is the longest indexed prefix. Longer query terms are
+// truncated to it.
+const maxSuggestGram = 25
+
+// createSuggestFieldType defines the Solr field type of the suggest field.
+func createSuggestFieldType() map[string]any {
+	tokenizer := map[string]any{"class": "solr.StandardTokenizerFactory"}
+	return map[string]any{
+		"name":                 suggestFieldType,
+		"class":                "solr.TextField",
+		"positionIncrementGap": "100",
+		"indexAnalyzer": map[string]any{
+			"tokenizer": tokenizer,
+			"filters": []map[string]any{
+				{"class": "solr.LowerCaseFilterFactory"},
+				{"class": "solr.ASCIIFoldingFilterFactory"},
+				{"class": "solr.EdgeNGramFilterFactory", "minGramSize": "1", "maxGramSize": fmt.Sprint(maxSuggestGram)},
+			},
+		},
+		"queryAnalyzer": map[string]any{
+			"tokenizer": tokenizer,
+			"filters": []map[string]any{
+				{"class": "solr.LowerCaseFilterFactory"},
+				{"class": "solr.ASCIIFoldingFilterFactory"},
+				{"class": "solr.TruncateTokenFilterFactory", "prefixLength": fmt.Sprint(maxSuggestGram)},
+			},
+		},
+	}
+}
+
+// suggestCopyFields feeds entity labels and text values into the suggest field.
+func suggestCopyFields() []solr.CopyField {
+	copyFields := []solr.CopyField{{Source: "label", Dest: "suggest"}}
+	for _, field := range textFields() {
+		copyFields = append(copyFields, solr.CopyField{Source: field, Dest: "suggest"})
+	}
+	return copyFields
+}
@@ -3,12 +3,14 @@ package search
 import "testing"
 
 func TestCollectionSchemaUsesFixedValueFields(t *testing.T) {
+	useTextLanguages(t, "en", "de")
 	want := map[string]bool{
 		"resourceId": false, "subject": false, "docType": false,
-		"label": false, "shape": false, "creator": false, "lastModified": false,
-		"path": false, "valueString": false, "valueText": false,
-		"valueNumber": false, "valueDate": false, "valueBoolean": false,
-		"valueGeo": false, "datatype": false, "language": false,
+		"label": false, "labelText": false, "shape": false, "creator": false, "lastModified": false,
+		"path": false, "registration": false, "pathIris": false, "valueString": false, "valueText": false,
+		"valueNumber": false, "valueNumberMin": false, "valueNumberMax": false, "valueDate": false, "valueDateRange": false, "valueBoolean": false,
+		"valueGeo": false, "expanded": false, "datatype": false, "language": false, "suggest": false,
+		"valueText_en": false, "valueText_de": false,
 	}
 	fields := createCollectionSchema()
 	if len(fields) != len(want) {
@@ -28,3 +30,21 @@ func TestCollectionSchemaUsesFixedValueFields(t *testing.T) {
 		}
 	}
 }
+
+func TestTextFieldsFollowLanguageTags(t *testing.T) {
+	useTextLanguages(t, "de", "xx")
+	for tag, expected := range map[string]string{"de": "valueText_de", "DE-at": "valueText_de", "en": "valueText", "": "valueText"} {
+		if field := textField(tag); field != expected {
+			t.Errorf("expected field %q for language %q, got %q", expected, tag, field)
+		}
+	}
+	types := createTextFieldTypes()
+	if len(types) != 2 || types[0]["name"] != "text_value_de" || types[1]["name"] != "text_value_xx" {
+		t.Fatalf("unexpected field types %v", types)
+	}
+	// languages without specific analysis are lowercased only
+	filters := types[1]["analyzer"].(map[string]any)["filters"].([]map[string]any)
+	if len(filters) != 1 || filters[0]["class"] != "solr.LowerCaseFilterFactory" {
+		t.Fatalf("unexpected filters %v", filters)
+	}
+}
@@ -0,0 +1,120 @@
+package search
+
+import (
+	"fmt"
+	"rdf-store-backend/rdf"
+	"rdf-store-backend/shacl"
+	"slices"
+	"strings"
+)
+
+// IndexedPath is a SHACL path of a profile whose values the query indexer
+// stores as value documents.
+type IndexedPath struct {
+	// Path holds the segments in the notation of search criteria.
+	Path []string `json:"path"`
+	// ID is the path identifier stored in the path field of value documents.
+	ID string `json:"id"`
+	// Datatype is the sh:datatype of the leaf property, if any.
+	Datatype string `json:"datatype,omitempty"`
+	// boost is the declared rdfstore:searchBoost of the path, zero if none.
+	boost float64
+}
+
+// ProfilePaths lists the indexed paths of a profile ordered by path. It walks
+// the properties of the profile, its parents, alternatives and referenced
+// shapes like the query indexer.
+// It returns an error wrapping ErrInvalidQuery for unknown profiles.
+func ProfilePaths(profileID string) ([]IndexedPath, error) {
+	profile, ok := rdf.Profiles[profileID]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown profile %q", ErrInvalidQuery, profileID)
+	}
+	byID := make(map[string]IndexedPath)
+	collectProfilePaths(profile, nil, 0, make(map[string]bool), byID)
+	paths := make([]IndexedPath, 0, len(byID))
+	for _, path := range byID {
+		paths = append(paths, path)
+	}
+	slices.SortFunc(paths, func(a, b IndexedPath) int {
+		return strings.Compare(strings.Join(a.Path, "\x00"), strings.Join(b.Path, "\x00"))
+	})
+	return paths, nil
+}
+
+// FindProfilePath returns the indexed path of a profile with the given segments.
+// It returns an error wrapping ErrInvalidQuery if the profile has no such path.
+func FindProfilePath(profileID string, segments []string) (IndexedPath, error) {
+	paths, err := ProfilePaths(profileID)
+	if err != nil {
+		return IndexedPath{}, err
+	}
+	for _, path := range paths {
+		if slices.Equal(path.Path, segments) {
+			return path, nil
+		}
+	}
+	return IndexedPath{}, fmt.Errorf("%w: profile %q has no indexed path %q", ErrInvalidQuery, profileID, segments)
+}
+
+// collectProfilePaths adds the leaf paths below a shape. Shapes already on the
+// current path are skipped, as instances of recursive shapes are only indexed
+// as deep as the data goes. Search boosts apply to all paths below the
+// annotated property unless overridden.
+func collectProfilePaths(shape *shacl.NodeShape, prefix []string, boost float64, active map[string]bool, paths map[string]IndexedPath) {
+	if active[shape.Id.RawValue()] {
+		return
+	}
+	active[shape.Id.RawValue()] = true
+	defer delete(active, shape.Id.RawValue())
+
+	for parentID := range shape.Parents {
+		if parent, ok := rdf.Profiles[parentID]; ok {
+			collectProfilePaths(parent, prefix, boost, active, paths)
+		}
+	}
+	for alternativeID := range shape.Alternatives {
+		if alternative, ok := rdf.Profiles[alternativeID]; ok {
+			collectProfilePaths(alternative, prefix, boost, active, paths)
+		}
+	}
+	for path, properties := range shape.Properties {
+		for _, property := range properties {
+			segment := path
+			if property.QualifiedValueShape != "" {
+				segment = property.Id.RawValue()
+			}
+			shapePath := appendPath(prefix, segment)
+			propertyBoost := boost
+			if property.SearchBoost > 0 {
+				propertyBoost = property.SearchBoost
+			}
+			childShapes := make(map[string]bool, len(property.NodeShapes)+len(property.AlternativeNodeShapes)+1)
+			for id := range property.NodeShapes {
+				childShapes[id] = true
+			}
+			for id := range property.AlternativeNodeShapes {
+				childShapes[id] = true
+			}
+			if property.QualifiedValueShape != "" {
+				childShapes[property.QualifiedValueShape] = true
+			}
+			if len(childShapes) == 0 || structuredPropertyIsFacet(property, childShapes) {
+				id := queryPathID(shapePath)
+				indexed := paths[id]
+				indexed.Path, indexed.ID = shapePath, id
+				if property.Datatype != "" {
+					indexed.Datatype = property.Datatype
+				}
+				indexed.boost = max(indexed.boost, propertyBoost)
+				paths[id] = indexed
+				continue
+			}
+			for id := range childShapes {
+				if child, ok := rdf.Profiles[id]; ok {
+					collectProfilePaths(child, shapePath, propertyBoost, active, paths)
+				}
+			}
+		}
+	}
+}
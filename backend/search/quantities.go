@@ -0,0 +1,130 @@
+package search
+
+import (
+	"errors"
+	"log/slog"
+	"maps"
+	"os"
+	"path"
+	"rdf-store-backend/base"
+	"rdf-store-backend/rdf"
+	"rdf-store-backend/search/qudt"
+	"rdf-store-backend/shacl"
+	"slices"
+)
+
+// QuantityPredicatesFile is the optional JSON file with additional quantity
+// predicate configurations.
+var QuantityPredicatesFile = base.EnvVar("CONVERSION_PREDICATES_FILE", path.Join("local", "conversion-predicates.json"))
+
+// UnitCatalogFiles are the optional unit catalogs merged into the embedded
+// one, in order. JSON files have the format of units.json, other files are
+// Turtle.
+var UnitCatalogFiles = base.EnvVarAsStringSlice("UNIT_CATALOG_FILES", path.Join("local", "unit-catalog.ttl"), path.Join("local", "unit-catalog.json"))
+
+// KnownQuantityPredicates enables the quantity predicates of well-known
+// vocabularies. Changing it requires a reindex.
+var KnownQuantityPredicates = base.EnvVarAsBool("CONVERSION_KNOWN_PREDICATES", false)
+
+// defaultConversionPredicates are the configured quantity predicates followed
+// by the well-known vocabularies, if enabled.
+var defaultConversionPredicates = configuredConversionPredicates(KnownQuantityPredicates)
+
+// conversionPredicates are the quantity predicates used when indexing,
+// including those of the predicates file.
+var conversionPredicates = defaultConversionPredicates
+
+// LoadQuantityPredicates reads additional quantity predicate configurations
+// from file. They take precedence over the configured and well-known ones.
+// The defaults are used alone if the file does not exist.
+// It returns an error if the file cannot be read or is invalid.
+func LoadQuantityPredicates(file string) error {
+	sets, err := qudt.LoadPredicateSets(file)
+	if errors.Is(err, os.ErrNotExist) {
+		conversionPredicates = defaultConversionPredicates
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	conversionPredicates = append(sets, defaultConversionPredicates...)
+	slog.Info("loaded quantity predicates", "file", file, "sets", len(sets))
+	return nil
+}
+
+// configuredConversionPredicates returns the quantity predicates of the
+// configuration, followed by those of the well-known vocabularies if known is
+// set.
+func configuredConversionPredicates(known bool) qudt.PredicateSets {
+	sets := qudt.PredicateSets{qudt.NewPredicateConfig(
+		base.Configuration.ConversionUnit,
+		base.Configuration.ConversionQuantity,
+		base.Configuration.ConversionValue,
+	)}
+	if known {
+		sets = append(sets, qudt.KnownPredicateSets...)
+	}
+	return sets
+}
+
+// QuantityPredicates lists the enabled quantity predicates declared by the
+// profiles, followed by those used for all nodes, without duplicates. Clients
+// need them to convert criteria on quantity values like the indexer does.
+func QuantityPredicates() []base.QuantityPredicates {
+	ids := slices.Sorted(maps.Keys(rdf.Profiles))
+	sets := make(qudt.PredicateSets, 0, len(ids)+len(conversionPredicates))
+	for _, id := range ids {
+		if profile := rdf.Profiles[id]; profile.QuantityUnit != "" && profile.QuantityValue != "" {
+			sets = append(sets, qudt.NewOptionalKindPredicateConfig(profile.QuantityUnit, profile.QuantityKind, profile.QuantityValue))
+		}
+	}
+	sets = append(sets, conversionPredicates...)
+	result := make([]base.QuantityPredicates, 0, len(sets))
+	for _, set := range sets {
+		if !set.Enabled() {
+			continue
+		}
+		unit, quantityKind, value, kindOptional := set.Predicates()
+		predicates := base.QuantityPredicates{Unit: unit, QuantityKind: quantityKind, Value: value, KindOptional: kindOptional}
+		if !slices.Contains(result, predicates) {
+			result = append(result, predicates)
+		}
+	}
+	return result
+}
+
+// LoadUnitCatalogs merges the unit catalogs of files into the active unit
+// catalog. Missing files are skipped.
+// It returns an error if a file cannot be read or is invalid.
+func LoadUnitCatalogs(files []string) error {
+	for _, file := range files {
+		conflicts, err := qudt.LoadCatalog(file)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		slog.Info("loaded unit catalog", "file", file, "conflicts", len(conflicts))
+	}
+	return nil
+}
+
+// declaredQuantityPredicates returns the quantity predicates annotated on a
+// node shape or, failing that, on its parents.
+func declaredQuantityPredicates(profile *shacl.NodeShape, seen map[string]bool) (qudt.PredicateConfig, bool) {
+	if profile.QuantityUnit != "" && profile.QuantityValue != "" {
+		return qudt.NewOptionalKindPredicateConfig(profile.QuantityUnit, profile.QuantityKind, profile.QuantityValue), true
+	}
+	seen[profile.Id.RawValue()] = true
+	parents := profile.ParentList()
+	slices.Sort(parents)
+	for _, parentId := range parents {
+		if parent, ok := rdf.Profiles[parentId]; ok && !seen[parentId] {
+			if config, ok := declaredQuantityPredicates(parent, seen); ok {
+				return config, true
+			}
+		}
+	}
+	return qudt.PredicateConfig{}, false
+}
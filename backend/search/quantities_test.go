@@ -0,0 +1,184 @@
+package search
+
+import (
+	"os"
+	"path/filepath"
+	"rdf-store-backend/base"
+	"rdf-store-backend/rdf"
+	"rdf-store-backend/search/qudt"
+	"rdf-store-backend/shacl"
+	"slices"
+	"testing"
+
+	"github.com/deiu/rdf2go"
+)
+
+func TestIndexerPicksQuantityPredicatesPerNode(t *testing.T) {
+	const (
+		quantityID = "http://example.org/Quantity"
+		readingID  = "http://example.org/Reading"
+		offerID    = "http://example.org/Offer"
+		unit       = "http://example.org/unit"
+		value      = "http://example.org/value"
+		weight     = "http://schema.org/weight"
+		unitCode   = "http://schema.org/unitCode"
+		amount     = "http://schema.org/value"
+		celsius    = "http://qudt.org/vocab/unit/DEG_C"
+		gram       = "http://qudt.org/vocab/unit/GM"
+		double     = "http://www.w3.org/2001/XMLSchema#double"
+	)
+	// the reading inherits the quantity predicates declared by its parent
+	quantity := &shacl.NodeShape{
+		Id: rdf2go.NewResource(quantityID), Parents: map[string]bool{}, Alternatives: map[string]bool{},
+		Properties: map[string][]*shacl.Property{}, QuantityUnit: unit, QuantityValue: value,
+	}
+	reading := &shacl.NodeShape{
+		Id: rdf2go.NewResource(readingID), Parents: map[string]bool{quantityID: true}, Alternatives: map[string]bool{},
+		Properties: map[string][]*shacl.Property{
+			unit:  {{Id: rdf2go.NewResource("urn:property:unit"), Path: unit}},
+			value: {{Id: rdf2go.NewResource("urn:property:value"), Path: value}},
+		},
+	}
+	weightShape := &shacl.NodeShape{
+		Id: rdf2go.NewResource("urn:shape:weight"), Parents: map[string]bool{}, Alternatives: map[string]bool{},
+		Properties: map[string][]*shacl.Property{amount: {{Id: rdf2go.NewResource("urn:property:amount"), Path: amount}}},
+	}
+	offer := &shacl.NodeShape{
+		Id: rdf2go.NewResource(offerID), Parents: map[string]bool{}, Alternatives: map[string]bool{},
+		Properties: map[string][]*shacl.Property{weight: {{Id: rdf2go.NewResource("urn:property:weight"), Path: weight, QualifiedValueShape: "urn:shape:weight", QualifiedValueShapeDenormalized: weightShape}}},
+	}
+	previousProfiles := rdf.Profiles
+	rdf.Profiles = map[string]*shacl.NodeShape{quantityID: quantity, readingID: reading, offerID: offer, "urn:shape:weight": weightShape}
+	t.Cleanup(func() { rdf.Profiles = previousProfiles })
+
+	subject := rdf2go.NewResource("http://example.org/reading")
+	item := rdf2go.NewResource("http://example.org/item")
+	itemWeight := rdf2go.NewBlankNode("weight")
+	graph := rdf2go.NewGraph("")
+	graph.AddTriple(subject, rdf2go.NewResource(unit), rdf2go.NewResource(celsius))
+	graph.AddTriple(subject, rdf2go.NewResource(value), rdf2go.NewLiteralWithDatatype("20", rdf2go.NewResource(double)))
+	graph.AddTriple(item, rdf2go.NewResource(weight), itemWeight)
+	graph.AddTriple(itemWeight, rdf2go.NewResource(unitCode), rdf2go.NewResource(gram))
+	graph.AddTriple(itemWeight, rdf2go.NewResource(amount), rdf2go.NewLiteralWithDatatype("500", rdf2go.NewResource(double)))
+	metadata := &rdf.ResourceMetadata{Id: subject, Conformance: map[string][]string{subject.RawValue(): {readingID}, item.RawValue(): {offerID}, itemWeight.RawValue(): {"urn:shape:weight"}}}
+
+	doc := document{"id": "reading"}
+	newQueryIndexer(graph, metadata, readingID, newQueryTraversalState(), nil).index(subject, reading, &doc)
+	if values := valueChildren(doc, []string{value}, "valueNumber"); !slices.Equal(values, []any{"293.15"}) {
+		t.Fatalf("expected the declared quantity converted to kelvin, got %#v", values)
+	}
+
+	// schema.org quantitative values are detected without configuration
+	doc = document{"id": "item"}
+	newQueryIndexer(graph, metadata, offerID, newQueryTraversalState(), qudt.KnownPredicateSets).index(item, offer, &doc)
+	if values := valueChildren(doc, []string{"urn:property:weight", amount}, "valueNumber"); !slices.Equal(values, []any{"0.5"}) {
+		t.Fatalf("expected the schema.org quantity converted to kilograms, got %#v", values)
+	}
+}
+
+func TestIndexerIndexesQuantityIntervals(t *testing.T) {
+	const (
+		measurementID = "http://example.org/Measurement"
+		qudtUnit      = "http://qudt.org/schema/qudt/unit"
+		numericValue  = "http://qudt.org/schema/qudt/numericValue"
+		uncertainty   = "http://qudt.org/schema/qudt/standardUncertainty"
+		lowerBound    = "http://qudt.org/schema/qudt/lowerBound"
+		double        = "http://www.w3.org/2001/XMLSchema#double"
+	)
+	measurement := &shacl.NodeShape{
+		Id: rdf2go.NewResource(measurementID), Parents: map[string]bool{}, Alternatives: map[string]bool{},
+		Properties: map[string][]*shacl.Property{
+			numericValue: {{Id: rdf2go.NewResource("urn:property:value"), Path: numericValue}},
+			uncertainty:  {{Id: rdf2go.NewResource("urn:property:uncertainty"), Path: uncertainty}},
+		},
+	}
+	previousProfiles := rdf.Profiles
+	rdf.Profiles = map[string]*shacl.NodeShape{measurementID: measurement}
+	t.Cleanup(func() { rdf.Profiles = previousProfiles })
+
+	number := func(value string) rdf2go.Term {
+		return rdf2go.NewLiteralWithDatatype(value, rdf2go.NewResource(double))
+	}
+	subject := rdf2go.NewResource("http://example.org/measurement")
+	graph := rdf2go.NewGraph("")
+	graph.AddTriple(subject, rdf2go.NewResource(qudtUnit), rdf2go.NewResource("http://qudt.org/vocab/unit/DEG_C"))
+	graph.AddTriple(subject, rdf2go.NewResource(numericValue), number("20"))
+	graph.AddTriple(subject, rdf2go.NewResource(uncertainty), number("0.5"))
+	metadata := &rdf.ResourceMetadata{Id: subject, Conformance: map[string][]string{subject.RawValue(): {measurementID}}}
+
+	doc := document{"id": "measurement"}
+	newQueryIndexer(graph, metadata, measurementID, newQueryTraversalState(), qudt.KnownPredicateSets).index(subject, measurement, &doc)
+	lower, upper := valueChildren(doc, []string{numericValue}, "valueNumberMin"), valueChildren(doc, []string{numericValue}, "valueNumberMax")
+	if !slices.Equal(lower, []any{"292.65"}) || !slices.Equal(upper, []any{"293.65"}) {
+		t.Fatalf("expected the uncertainty converted without offset, got %v to %v", lower, upper)
+	}
+	if values := valueChildren(doc, []string{uncertainty}, "valueNumber"); !slices.Equal(values, []any{"0.5"}) {
+		t.Fatalf("expected the uncertainty indexed as difference, got %v", values)
+	}
+
+	// explicit bounds take precedence and are converted with offset
+	graph.AddTriple(subject, rdf2go.NewResource(lowerBound), number("19"))
+	doc = document{"id": "measurement"}
+	newQueryIndexer(graph, metadata, measurementID, newQueryTraversalState(), qudt.KnownPredicateSets).index(subject, measurement, &doc)
+	lower, upper = valueChildren(doc, []string{numericValue}, "valueNumberMin"), valueChildren(doc, []string{numericValue}, "valueNumberMax")
+	if !slices.Equal(lower, []any{"292.15"}) || !slices.Equal(upper, []any{"293.65"}) {
+		t.Fatalf("expected the lower bound in kelvin, got %v to %v", lower, upper)
+	}
+}
+
+func TestLoadQuantityPredicatesPrecedesDefaults(t *testing.T) {
+	t.Cleanup(func() { conversionPredicates = defaultConversionPredicates })
+	file := filepath.Join(t.TempDir(), "conversion-predicates.json")
+	if err := LoadQuantityPredicates(file); err != nil || len(conversionPredicates) != len(defaultConversionPredicates) {
+		t.Fatalf("expected defaults for a missing file, got %d sets (%v)", len(conversionPredicates), err)
+	}
+	if err := os.WriteFile(file, []byte(`[{"unit": "http://example.org/unit", "value": "http://example.org/value"}]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadQuantityPredicates(file); err != nil || len(conversionPredicates) != len(defaultConversionPredicates)+1 {
+		t.Fatalf("expected the file's set before the defaults, got %d sets (%v)", len(conversionPredicates), err)
+	}
+	if err := os.WriteFile(file, []byte(`[{"unit": "http://example.org/unit"}]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadQuantityPredicates(file); err == nil {
+		t.Fatal("expected error for a set without value predicate")
+	}
+}
+
+func TestConfiguredConversionPredicatesKnownOptIn(t *testing.T) {
+	if sets := configuredConversionPredicates(false); len(sets) != 1 {
+		t.Fatalf("expected only the configured set without opt-in, got %d sets", len(sets))
+	}
+	if sets := configuredConversionPredicates(true); len(sets) != 1+len(qudt.KnownPredicateSets) {
+		t.Fatalf("expected the well-known sets after the configured one, got %d sets", len(sets))
+	}
+}
+
+func TestQuantityPredicatesListsProfileAndActiveSets(t *testing.T) {
+	const (
+		unit  = "http://example.org/unit"
+		value = "http://example.org/value"
+	)
+	quantity := &shacl.NodeShape{
+		Id: rdf2go.NewResource("http://example.org/Quantity"), Parents: map[string]bool{}, Alternatives: map[string]bool{},
+		Properties: map[string][]*shacl.Property{}, QuantityUnit: unit, QuantityValue: value,
+	}
+	previousProfiles, previousPredicates := rdf.Profiles, conversionPredicates
+	rdf.Profiles = map[string]*shacl.NodeShape{quantity.Id.RawValue(): quantity}
+	// the file repeats the profile's set, and the configured set is disabled
+	conversionPredicates = qudt.PredicateSets{
+		qudt.NewOptionalKindPredicateConfig(unit, "", value),
+		qudt.NewPredicateConfig("", "", ""),
+		qudt.KnownPredicateSets[0],
+	}
+	t.Cleanup(func() { rdf.Profiles, conversionPredicates = previousProfiles, previousPredicates })
+
+	expected := []base.QuantityPredicates{
+		{Unit: unit, Value: value, KindOptional: true},
+		{Unit: "http://qudt.org/schema/qudt/unit", QuantityKind: "http://qudt.org/schema/qudt/hasQuantityKind", Value: "http://qudt.org/schema/qudt/numericValue", KindOptional: true},
+	}
+	if predicates := QuantityPredicates(); !slices.Equal(predicates, expected) {
+		t.Fatalf("expected %v, got %v", expected, predicates)
+	}
+}
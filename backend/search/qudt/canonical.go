@@ -13,6 +13,9 @@ type CatalogSourceUnit struct {
 	SIApplicable      bool
 	Scaled            bool
 	SIExactMatch      bool
+	Symbol            string
+	// Labels are the unit labels by language tag.
+	Labels map[string]string
 }
 
 // Conversion contains the data needed to convert a unit at runtime.
@@ -20,11 +23,16 @@ type UnitInfo struct {
 	Multiplier      float64 `json:"multiplier"`
 	Offset          float64 `json:"offset"`
 	DimensionVector string  `json:"dimensionVector"`
+	Symbol          string  `json:"symbol,omitempty"`
+	// Labels are the unit labels by language tag, untagged labels under "".
+	Labels map[string]string `json:"labels,omitempty"`
 }
 
 // Catalog is the generated, index-focused representation embedded by the
 // runtime package.
 type Catalog struct {
+	// Version names the QUDT release or custom catalog the units stem from.
+	Version        string               `json:"version,omitempty"`
 	Units          map[string]*UnitInfo `json:"units"`
 	CanonicalUnits map[string]string    `json:"canonicalUnits"`
 }
@@ -50,6 +58,8 @@ func BuildCatalog(source map[string]*CatalogSourceUnit, parents map[string][]str
 			Multiplier:      unit.Multiplier,
 			Offset:          unit.Offset,
 			DimensionVector: unit.DimensionVector,
+			Symbol:          unit.Symbol,
+			Labels:          unit.Labels,
 		}
 	}
 
@@ -0,0 +1,212 @@
+package qudt
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/deiu/rdf2go"
+)
+
+// embeddedCatalogVersion is the QUDT release units.json was generated from,
+// for catalogs written before the version was recorded.
+const embeddedCatalogVersion = "QUDT 3.5.0"
+
+var (
+	owlVersionInfo = rdf2go.NewResource("http://www.w3.org/2002/07/owl#versionInfo")
+	owlOntology    = rdf2go.NewResource("http://www.w3.org/2002/07/owl#Ontology")
+	rdfType        = rdf2go.NewResource("http://www.w3.org/1999/02/22-rdf-syntax-ns#type")
+)
+
+var (
+	// catalogVersions are the versions of the merged catalogs, the embedded
+	// one first.
+	catalogVersions []string
+	// catalogConflicts are the units and quantity kinds that merged catalogs
+	// redefined.
+	catalogConflicts []CatalogConflict
+)
+
+// CatalogConflict is a unit or canonical unit of a quantity kind that a
+// merged catalog redefined. The merged catalog's definition wins.
+type CatalogConflict struct {
+	Catalog      string `json:"catalog"`
+	Unit         string `json:"unit,omitempty"`
+	QuantityKind string `json:"quantityKind,omitempty"`
+	Existing     string `json:"existing"`
+	Replacement  string `json:"replacement"`
+}
+
+// CatalogStatus describes the active unit catalog.
+type CatalogStatus struct {
+	Versions      []string          `json:"versions"`
+	Units         int               `json:"units"`
+	QuantityKinds int               `json:"quantityKinds"`
+	Conflicts     []CatalogConflict `json:"conflicts"`
+}
+
+// ActiveCatalog returns the versions, size and merge conflicts of the active
+// unit catalog.
+func ActiveCatalog() CatalogStatus {
+	conflicts := slices.Clone(catalogConflicts)
+	if conflicts == nil {
+		conflicts = []CatalogConflict{}
+	}
+	return CatalogStatus{
+		Versions:      slices.Clone(catalogVersions),
+		Units:         len(units),
+		QuantityKinds: len(canonicalByQuantityKind),
+		Conflicts:     conflicts,
+	}
+}
+
+// LoadCatalog reads a unit catalog and merges it into the active catalog.
+// JSON files have the format of units.json. Turtle files hold QUDT units and
+// quantity kinds, either a QUDT release or custom units, and run through
+// BuildCatalog; their units may refer to the canonical units of the active
+// catalog. The version is the catalog's version, the owl:versionInfo of a
+// Turtle ontology or else the file name.
+// It is not safe for concurrent use with conversions and must be called
+// before serving requests.
+// It returns the conflicts of the merge, or an error if the file cannot be
+// read or is invalid.
+func LoadCatalog(file string) ([]CatalogConflict, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var catalog Catalog
+	if strings.EqualFold(filepath.Ext(file), ".json") {
+		if err := json.Unmarshal(data, &catalog); err != nil {
+			return nil, fmt.Errorf("parsing unit catalog %s: %w", file, err)
+		}
+	} else {
+		graph := rdf2go.NewGraph("")
+		if err := graph.Parse(bytes.NewReader(data), "text/turtle"); err != nil {
+			return nil, fmt.Errorf("parsing unit catalog %s: %w", file, err)
+		}
+		catalog = extendCatalog(SourceUnits(graph))
+		catalog.Version = ontologyVersion(graph)
+	}
+	if catalog.Version == "" {
+		catalog.Version = filepath.Base(file)
+	}
+	if err := validateCatalog(catalog); err != nil {
+		return nil, fmt.Errorf("invalid unit catalog %s: %w", file, err)
+	}
+	return MergeCatalog(catalog), nil
+}
+
+// extendCatalog builds a catalog like BuildCatalog, additionally resolving
+// quantity kinds and units through the canonical units of the active catalog.
+func extendCatalog(source map[string]*CatalogSourceUnit, parents map[string][]string) Catalog {
+	catalog := BuildCatalog(source, parents)
+	canonical := maps.Clone(canonicalByQuantityKind)
+	maps.Copy(canonical, catalog.CanonicalUnits)
+	for _, unit := range source {
+		for _, quantityKind := range unit.QuantityKinds {
+			if canonical[quantityKind] == "" {
+				if uri := nearestCanonicalAncestor(quantityKind, canonical, parents); uri != "" {
+					catalog.CanonicalUnits[quantityKind] = uri
+					canonical[quantityKind] = uri
+				}
+			}
+		}
+	}
+	for uri, unit := range source {
+		if catalog.Units[uri] != nil || unit.Multiplier == 0 || unit.DimensionVector == "" {
+			continue
+		}
+		for _, quantityKind := range unit.QuantityKinds {
+			target := catalog.Units[canonical[quantityKind]]
+			if target == nil {
+				target = units[canonical[quantityKind]]
+			}
+			if target != nil && target.DimensionVector == unit.DimensionVector {
+				catalog.Units[uri] = &UnitInfo{
+					Multiplier:      unit.Multiplier,
+					Offset:          unit.Offset,
+					DimensionVector: unit.DimensionVector,
+					Symbol:          unit.Symbol,
+					Labels:          unit.Labels,
+				}
+				break
+			}
+		}
+	}
+	return catalog
+}
+
+// ontologyVersion returns the owl:versionInfo of the ontology in a graph.
+func ontologyVersion(graph *rdf2go.Graph) string {
+	for _, ontology := range graph.All(nil, rdfType, owlOntology) {
+		if version := graph.One(ontology.Subject, owlVersionInfo, nil); version != nil {
+			return version.Object.RawValue()
+		}
+	}
+	return ""
+}
+
+// validateCatalog checks that units can convert and canonical units are
+// known to the catalog or the active catalog.
+func validateCatalog(catalog Catalog) error {
+	for uri, info := range catalog.Units {
+		if info == nil || info.Multiplier == 0 || info.DimensionVector == "" {
+			return fmt.Errorf("unit %s needs a multiplier and dimension vector", uri)
+		}
+	}
+	for quantityKind, uri := range catalog.CanonicalUnits {
+		if catalog.Units[uri] == nil && units[uri] == nil {
+			return fmt.Errorf("canonical unit %s of %s is unknown", uri, quantityKind)
+		}
+	}
+	return nil
+}
+
+// MergeCatalog merges a catalog into the active catalog. Its units and
+// canonical units replace existing ones, which are reported as conflicts if
+// their conversion differs. Symbols and labels missing from replacing units
+// are kept.
+// It is not safe for concurrent use with conversions.
+func MergeCatalog(catalog Catalog) []CatalogConflict {
+	conflicts := make([]CatalogConflict, 0)
+	for _, uri := range slices.Sorted(maps.Keys(catalog.Units)) {
+		info := *catalog.Units[uri]
+		if existing := units[uri]; existing != nil {
+			if existing.Multiplier != info.Multiplier || existing.Offset != info.Offset || existing.DimensionVector != info.DimensionVector {
+				conflicts = append(conflicts, CatalogConflict{Catalog: catalog.Version, Unit: uri, Existing: existing.describe(), Replacement: info.describe()})
+			}
+			if info.Symbol == "" {
+				info.Symbol = existing.Symbol
+			}
+			if len(info.Labels) == 0 {
+				info.Labels = existing.Labels
+			}
+		}
+		units[uri] = &info
+	}
+	for _, quantityKind := range slices.Sorted(maps.Keys(catalog.CanonicalUnits)) {
+		uri := catalog.CanonicalUnits[quantityKind]
+		if existing := canonicalByQuantityKind[quantityKind]; existing != "" && existing != uri {
+			conflicts = append(conflicts, CatalogConflict{Catalog: catalog.Version, QuantityKind: quantityKind, Existing: existing, Replacement: uri})
+		}
+		canonicalByQuantityKind[quantityKind] = uri
+	}
+	canonicalByDimension = dimensionCanonicalUnits(units, canonicalByQuantityKind)
+	catalogVersions = append(catalogVersions, catalog.Version)
+	catalogConflicts = append(catalogConflicts, conflicts...)
+	if len(conflicts) > 0 {
+		slog.Warn("unit catalog redefines units", "catalog", catalog.Version, "conflicts", len(conflicts))
+	}
+	return conflicts
+}
+
+func (info UnitInfo) describe() string {
+	return fmt.Sprintf("multiplier %g, offset %g, dimension vector %s", info.Multiplier, info.Offset, info.DimensionVector)
+}
@@ -0,0 +1,108 @@
+package qudt
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// useActiveCatalog restores the active catalog after a test merged into it.
+func useActiveCatalog(t *testing.T) {
+	savedUnits, savedCanonical := maps.Clone(units), maps.Clone(canonicalByQuantityKind)
+	savedVersions, savedConflicts := slices.Clone(catalogVersions), slices.Clone(catalogConflicts)
+	t.Cleanup(func() {
+		units, canonicalByQuantityKind = savedUnits, savedCanonical
+		canonicalByDimension = dimensionCanonicalUnits(units, canonicalByQuantityKind)
+		catalogVersions, catalogConflicts = savedVersions, savedConflicts
+	})
+}
+
+func TestLoadCatalogMergesCustomTurtleUnits(t *testing.T) {
+	useActiveCatalog(t)
+	file := filepath.Join(t.TempDir(), "lab-units.ttl")
+	data := `
+@prefix owl: <http://www.w3.org/2002/07/owl#> .
+@prefix qudt: <http://qudt.org/schema/qudt/> .
+@prefix rdfs: <http://www.w3.org/2000/01/rdf-schema#> .
+@prefix unit: <http://qudt.org/vocab/unit/> .
+@prefix qk: <http://qudt.org/vocab/quantitykind/> .
+@prefix qkdv: <http://qudt.org/vocab/dimensionvector/> .
+@prefix lab: <http://example.org/unit/> .
+<http://example.org/units> a owl:Ontology ; owl:versionInfo "lab units 1" .
+lab:CountsPerMinute qudt:conversionMultiplier 0.016666666666666666 ;
+  qudt:hasDimensionVector qkdv:A0E0L0I0M0H0T-1D0 ;
+  qudt:hasQuantityKind qk:Frequency ;
+  qudt:symbol "cpm" ;
+  rdfs:label "counts per minute"@en .
+unit:FT qudt:conversionMultiplier 0.3 ;
+  qudt:hasDimensionVector qkdv:A0E0L1I0M0H0T0D0 ;
+  qudt:hasQuantityKind qk:Length .
+`
+	if err := os.WriteFile(file, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	conflicts, err := LoadCatalog(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(conflicts) != 1 || conflicts[0].Unit != "http://qudt.org/vocab/unit/FT" || conflicts[0].Catalog != "lab units 1" {
+		t.Fatalf("expected the redefined foot as conflict, got %+v", conflicts)
+	}
+	if got, ok := Convert(60, "http://example.org/unit/CountsPerMinute", "http://qudt.org/vocab/quantitykind/Frequency", false); !ok || !ApproxEquals(got, 1, 1e-9) {
+		t.Errorf("Convert(60 cpm) = %v, %v, want 1 Hz", got, ok)
+	}
+	if info := Unit("http://example.org/unit/CountsPerMinute"); info == nil || info.Symbol != "cpm" || info.Label("en") != "counts per minute" {
+		t.Errorf("unexpected custom unit %+v", info)
+	}
+	// the redefined foot replaces the embedded one
+	if got, _ := ConvertTo(10, "http://qudt.org/vocab/unit/FT", "http://qudt.org/vocab/unit/M", false); !ApproxEquals(got, 3, 1e-9) {
+		t.Errorf("ConvertTo(10 FT) = %v, want 3 m", got)
+	}
+	status := ActiveCatalog()
+	if !slices.Equal(status.Versions, []string{embeddedCatalogVersion, "lab units 1"}) || len(status.Conflicts) != 1 {
+		t.Fatalf("unexpected catalog status %+v", status)
+	}
+}
+
+func TestLoadCatalogRejectsInvalidJSONCatalogs(t *testing.T) {
+	useActiveCatalog(t)
+	dir := t.TempDir()
+	for name, data := range map[string]string{
+		"zero.json":    `{"units": {"http://example.org/unit/X": {"multiplier": 0, "dimensionVector": "A0E0L1I0M0H0T0D0"}}}`,
+		"unknown.json": `{"canonicalUnits": {"http://example.org/kind": "http://example.org/unit/Unknown"}}`,
+	} {
+		file := filepath.Join(dir, name)
+		if err := os.WriteFile(file, []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadCatalog(file); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+	file := filepath.Join(dir, "valid.json")
+	if err := os.WriteFile(file, []byte(`{"units": {"http://example.org/unit/Span": {"multiplier": 0.2286, "dimensionVector": "A0E0L1I0M0H0T0D0"}}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadCatalog(file); err != nil {
+		t.Fatal(err)
+	}
+	if cu := CanonicalUnitURI("http://example.org/unit/Span", "http://qudt.org/vocab/quantitykind/Length"); cu != "http://qudt.org/vocab/unit/M" {
+		t.Errorf("CanonicalUnitURI(Span) = %v, want M", cu)
+	}
+	if versions := ActiveCatalog().Versions; versions[len(versions)-1] != "valid.json" {
+		t.Errorf("expected the file name as version, got %v", versions)
+	}
+}
+
+func TestConvertToRejectsNonFiniteResults(t *testing.T) {
+	useActiveCatalog(t)
+	// merged catalogs are not validated, unlike loaded ones
+	MergeCatalog(Catalog{Version: "broken", Units: map[string]*UnitInfo{
+		"http://example.org/unit/Broken": {Multiplier: 0, DimensionVector: "A0E0L1I0M0H0T0D0"},
+	}})
+	if got, ok := ConvertTo(1, "http://qudt.org/vocab/unit/M", "http://example.org/unit/Broken", false); ok {
+		t.Fatalf("expected conversion to a unit without multiplier to fail, got %v", got)
+	}
+}
@@ -16,9 +16,6 @@ import (
 	"net/http"
 	"os"
 	"rdf-store-backend/search/qudt"
-	"sort"
-	"strconv"
-	"strings"
 
 	"github.com/deiu/rdf2go"
 )
@@ -28,23 +25,6 @@ const (
 	unitTTLURL  = "https://qudt.org/" + qudtVersion + "/vocab/unit"
 	souTTLURL   = "https://qudt.org/" + qudtVersion + "/vocab/sou"
 	qkTTLURL    = "https://qudt.org/" + qudtVersion + "/vocab/quantitykind"
-	siSystemURI = "http://qudt.org/vocab/sou/SI"
-)
-
-var (
-	qudtMultiplier      = rdf2go.NewResource("http://qudt.org/schema/qudt/conversionMultiplier")
-	qudtOffset          = rdf2go.NewResource("http://qudt.org/schema/qudt/conversionOffset")
-	qudtDV              = rdf2go.NewResource("http://qudt.org/schema/qudt/hasDimensionVector")
-	qudtQK              = rdf2go.NewResource("http://qudt.org/schema/qudt/hasQuantityKind")
-	qudtApplicable      = rdf2go.NewResource("http://qudt.org/schema/qudt/applicableSystem")
-	qudtDerivedCoherent = rdf2go.NewResource("http://qudt.org/schema/qudt/derivedCoherentUnitOfSystem")
-	qudtScalingOf       = rdf2go.NewResource("http://qudt.org/schema/qudt/scalingOf")
-	qudtHasBaseUnit     = rdf2go.NewResource("http://qudt.org/schema/qudt/hasBaseUnit")
-	qudtSIExactMatch    = rdf2go.NewResource("http://qudt.org/schema/qudt/siExactMatch")
-	qudtApplicableUnit  = rdf2go.NewResource("http://qudt.org/schema/qudt/applicableUnit")
-	qudtSpecialization  = rdf2go.NewResource("http://qudt.org/schema/qudt/specializationOf")
-	skosBroader         = rdf2go.NewResource("http://www.w3.org/2004/02/skos/core#broader")
-	siSystem            = rdf2go.NewResource(siSystemURI)
 )
 
 func main() {
@@ -68,145 +48,10 @@ func run() error {
 			return fmt.Errorf("loading %s vocabulary: %w", source.name, err)
 		}
 	}
-	type entry struct {
-		multiplier float64
-		offset     float64
-		dv         string
-		qkSet      map[string]bool
-		siBase     bool
-		siCoherent bool
-		siAllowed  bool
-		scaled     bool
-		siExact    bool
-	}
-	entries := make(map[string]*entry)
-
-	// Find all subjects with a conversionMultiplier — these are the units.
-	for _, s := range g.All(nil, qudtMultiplier, nil) {
-		subj := termURI(s.Subject)
-		e, ok := entries[subj]
-		if !ok {
-			e = &entry{qkSet: make(map[string]bool)}
-			entries[subj] = e
-		}
-		if lit, ok := s.Object.(*rdf2go.Literal); ok {
-			if f, err := strconv.ParseFloat(lit.Value, 64); err == nil {
-				e.multiplier = f
-			}
-		}
-	}
-	fmt.Printf("found %d units with conversionMultiplier\n", len(entries))
-
-	// Collect dimension vectors (predicate-specific query required by rdf2go).
-	for _, s := range g.All(nil, qudtDV, nil) {
-		subj := termURI(s.Subject)
-		e, ok := entries[subj]
-		if !ok {
-			continue
-		}
-		if res, ok := s.Object.(*rdf2go.Resource); ok {
-			uri := res.URI
-			if i := strings.LastIndex(uri, "/"); i >= 0 {
-				e.dv = uri[i+1:]
-			}
-		}
-	}
-
-	// Collect offsets.
-	for _, s := range g.All(nil, qudtOffset, nil) {
-		subj := termURI(s.Subject)
-		e, ok := entries[subj]
-		if !ok {
-			continue
-		}
-		if lit, ok := s.Object.(*rdf2go.Literal); ok {
-			if f, err := strconv.ParseFloat(lit.Value, 64); err == nil {
-				e.offset = f
-			}
-		}
-	}
-
-	// Collect quantity kinds.
-	for _, s := range g.All(nil, qudtQK, nil) {
-		subj := termURI(s.Subject)
-		e, ok := entries[subj]
-		if !ok {
-			continue
-		}
-		if res, ok := s.Object.(*rdf2go.Resource); ok {
-			e.qkSet[res.URI] = true
-		}
-	}
-	// Quantity kinds also declare their applicable units in the quantity-kind
-	// vocabulary. Merge those assertions to cover units whose unit record is
-	// less specific.
-	for _, s := range g.All(nil, qudtApplicableUnit, nil) {
-		if e := entries[termURI(s.Object)]; e != nil {
-			e.qkSet[termURI(s.Subject)] = true
-		}
-	}
-
-	quantityKindParents := make(map[string][]string)
-	for _, predicate := range []rdf2go.Term{qudtSpecialization, skosBroader} {
-		for _, s := range g.All(nil, predicate, nil) {
-			child := termURI(s.Subject)
-			parent := termURI(s.Object)
-			if child != "" && parent != "" {
-				quantityKindParents[child] = appendUnique(quantityKindParents[child], parent)
-			}
-		}
-	}
-
-	// Collect explicit SI metadata. Base and named coherent units have the
-	// strongest signal. SI-applicable, unscaled units provide a fallback for
-	// coherent compound units such as cubic metre, which QUDT does not mark with
-	// derivedCoherentUnitOfSystem.
-	for _, s := range g.All(nil, qudtApplicable, siSystem) {
-		if e := entries[termURI(s.Subject)]; e != nil {
-			e.siAllowed = true
-		}
-	}
-	for _, s := range g.All(nil, qudtDerivedCoherent, siSystem) {
-		if e := entries[termURI(s.Subject)]; e != nil {
-			e.siCoherent = true
-		}
-	}
-	for _, s := range g.All(nil, qudtScalingOf, nil) {
-		if e := entries[termURI(s.Subject)]; e != nil {
-			e.scaled = true
-		}
-	}
-	for _, s := range g.All(siSystem, qudtHasBaseUnit, nil) {
-		if e := entries[termURI(s.Object)]; e != nil {
-			e.siBase = true
-		}
-	}
-	for _, s := range g.All(nil, qudtSIExactMatch, nil) {
-		if e := entries[termURI(s.Subject)]; e != nil {
-			e.siExact = true
-		}
-	}
-
-	allUnits := make(map[string]*qudt.CatalogSourceUnit, len(entries))
-	for uri, e := range entries {
-		qks := make([]string, 0, len(e.qkSet))
-		for qk := range e.qkSet {
-			qks = append(qks, qk)
-		}
-		sort.Strings(qks)
-		allUnits[uri] = &qudt.CatalogSourceUnit{
-			Multiplier:        e.multiplier,
-			Offset:            e.offset,
-			QuantityKinds:     qks,
-			DimensionVector:   e.dv,
-			SIBase:            e.siBase,
-			SIDerivedCoherent: e.siCoherent,
-			SIApplicable:      e.siAllowed,
-			Scaled:            e.scaled,
-			SIExactMatch:      e.siExact,
-		}
-	}
+	allUnits, quantityKindParents := qudt.SourceUnits(g)
+	fmt.Printf("found %d units with conversionMultiplier\n", len(allUnits))
 	catalog := qudt.BuildCatalog(allUnits, quantityKindParents)
+	catalog.Version = "QUDT " + qudtVersion
 
 	var buf bytes.Buffer
 	enc := json.NewEncoder(&buf)
@@ -245,19 +90,3 @@ func downloadInto(g *rdf2go.Graph, url string) error {
 	}
 	return nil
 }
-
-func termURI(t rdf2go.Term) string {
-	if r, ok := t.(*rdf2go.Resource); ok {
-		return r.URI
-	}
-	return t.String()
-}
-
-func appendUnique(values []string, value string) []string {
-	for _, existing := range values {
-		if existing == value {
-			return values
-		}
-	}
-	return append(values, value)
-}
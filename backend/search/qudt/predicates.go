@@ -0,0 +1,81 @@
+package qudt
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/deiu/rdf2go"
+)
+
+// PredicateSets are alternative quantity predicate configurations, tried in
+// order until one matches a measurement node.
+type PredicateSets []PredicateConfig
+
+// KnownPredicateSets model quantity values in well-known vocabularies. When
+// enabled, they are detected on measurement nodes without further
+// configuration.
+var KnownPredicateSets = PredicateSets{
+	// QUDT quantity values
+	NewOptionalKindPredicateConfig("http://qudt.org/schema/qudt/unit", "http://qudt.org/schema/qudt/hasQuantityKind", "http://qudt.org/schema/qudt/numericValue"),
+	NewOptionalKindPredicateConfig("http://qudt.org/schema/qudt/unit", "http://qudt.org/schema/qudt/hasQuantityKind", "http://qudt.org/schema/qudt/value"),
+	// NFDI4ING metadata4ing
+	NewOptionalKindPredicateConfig("http://w3id.org/nfdi4ing/metadata4ing#hasUnit", "http://w3id.org/nfdi4ing/metadata4ing#hasKindOfQuantity", "http://w3id.org/nfdi4ing/metadata4ing#hasNumericalValue"),
+	// schema.org QuantitativeValue, whose unit codes are converted if they are unit IRIs
+	NewOptionalKindPredicateConfig("http://schema.org/unitCode", "", "http://schema.org/value"),
+	NewOptionalKindPredicateConfig("https://schema.org/unitCode", "", "https://schema.org/value"),
+}
+
+// Enabled reports whether the configuration converts quantity values.
+func (config PredicateConfig) Enabled() bool {
+	return config.enabled
+}
+
+// Predicates returns the unit, quantity kind and value predicates and whether
+// the quantity kind is optional.
+func (config PredicateConfig) Predicates() (hasUnit, hasKindOfQuantity, hasNumericalValue string, kindOptional bool) {
+	return config.hasUnit, config.hasKindOfQuantity, config.hasNumericalValue, config.kindOptional
+}
+
+// ScanConversionContext returns the conversion context of the first
+// configuration matching the node, or nil if none matches.
+func (sets PredicateSets) ScanConversionContext(node rdf2go.Term, resource *rdf2go.Graph) *QuantityContext {
+	for _, config := range sets {
+		if context := config.ScanConversionContext(node, resource); context != nil {
+			return context
+		}
+	}
+	return nil
+}
+
+// predicateSetFile is an entry of a predicate set file.
+type predicateSetFile struct {
+	Unit         string `json:"unit"`
+	QuantityKind string `json:"quantityKind,omitempty"`
+	Value        string `json:"value"`
+}
+
+// LoadPredicateSets reads quantity predicate configurations from a JSON file
+// holding an array of objects with the unit, optional quantityKind and value
+// predicates.
+// It returns an error if the file cannot be parsed or an entry lacks the unit
+// or value predicate.
+func LoadPredicateSets(file string) (PredicateSets, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var entries []predicateSetFile
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("parsing quantity predicates file %s: %w", file, err)
+	}
+	sets := make(PredicateSets, 0, len(entries))
+	for i, entry := range entries {
+		config := NewOptionalKindPredicateConfig(entry.Unit, entry.QuantityKind, entry.Value)
+		if !config.enabled {
+			return nil, fmt.Errorf("invalid quantity predicates file %s: entry %d needs unit and value predicates", file, i)
+		}
+		sets = append(sets, config)
+	}
+	return sets, nil
+}
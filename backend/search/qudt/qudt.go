@@ -4,7 +4,9 @@ import (
 	_ "embed"
 	"encoding/json"
 	"log/slog"
+	"maps"
 	"math"
+	"slices"
 	"strings"
 
 	"github.com/deiu/rdf2go"
@@ -16,6 +18,9 @@ var unitsJSON []byte
 var (
 	units                   map[string]*UnitInfo
 	canonicalByQuantityKind map[string]string
+	// canonicalByDimension maps dimension vectors to the canonical unit of
+	// most quantity kinds of that dimension.
+	canonicalByDimension map[string]string
 )
 
 // PredicateConfig identifies the RDF properties that make up a quantity
@@ -25,14 +30,22 @@ type PredicateConfig struct {
 	hasKindOfQuantity string
 	hasNumericalValue string
 	enabled           bool
+	// kindOptional accepts quantity values without quantity kind, which are
+	// converted by the dimension vector of their unit.
+	kindOptional bool
 }
 
 // QuantityContext carries unit information for a measurement node so that
 // appendQueryValue can convert the numeric value to its canonical SI unit.
 type QuantityContext struct {
-	UnitURI               string
-	QuantityKindURI       string
-	IsDelta               bool
+	UnitURI         string
+	QuantityKindURI string
+	IsDelta         bool
+	// Uncertainty is the standard uncertainty and Min and Max are the bounds
+	// of the measurement, in the unit of the quantity, or nil if not given.
+	Uncertainty           *float64
+	Min                   *float64
+	Max                   *float64
 	numericalPredicateURI string
 }
 
@@ -50,6 +63,11 @@ func init() {
 	}
 	units = catalog.Units
 	canonicalByQuantityKind = catalog.CanonicalUnits
+	canonicalByDimension = dimensionCanonicalUnits(units, canonicalByQuantityKind)
+	if catalog.Version == "" {
+		catalog.Version = embeddedCatalogVersion
+	}
+	catalogVersions = []string{catalog.Version}
 
 	slog.Info("loaded QUDT units", "units", len(units), "canonicalQuantityKinds", len(canonicalByQuantityKind))
 }
@@ -66,8 +84,20 @@ func NewPredicateConfig(hasUnit, hasKindOfQuantity, hasNumericalValue string) Pr
 	return config
 }
 
+// NewOptionalKindPredicateConfig creates a quantity predicate configuration
+// for vocabularies where the quantity kind is optional or not modelled at all.
+// hasKindOfQuantity may be empty. If the unit or value predicate is empty,
+// quantity conversion is disabled.
+func NewOptionalKindPredicateConfig(hasUnit, hasKindOfQuantity, hasNumericalValue string) PredicateConfig {
+	config := NewPredicateConfig(hasUnit, hasKindOfQuantity, hasNumericalValue)
+	config.enabled = config.hasUnit != "" && config.hasNumericalValue != ""
+	config.kindOptional = true
+	return config
+}
+
 // ScanConversionContext inspects a measurement node for quantity
-// properties (hasUnit, hasKindOfQuantity) and the QUDT isDeltaQuantity flag.
+// properties (hasUnit, hasKindOfQuantity), the QUDT isDeltaQuantity flag and
+// the uncertainty and bounds of the measurement.
 // It returns a context suitable for unit conversion, or nil if the node is
 // not a quantity measurement.
 func (config PredicateConfig) ScanConversionContext(node rdf2go.Term, resource *rdf2go.Graph) *QuantityContext {
@@ -75,7 +105,6 @@ func (config PredicateConfig) ScanConversionContext(node rdf2go.Term, resource *
 		return nil
 	}
 	hasUnitPredicate := rdf2go.NewResource(config.hasUnit)
-	hasKindOfQuantityPredicate := rdf2go.NewResource(config.hasKindOfQuantity)
 	var unitURI string
 	for _, t := range resource.All(node, hasUnitPredicate, nil) {
 		if res, ok := t.Object.(*rdf2go.Resource); ok {
@@ -87,13 +116,15 @@ func (config PredicateConfig) ScanConversionContext(node rdf2go.Term, resource *
 		return nil
 	}
 	var quantityKindURI string
-	for _, t := range resource.All(node, hasKindOfQuantityPredicate, nil) {
-		if res, ok := t.Object.(*rdf2go.Resource); ok {
-			quantityKindURI = res.RawValue()
-			break
+	if config.hasKindOfQuantity != "" {
+		for _, t := range resource.All(node, rdf2go.NewResource(config.hasKindOfQuantity), nil) {
+			if res, ok := t.Object.(*rdf2go.Resource); ok {
+				quantityKindURI = res.RawValue()
+				break
+			}
 		}
 	}
-	if quantityKindURI == "" {
+	if quantityKindURI == "" && !config.kindOptional {
 		return nil
 	}
 	// Check for qudt:isDeltaQuantity true on the measurement node.
@@ -110,6 +141,9 @@ func (config PredicateConfig) ScanConversionContext(node rdf2go.Term, resource *
 		UnitURI:               unitURI,
 		QuantityKindURI:       quantityKindURI,
 		IsDelta:               isDelta,
+		Uncertainty:           scanNumber(node, resource, uncertaintyPredicates),
+		Min:                   scanNumber(node, resource, lowerBoundPredicates),
+		Max:                   scanNumber(node, resource, upperBoundPredicates),
 		numericalPredicateURI: config.hasNumericalValue,
 	}
 }
@@ -127,13 +161,18 @@ func Unit(unitURI string) *UnitInfo {
 
 // CanonicalUnitURI returns the preferred coherent unit URI for the given
 // quantity kind, provided it is dimensionally compatible with the source unit.
+// Without quantity kind, the canonical unit is the one of most quantity kinds
+// of the unit's dimension vector.
 // It returns an empty string if no compatible target is known.
 func CanonicalUnitURI(unitURI, quantityKindURI string) string {
 	info, ok := units[unitURI]
 	if !ok {
 		return ""
 	}
-	canonicalURI := canonicalByQuantityKind[normalizeQuantityKindURI(quantityKindURI)]
+	canonicalURI := canonicalByDimension[info.DimensionVector]
+	if quantityKindURI != "" {
+		canonicalURI = canonicalByQuantityKind[normalizeQuantityKindURI(quantityKindURI)]
+	}
 	canonicalInfo := units[canonicalURI]
 	if canonicalInfo == nil || info.DimensionVector == "" || canonicalInfo.DimensionVector != info.DimensionVector {
 		return ""
@@ -184,6 +223,39 @@ func Convert(value float64, srcUnitURI, quantityKindURI string, isDelta bool) (f
 	return convertWith(value, src, tgt, isDelta), true
 }
 
+// dimensionlessVector is the dimension vector of counts, ratios and angles,
+// which cannot be compared without their quantity kind.
+const dimensionlessVector = "A0E0L0I0M0H0T0D1"
+
+// dimensionCanonicalUnits maps dimension vectors to the canonical unit of the
+// majority of their quantity kinds, e.g. metre for length, width and the other
+// kinds of dimension L1. Dimension vectors without majority, like the one
+// shared by hertz and becquerel, are left out.
+func dimensionCanonicalUnits(units map[string]*UnitInfo, canonicalUnits map[string]string) map[string]string {
+	votes := make(map[string]map[string]int)
+	totals := make(map[string]int)
+	for _, uri := range canonicalUnits {
+		info := units[uri]
+		if info == nil || info.DimensionVector == "" || info.DimensionVector == dimensionlessVector {
+			continue
+		}
+		if votes[info.DimensionVector] == nil {
+			votes[info.DimensionVector] = make(map[string]int)
+		}
+		votes[info.DimensionVector][uri]++
+		totals[info.DimensionVector]++
+	}
+	result := make(map[string]string)
+	for dimension, counts := range votes {
+		for uri, count := range counts {
+			if 2*count > totals[dimension] {
+				result[dimension] = uri
+			}
+		}
+	}
+	return result
+}
+
 func normalizeQuantityKindURI(quantityKind string) string {
 	if strings.Contains(quantityKind, "://") {
 		return quantityKind
@@ -193,7 +265,8 @@ func normalizeQuantityKindURI(quantityKind string) string {
 
 // ConvertTo converts a numeric value from srcUnit to tgtUnit.
 // It returns the converted value and true on success, or (0, false) if
-// either unit is unknown or they have different dimension vectors.
+// either unit is unknown, they have different dimension vectors or the
+// result is not finite.
 func ConvertTo(value float64, srcUnitURI, tgtUnitURI string, isDelta bool) (float64, bool) {
 	if srcUnitURI == tgtUnitURI {
 		return 0, false
@@ -209,7 +282,11 @@ func ConvertTo(value float64, srcUnitURI, tgtUnitURI string, isDelta bool) (floa
 	if src.DimensionVector != tgt.DimensionVector {
 		return 0, false
 	}
-	return convertWith(value, src, tgt, isDelta), true
+	result := convertWith(value, src, tgt, isDelta)
+	if math.IsInf(result, 0) || math.IsNaN(result) {
+		return 0, false
+	}
+	return result, true
 }
 
 func convertWith(value float64, src, tgt *UnitInfo, isDelta bool) float64 {
@@ -222,6 +299,50 @@ func convertWith(value float64, src, tgt *UnitInfo, isDelta bool) float64 {
 	return ((value+srcOff)*src.Multiplier)/tgt.Multiplier - tgtOff
 }
 
+// CanonicalUnitOf returns the canonical unit URI of a quantity kind, or an
+// empty string if the quantity kind has none.
+func CanonicalUnitOf(quantityKindURI string) string {
+	return canonicalByQuantityKind[normalizeQuantityKindURI(quantityKindURI)]
+}
+
+// CompatibleUnits returns the URIs of the units sharing the dimension vector of
+// a quantity kind's canonical unit, which ConvertTo converts between, sorted.
+// Without quantity kind, all units are returned. Unknown quantity kinds have
+// no compatible units.
+func CompatibleUnits(quantityKindURI string) []string {
+	dimension := ""
+	if quantityKindURI != "" {
+		canonical := units[CanonicalUnitOf(quantityKindURI)]
+		if canonical == nil {
+			return nil
+		}
+		dimension = canonical.DimensionVector
+	}
+	result := make([]string, 0)
+	for uri, info := range units {
+		if dimension == "" || info.DimensionVector == dimension {
+			result = append(result, uri)
+		}
+	}
+	slices.Sort(result)
+	return result
+}
+
+// Label returns the label of a unit in the first of the languages it has,
+// falling back to the untagged and then any label.
+func (info *UnitInfo) Label(languages ...string) string {
+	for _, language := range append(slices.Clone(languages), "") {
+		if label, ok := info.Labels[strings.ToLower(language)]; ok {
+			return label
+		}
+	}
+	tags := slices.Sorted(maps.Keys(info.Labels))
+	if len(tags) == 0 {
+		return ""
+	}
+	return info.Labels[tags[0]]
+}
+
 // ApproxEquals reports whether a and b are equal within the given tolerance.
 func ApproxEquals(a, b, tolerance float64) bool {
 	diff := math.Abs(a - b)
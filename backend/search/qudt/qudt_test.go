@@ -293,3 +293,77 @@ func TestConvertUnknownCanonical(t *testing.T) {
 		t.Error("Convert(273.15, K) should return false (K is canonical)")
 	}
 }
+
+func TestPredicateSetsScanFirstMatchingConfiguration(t *testing.T) {
+	const (
+		qudtUnit  = "http://qudt.org/schema/qudt/unit"
+		qudtValue = "http://qudt.org/schema/qudt/numericValue"
+	)
+	node := rdf2go.NewResource("http://example.com/quantity")
+	graph := rdf2go.NewGraph("")
+	graph.AddTriple(node, rdf2go.NewResource(qudtUnit), rdf2go.NewResource("http://qudt.org/vocab/unit/DEG_C"))
+
+	context := KnownPredicateSets.ScanConversionContext(node, graph)
+	if context == nil || context.QuantityKindURI != "" || !context.ConvertsNumericPredicate(qudtValue) {
+		t.Fatalf("expected a QUDT quantity without kind, got %+v", context)
+	}
+	if got, ok := Convert(20, context.UnitURI, context.QuantityKindURI, false); !ok || !ApproxEquals(got, 293.15, 1e-9) {
+		t.Errorf("Convert(20 DEG_C) without kind = %v, %v, want 293.15 K", got, ok)
+	}
+	if context := (PredicateSets{NewPredicateConfig(qudtUnit, "http://qudt.org/schema/qudt/hasQuantityKind", qudtValue)}).ScanConversionContext(node, graph); context != nil {
+		t.Errorf("expected a configuration requiring the kind not to match, got %+v", context)
+	}
+}
+
+func TestCanonicalUnitWithoutQuantityKind(t *testing.T) {
+	if cu := CanonicalUnitURI("http://qudt.org/vocab/unit/FT", ""); cu != "http://qudt.org/vocab/unit/M" {
+		t.Errorf("CanonicalUnitURI(FT) without kind = %v, want M", cu)
+	}
+	// hertz and becquerel share their dimension vector
+	if cu := CanonicalUnitURI("http://qudt.org/vocab/unit/HZ", ""); cu != "" {
+		t.Errorf("CanonicalUnitURI(HZ) without kind = %v, want empty", cu)
+	}
+}
+
+func TestUnitLabelFallsBackToUntaggedLabel(t *testing.T) {
+	info := &UnitInfo{Labels: map[string]string{"": "metre", "de": "Meter"}}
+	if label := info.Label("fr", "DE"); label != "Meter" {
+		t.Errorf("Label(fr, DE) = %q, want Meter", label)
+	}
+	if label := info.Label("fr"); label != "metre" {
+		t.Errorf("Label(fr) = %q, want metre", label)
+	}
+	if label := (&UnitInfo{}).Label("en"); label != "" {
+		t.Errorf("Label without labels = %q, want empty", label)
+	}
+}
+
+func TestQuantityContextIntervalConvertsUncertaintyWithoutOffset(t *testing.T) {
+	const qudtValue = "http://qudt.org/schema/qudt/numericValue"
+	node := rdf2go.NewResource("http://example.com/quantity")
+	graph := rdf2go.NewGraph("")
+	graph.AddTriple(node, rdf2go.NewResource("http://qudt.org/schema/qudt/unit"), rdf2go.NewResource("http://qudt.org/vocab/unit/DEG_F"))
+	graph.AddTriple(node, rdf2go.NewResource("http://qudt.org/schema/qudt/standardUncertainty"), rdf2go.NewLiteral("1.8"))
+	graph.AddTriple(node, rdf2go.NewResource("http://schema.org/maxValue"), rdf2go.NewLiteral("50"))
+
+	context := KnownPredicateSets.ScanConversionContext(node, graph)
+	if context == nil || context.Uncertainty == nil || *context.Uncertainty != 1.8 || context.Min != nil || context.Max == nil || *context.Max != 50 {
+		t.Fatalf("expected uncertainty and upper bound, got %+v", context)
+	}
+	lower, upper, ok := context.Interval(32)
+	if !ok || !ApproxEquals(lower, 272.15, 1e-9) || !ApproxEquals(upper, 283.15, 1e-9) {
+		t.Errorf("Interval(32 DEG_F) = %v, %v, %v, want 272.15 K to 283.15 K", lower, upper, ok)
+	}
+	if got, ok := context.ConvertNumeric("http://qudt.org/schema/qudt/standardUncertainty", 1.8); !ok || !ApproxEquals(got, 1, 1e-9) {
+		t.Errorf("ConvertNumeric(1.8 DEG_F uncertainty) = %v, %v, want 1 K", got, ok)
+	}
+	if got, ok := context.ConvertNumeric(qudtValue, 32); !ok || !ApproxEquals(got, 273.15, 1e-9) {
+		t.Errorf("ConvertNumeric(32 DEG_F) = %v, %v, want 273.15 K", got, ok)
+	}
+	if _, ok := context.ConvertNumeric("http://example.com/other", 32); ok {
+		t.Error("expected values of other predicates not to be converted")
+	}
+	if _, _, ok := (&QuantityContext{UnitURI: context.UnitURI}).Interval(32); ok {
+		t.Error("expected no interval without uncertainty or bounds")
+	}
+}
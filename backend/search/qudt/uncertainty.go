@@ -0,0 +1,97 @@
+package qudt
+
+import (
+	"math"
+	"slices"
+	"strconv"
+
+	"github.com/deiu/rdf2go"
+)
+
+// uncertaintyPredicates give the standard uncertainty of a measurement, and
+// lowerBoundPredicates and upperBoundPredicates the range it lies in, in the
+// unit of the measurement.
+var (
+	uncertaintyPredicates = []string{
+		"http://qudt.org/schema/qudt/standardUncertainty",
+	}
+	lowerBoundPredicates = []string{
+		"http://qudt.org/schema/qudt/lowerBound",
+		"http://w3id.org/nfdi4ing/metadata4ing#hasMinimumValue",
+		"http://schema.org/minValue",
+		"https://schema.org/minValue",
+	}
+	upperBoundPredicates = []string{
+		"http://qudt.org/schema/qudt/upperBound",
+		"http://w3id.org/nfdi4ing/metadata4ing#hasMaximumValue",
+		"http://schema.org/maxValue",
+		"https://schema.org/maxValue",
+	}
+)
+
+// scanNumber returns the first numeric literal of a node at any of the
+// predicates, or nil if there is none.
+func scanNumber(node rdf2go.Term, resource *rdf2go.Graph, predicates []string) *float64 {
+	for _, predicate := range predicates {
+		for _, t := range resource.All(node, rdf2go.NewResource(predicate), nil) {
+			if lit, ok := t.Object.(*rdf2go.Literal); ok {
+				if number, err := strconv.ParseFloat(lit.RawValue(), 64); err == nil {
+					return &number
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// canonical converts a value of the quantity to its canonical unit. Values
+// that cannot be converted, or already are canonical, are kept.
+func (q *QuantityContext) canonical(value float64, isDelta bool) float64 {
+	if converted, ok := Convert(value, q.UnitURI, q.QuantityKindURI, isDelta); ok {
+		return converted
+	}
+	return value
+}
+
+// ConvertNumeric converts a value of predicateURI on the measurement node to
+// the canonical unit. Numerical values and bounds are converted like the
+// quantity, the uncertainty as a difference, without offset.
+// It returns false if predicateURI has no quantity values or the value
+// cannot be converted.
+func (q *QuantityContext) ConvertNumeric(predicateURI string, value float64) (float64, bool) {
+	if q == nil {
+		return 0, false
+	}
+	isDelta := q.IsDelta
+	switch {
+	case predicateURI == q.numericalPredicateURI || slices.Contains(lowerBoundPredicates, predicateURI) || slices.Contains(upperBoundPredicates, predicateURI):
+	case slices.Contains(uncertaintyPredicates, predicateURI):
+		isDelta = true
+	default:
+		return 0, false
+	}
+	return Convert(value, q.UnitURI, q.QuantityKindURI, isDelta)
+}
+
+// Interval returns the range of a numerical value of the quantity in the
+// canonical unit. Explicit bounds take precedence over the value plus or
+// minus its uncertainty, which is converted without offset.
+// It returns false if the measurement has neither uncertainty nor bounds.
+func (q *QuantityContext) Interval(value float64) (float64, float64, bool) {
+	if q == nil || (q.Uncertainty == nil && q.Min == nil && q.Max == nil) {
+		return 0, 0, false
+	}
+	center := q.canonical(value, q.IsDelta)
+	lower, upper := center, center
+	if q.Uncertainty != nil {
+		uncertainty := math.Abs(q.canonical(*q.Uncertainty, true))
+		lower, upper = center-uncertainty, center+uncertainty
+	}
+	if q.Min != nil {
+		lower = q.canonical(*q.Min, q.IsDelta)
+	}
+	if q.Max != nil {
+		upper = q.canonical(*q.Max, q.IsDelta)
+	}
+	return min(lower, center), max(upper, center), true
+}
@@ -0,0 +1,560 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"rdf-store-backend/base"
+	"rdf-store-backend/httpclient"
+	"rdf-store-backend/metrics"
+	"rdf-store-backend/rdf"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/deiu/rdf2go"
+)
+
+// Criterion operators supported by structured searches.
+const (
+	OperatorEquals        = "equals"
+	OperatorContains      = "contains"
+	OperatorRange         = "range"
+	OperatorGeoIntersects = "geo-intersects"
+	OperatorGeoWithin     = "geo-within"
+	OperatorGeoBBox       = "geo-bbox"
+	OperatorGeoDistance   = "geo-distance"
+	// Interval operators match dates, partial dates and time intervals that
+	// overlap, lie within or contain the criterion interval.
+	OperatorIntervalOverlaps = "interval-overlaps"
+	OperatorIntervalWithin   = "interval-within"
+	OperatorIntervalContains = "interval-contains"
+)
+
+const (
+	defaultSearchLimit = 10
+	maxSearchLimit     = 100
+	defaultSearchSort  = "lastModified desc"
+	rankedSearchSort   = "score desc, lastModified desc"
+)
+
+// ErrInvalidQuery wraps errors caused by a malformed structured query.
+var ErrInvalidQuery = errors.New("invalid query")
+
+var searchFields = []string{"id", "resourceId", "subject", "label", "shape", "lastModified"}
+var sortFields = map[string]bool{"score": true, "lastModified": true, "resourceId": true, "subject": true}
+var luceneTermCharacters = regexp.MustCompile(`[+\-&|!(){}\[\]^"~*?:\\/\s]`)
+
+// Query is the structured search request model. Criteria reference SHACL
+// paths by their segments, so clients never compute path identifiers.
+type Query struct {
+	// Profile restricts hits to entities conforming to the profile.
+	Profile string `json:"profile,omitempty"`
+	// Criteria must all be satisfied, each by any value of the entity.
+	Criteria []Criterion `json:"criteria,omitempty"`
+	// Fulltext matches entities containing the term anywhere in their text.
+	Fulltext string `json:"fulltext,omitempty"`
+	// Sort is a field and direction, e.g. "lastModified desc".
+	Sort string `json:"sort,omitempty"`
+	// Offset and Limit page through the hits.
+	Offset int `json:"offset,omitempty"`
+	Limit  int `json:"limit,omitempty"`
+	// Language selects the label language of hits. The indexed default label
+	// is returned when it is empty.
+	Language string `json:"language,omitempty"`
+	// Geometries adds the geometry values of hits as GeoJSON. They are also
+	// added if a criterion is spatial.
+	Geometries bool `json:"geometries,omitempty"`
+}
+
+// Criterion restricts hits to entities having a value at Path that matches
+// the operator. Path segments are predicate IRIs or, for qualified SHACL
+// properties, the property shape IDs, as used by the indexer.
+type Criterion struct {
+	Path     []string `json:"path"`
+	Operator string   `json:"operator"`
+	Value    string   `json:"value,omitempty"`
+	Min      string   `json:"min,omitempty"`
+	Max      string   `json:"max,omitempty"`
+	// Datatype is the XSD datatype of the value. It selects the typed value
+	// field, so it is needed for numbers, dates and booleans.
+	Datatype string `json:"datatype,omitempty"`
+	// Distance is the radius in kilometers of geo-distance criteria.
+	Distance float64 `json:"distance,omitempty"`
+	// Exact leaves out the broader concepts added to IRI values, so a concept
+	// does not match entities having only narrower concepts.
+	Exact bool `json:"exact,omitempty"`
+	// Unit is the QUDT unit of numeric values, which are converted to the
+	// canonical unit of QuantityKind, or of the unit's dimension vector if
+	// empty. IsDelta converts them as differences, without offset.
+	Unit         string `json:"unit,omitempty"`
+	QuantityKind string `json:"quantityKind,omitempty"`
+	IsDelta      bool   `json:"isDelta,omitempty"`
+}
+
+// SearchResult is a page of entity hits.
+type SearchResult struct {
+	Total  int         `json:"total"`
+	Offset int         `json:"offset"`
+	Limit  int         `json:"limit"`
+	Hits   []SearchHit `json:"hits"`
+	// nextCursorMark is the cursor of the following page for cursor requests.
+	nextCursorMark string
+}
+
+// SearchHit is an entity matching a structured search.
+type SearchHit struct {
+	ID           string   `json:"id"`
+	ResourceID   string   `json:"resourceId"`
+	Subject      string   `json:"subject"`
+	Label        string   `json:"label,omitempty"`
+	Shapes       []string `json:"shapes"`
+	LastModified string   `json:"lastModified,omitempty"`
+	// Highlights are snippets of the text values matching the full-text term
+	// or contains criteria of a structured search.
+	Highlights []Highlight `json:"highlights,omitempty"`
+	// Geometries are the geometry values of the hit, if requested.
+	Geometries []GeometryValue `json:"geometries,omitempty"`
+}
+
+type searchRequest struct {
+	Query  string         `json:"query"`
+	Filter []string       `json:"filter"`
+	Sort   string         `json:"sort"`
+	Offset int            `json:"offset"`
+	Limit  int            `json:"limit"`
+	Fields []string       `json:"fields"`
+	Params map[string]any `json:"params,omitempty"`
+}
+
+// buildSearchRequest translates a structured query into the Solr JSON request
+// described in SEARCHING.md. Every criterion becomes its own block-join parent
+// filter so that different criteria may be satisfied by different values.
+// It returns an error wrapping ErrInvalidQuery when the query is malformed.
+func buildSearchRequest(query Query) (*searchRequest, error) {
+	request := &searchRequest{
+		Query:  "*:*",
+		Filter: []string{"docType:entity"},
+		Sort:   defaultSearchSort,
+		Offset: query.Offset,
+		Limit:  query.Limit,
+		Fields: searchFields,
+	}
+	if request.Offset < 0 {
+		return nil, fmt.Errorf("%w: negative offset", ErrInvalidQuery)
+	}
+	if request.Limit == 0 {
+		request.Limit = defaultSearchLimit
+	}
+	if request.Limit < 0 || request.Limit > maxSearchLimit {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidQuery, maxSearchLimit)
+	}
+	if query.Sort != "" {
+		sort, err := parseSort(query.Sort)
+		if err != nil {
+			return nil, err
+		}
+		request.Sort = sort
+	}
+	if query.Profile != "" {
+		request.Filter = append(request.Filter, "shape:"+escapeQueryValue(query.Profile))
+	}
+	if term := strings.TrimSpace(query.Fulltext); term != "" {
+		request.Filter = append(request.Filter, "_text_:*"+escapeQueryTerm(term)+"*")
+		ranking.applyRanking(request, term, query.Profile)
+		if query.Sort == "" {
+			request.Sort = rankedSearchSort
+		}
+	}
+	for i, criterion := range query.Criteria {
+		filter, err := criterionFilter(criterion)
+		if err != nil {
+			return nil, fmt.Errorf("%w: criterion %d: %w", ErrInvalidQuery, i, err)
+		}
+		request.Filter = append(request.Filter, filter)
+	}
+	return request, nil
+}
+
+// parseSort validates a "field direction" sort clause.
+// It returns the normalized clause or an error for unknown fields.
+func parseSort(sort string) (string, error) {
+	parts := strings.Fields(sort)
+	if len(parts) == 1 {
+		parts = append(parts, "asc")
+	}
+	if len(parts) != 2 || !sortFields[parts[0]] || (parts[1] != "asc" && parts[1] != "desc") {
+		return "", fmt.Errorf("%w: unsupported sort %q", ErrInvalidQuery, sort)
+	}
+	return parts[0] + " " + parts[1], nil
+}
+
+// criterionFilter builds the block-join filter of a single criterion.
+// It returns an error when the path is empty or the values do not fit the operator.
+func criterionFilter(criterion Criterion) (string, error) {
+	if len(criterion.Path) == 0 {
+		return "", errors.New("missing path")
+	}
+	if criterion.Unit != "" {
+		converted, err := canonicalCriterion(criterion)
+		if err != nil {
+			return "", err
+		}
+		criterion = converted
+	}
+	field := valueField(criterion.Datatype)
+	// durations and times are compared in their indexed representation
+	if convert := valueConversions[datatypeMappings[criterion.Datatype]]; convert != nil {
+		for _, value := range []*string{&criterion.Value, &criterion.Min, &criterion.Max} {
+			if *value == "" {
+				continue
+			}
+			converted, err := convert(*value)
+			if err != nil {
+				return "", err
+			}
+			*value = converted
+		}
+	}
+	var valueFilter string
+	switch criterion.Operator {
+	case OperatorEquals:
+		if criterion.Value == "" {
+			return "", errors.New("missing value")
+		}
+		value, err := normalizeValue(criterion.Value, field)
+		if err != nil {
+			return "", err
+		}
+		// partial dates are matched by their lexical value
+		if field == "valueText" || field == "valueDateRange" {
+			field = "valueString"
+		}
+		valueFilter = field + ":" + escapeQueryValue(value)
+		if criterion.Datatype == "" && isAbsoluteIRI(value) {
+			// IRIs are indexed in N-Triples notation, plain literals as is
+			valueFilter = fmt.Sprintf("(%s OR %s:%s)", valueFilter, field, escapeQueryValue("<"+value+">"))
+		}
+	case OperatorContains:
+		if strings.TrimSpace(criterion.Value) == "" {
+			return "", errors.New("missing value")
+		}
+		valueFilter = textContainsFilter(strings.TrimSpace(criterion.Value))
+	case OperatorRange:
+		if criterion.Datatype == "" {
+			field = "valueNumber"
+		}
+		if field != "valueNumber" && field != "valueDate" {
+			return "", fmt.Errorf("datatype %s does not support ranges", criterion.Datatype)
+		}
+		if criterion.Min == "" && criterion.Max == "" {
+			return "", errors.New("missing min or max")
+		}
+		min, err := rangeBound(criterion.Min, field)
+		if err != nil {
+			return "", err
+		}
+		max, err := rangeBound(criterion.Max, field)
+		if err != nil {
+			return "", err
+		}
+		valueFilter = fmt.Sprintf("%s:[%s TO %s]", field, min, max)
+	case OperatorGeoIntersects, OperatorGeoWithin, OperatorGeoBBox, OperatorGeoDistance:
+		filter, err := geoFilter(criterion)
+		if err != nil {
+			return "", err
+		}
+		valueFilter = filter
+	case OperatorIntervalOverlaps, OperatorIntervalWithin, OperatorIntervalContains:
+		filter, err := intervalFilter(criterion)
+		if err != nil {
+			return "", err
+		}
+		valueFilter = filter
+	default:
+		return "", fmt.Errorf("unsupported operator %q", criterion.Operator)
+	}
+	if criterion.Exact {
+		valueFilter += " AND -expanded:true"
+	}
+	return fmt.Sprintf(`{!parent which=docType:entity}(docType:value AND path:"%s" AND %s)`, queryPathID(criterion.Path), valueFilter), nil
+}
+
+// textContainsFilter matches text values containing value in any text field.
+// Language fields are additionally matched with analysis, as wildcard terms
+// are not stemmed.
+func textContainsFilter(value string) string {
+	clauses := make([]string, 0)
+	for _, field := range textFields() {
+		clauses = append(clauses, field+":*"+escapeQueryTerm(value)+"*")
+		if field != "valueText" {
+			clauses = append(clauses, field+":"+escapeQueryValue(value))
+		}
+	}
+	return "(" + strings.Join(clauses, " OR ") + ")"
+}
+
+// valueField returns the typed value field the indexer uses for a datatype.
+func valueField(datatype string) string {
+	if datatype == "" {
+		return "valueString"
+	}
+	switch datatypeMappings[datatype] {
+	case "ds", "dur":
+		return "valueNumber"
+	case "bs":
+		return "valueBoolean"
+	case "srpt":
+		return "valueGeo"
+	case "dts", "tms":
+		return "valueDate"
+	case "dr":
+		return "valueDateRange"
+	case "ss":
+		return "valueString"
+	}
+	return "valueText"
+}
+
+// normalizeValue converts a lexical value to the representation stored in field.
+// It returns an error when the value cannot be parsed.
+func normalizeValue(value string, field string) (string, error) {
+	switch field {
+	case "valueNumber":
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return "", fmt.Errorf("invalid number %q", value)
+		}
+	case "valueBoolean":
+		if _, err := strconv.ParseBool(value); err != nil {
+			return "", fmt.Errorf("invalid boolean %q", value)
+		}
+	case "valueDate":
+		return dateValue(value, "")
+	}
+	return value, nil
+}
+
+// rangeBound returns the Solr range bound of value, where empty is open.
+func rangeBound(value string, field string) (string, error) {
+	if value == "" {
+		return "*", nil
+	}
+	value, err := normalizeValue(value, field)
+	if err != nil {
+		return "", err
+	}
+	return escapeQueryValue(value), nil
+}
+
+// escapeQueryTerm escapes a value for use inside an unquoted wildcard term.
+func escapeQueryTerm(value string) string {
+	return luceneTermCharacters.ReplaceAllStringFunc(value, func(match string) string {
+		return `\` + match
+	})
+}
+
+func isAbsoluteIRI(value string) bool {
+	parsed, err := url.Parse(value)
+	return err == nil && parsed.Scheme != "" && !strings.ContainsAny(value, " <>\"")
+}
+
+// Search executes a structured query against the search index.
+// It returns the page of hits or an error wrapping ErrInvalidQuery when the
+// query is malformed.
+func Search(ctx context.Context, query Query) (*SearchResult, error) {
+	request, err := buildSearchRequest(query)
+	if err != nil {
+		return nil, err
+	}
+	result, err := executeSearch(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	if err := highlightHits(ctx, query, result.Hits); err != nil {
+		return nil, err
+	}
+	if wantsGeometries(query) {
+		if err := addGeometries(ctx, result.Hits); err != nil {
+			return nil, err
+		}
+	}
+	if query.Language != "" && len(result.Hits) > 0 {
+		if err := localizeLabels(ctx, query.Language, result.Hits); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// SolrQuery is the query and filter part of a raw Solr JSON request, as
+// accepted by the Solr proxy.
+type SolrQuery struct {
+	Query  string   `json:"query,omitempty"`
+	Filter []string `json:"filter,omitempty"`
+}
+
+// SolrQuery translates a structured query into its Solr query and filters.
+// It returns an error wrapping ErrInvalidQuery when the query is malformed.
+func (query Query) SolrQuery() (SolrQuery, error) {
+	request, err := buildSearchRequest(query)
+	if err != nil {
+		return SolrQuery{}, err
+	}
+	return SolrQuery{Query: request.Query, Filter: request.Filter}, nil
+}
+
+// FindModifiedEntities returns up to limit entities matching the query whose
+// resources were modified in the period [since, until), oldest first.
+func FindModifiedEntities(ctx context.Context, query SolrQuery, since time.Time, until time.Time, limit int) (*SearchResult, error) {
+	request := &searchRequest{
+		Query:  query.Query,
+		Filter: append(slices.Clone(query.Filter), "docType:entity", fmt.Sprintf("lastModified:[%s TO %s}", since.UTC().Format(time.RFC3339Nano), until.UTC().Format(time.RFC3339Nano))),
+		Sort:   "lastModified asc",
+		Limit:  limit,
+		Fields: searchFields,
+	}
+	if request.Query == "" {
+		request.Query = "*:*"
+	}
+	return executeSearch(ctx, request)
+}
+
+// Cursor pages through all entities matching a query using Solr's cursorMark
+// paging, which is stable while the index changes and does not degrade with
+// deep offsets.
+type Cursor struct {
+	request *searchRequest
+	done    bool
+	// Total is the number of matching entities. It is set by the first call to Next.
+	Total int
+}
+
+// NewCursor creates a cursor over the entities matching the query, fetching
+// pageSize entities per request.
+func NewCursor(query SolrQuery, pageSize int) *Cursor {
+	request := &searchRequest{
+		Query:  query.Query,
+		Filter: append(slices.Clone(query.Filter), "docType:entity"),
+		// cursors require a sort on the unique key
+		Sort:   "id asc",
+		Limit:  pageSize,
+		Fields: searchFields,
+		Params: map[string]any{"cursorMark": "*"},
+	}
+	if request.Query == "" {
+		request.Query = "*:*"
+	}
+	return &Cursor{request: request}
+}
+
+// Next fetches the following page of entities.
+// It returns an empty page once all entities have been returned.
+func (cursor *Cursor) Next(ctx context.Context) ([]SearchHit, error) {
+	if cursor.done {
+		return nil, nil
+	}
+	result, err := executeSearch(ctx, cursor.request)
+	if err != nil {
+		return nil, err
+	}
+	cursor.Total = result.Total
+	if result.nextCursorMark == "" || result.nextCursorMark == cursor.request.Params["cursorMark"] || len(result.Hits) == 0 {
+		cursor.done = true
+	}
+	cursor.request.Params["cursorMark"] = result.nextCursorMark
+	return result.Hits, nil
+}
+
+// postQuery posts a JSON request to the collection's /query handler.
+// It returns the response body.
+func postQuery(ctx context.Context, request any) ([]byte, error) {
+	data, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/solr/%s/query", Endpoint, base.SolrIndex), bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	start := time.Now()
+	resp, err := httpclient.Do(httpClient, req, true)
+	if err != nil {
+		metrics.ObserveBackendCall(metrics.ServiceSolr, "query", start, 0, err)
+		return nil, err
+	}
+	metrics.ObserveBackendCall(metrics.ServiceSolr, "query", start, resp.StatusCode, nil)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("solr query failed: %s", extractSolrError(body))
+	}
+	return body, nil
+}
+
+// executeSearch runs a search request.
+// It returns the page of entity hits.
+func executeSearch(ctx context.Context, request *searchRequest) (*SearchResult, error) {
+	body, err := postQuery(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	var payload struct {
+		Response struct {
+			NumFound int `json:"numFound"`
+			Docs     []struct {
+				ID           string   `json:"id"`
+				ResourceID   string   `json:"resourceId"`
+				Subject      string   `json:"subject"`
+				Label        []string `json:"label"`
+				Shape        []string `json:"shape"`
+				LastModified string   `json:"lastModified"`
+			} `json:"docs"`
+		} `json:"response"`
+		NextCursorMark string `json:"nextCursorMark"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+	result := &SearchResult{Total: payload.Response.NumFound, Offset: request.Offset, Limit: request.Limit, Hits: make([]SearchHit, 0, len(payload.Response.Docs)), nextCursorMark: payload.NextCursorMark}
+	for _, doc := range payload.Response.Docs {
+		hit := SearchHit{ID: doc.ID, ResourceID: doc.ResourceID, Subject: doc.Subject, Shapes: doc.Shape, LastModified: doc.LastModified}
+		if hit.Shapes == nil {
+			hit.Shapes = []string{}
+		}
+		if len(doc.Label) > 0 {
+			hit.Label = doc.Label[0]
+		}
+		result.Hits = append(result.Hits, hit)
+	}
+	return result, nil
+}
+
+// localizeLabels replaces the indexed default labels of hits with labels in
+// the requested language where available.
+func localizeLabels(ctx context.Context, language string, hits []SearchHit) error {
+	ids := make([]string, 0, len(hits))
+	for _, hit := range hits {
+		ids = append(ids, rdf2go.NewResource(hit.Subject).String())
+	}
+	labels, err := rdf.GetLabels(ctx, language, ids)
+	if err != nil {
+		return fmt.Errorf("loading hit labels: %w", err)
+	}
+	for i := range hits {
+		if label := labels[rdf2go.NewResource(hits[i].Subject).String()]; label != "" {
+			hits[i].Label = label
+		}
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package search
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"rdf-store-backend/base"
+	"slices"
+	"testing"
+)
+
+// useTextLanguages configures the languages of text analysis for a test.
+func useTextLanguages(t *testing.T, languages ...string) {
+	previousLanguages := base.Configuration.TextLanguages
+	base.Configuration.TextLanguages = languages
+	t.Cleanup(func() { base.Configuration.TextLanguages = previousLanguages })
+}
+
+func TestBuildSearchRequestTranslatesCriteria(t *testing.T) {
+	useTextLanguages(t, "de")
+	path := []string{"http://example.org/child", "http://example.org/score"}
+	request, err := buildSearchRequest(Query{
+		Profile:  "http://example.org/Profile",
+		Fulltext: "foo bar",
+		Sort:     "score desc",
+		Offset:   20,
+		Criteria: []Criterion{
+			{Path: path, Operator: OperatorRange, Min: "10"},
+			{Path: []string{"http://example.org/name"}, Operator: OperatorContains, Value: "a:b"},
+			{Path: []string{"http://example.org/created"}, Operator: OperatorEquals, Value: "2024-05-01", Datatype: "http://www.w3.org/2001/XMLSchema#date"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"docType:entity",
+		`shape:"http\:\/\/example.org\/Profile"`,
+		`_text_:*foo\ bar*`,
+		`{!parent which=docType:entity}(docType:value AND path:"5f508e2ada4ba4124cc00d65f039588f" AND valueNumber:["10" TO *])`,
+		`{!parent which=docType:entity}(docType:value AND path:"` + queryPathID([]string{"http://example.org/name"}) + `" AND (valueText:*a\:b* OR valueText_de:*a\:b* OR valueText_de:"a\:b"))`,
+		`{!parent which=docType:entity}(docType:value AND path:"` + queryPathID([]string{"http://example.org/created"}) + `" AND valueDate:"2024\-05\-01T00\:00\:00Z")`,
+	}
+	if !slices.Equal(request.Filter, expected) {
+		t.Fatalf("unexpected filters:\n%q\nexpected:\n%q", request.Filter, expected)
+	}
+	if request.Sort != "score desc" || request.Offset != 20 || request.Limit != defaultSearchLimit {
+		t.Fatalf("unexpected paging: %+v", request)
+	}
+}
+
+func TestBuildSearchRequestMatchesIRIsInBothNotations(t *testing.T) {
+	filter, err := criterionFilter(Criterion{Path: []string{"http://example.org/type"}, Operator: OperatorEquals, Value: "http://example.org/T"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{!parent which=docType:entity}(docType:value AND path:"` + queryPathID([]string{"http://example.org/type"}) + `" AND (valueString:"http\:\/\/example.org\/T" OR valueString:"<http\:\/\/example.org\/T>"))`
+	if filter != expected {
+		t.Fatalf("unexpected filter %q", filter)
+	}
+}
+
+func TestBuildSearchRequestRejectsInvalidQueries(t *testing.T) {
+	for name, query := range map[string]Query{
+		"limit":    {Limit: maxSearchLimit + 1},
+		"sort":     {Sort: "label desc"},
+		"operator": {Criteria: []Criterion{{Path: []string{"http://example.org/p"}, Operator: "near", Value: "x"}}},
+		"path":     {Criteria: []Criterion{{Operator: OperatorEquals, Value: "x"}}},
+		"number":   {Criteria: []Criterion{{Path: []string{"http://example.org/p"}, Operator: OperatorRange, Min: "ten"}}},
+		"range":    {Criteria: []Criterion{{Path: []string{"http://example.org/p"}, Operator: OperatorRange, Min: "a", Datatype: "http://www.w3.org/2001/XMLSchema#string"}}},
+		"geometry": {Criteria: []Criterion{{Path: []string{"http://example.org/p"}, Operator: OperatorGeoIntersects, Value: `POINT(1 2)") OR *:*`}}},
+	} {
+		if _, err := buildSearchRequest(query); !errors.Is(err, ErrInvalidQuery) {
+			t.Errorf("%s: expected invalid query error, got %v", name, err)
+		}
+	}
+}
+
+func TestCursorPagesUntilCursorMarkRepeats(t *testing.T) {
+	marks := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request searchRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Sort != "id asc" || request.Offset != 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		mark, _ := request.Params["cursorMark"].(string)
+		marks = append(marks, mark)
+		next, docs := "AoE2", `[{"id":"r|s2","resourceId":"r","subject":"s2"}]`
+		switch mark {
+		case "*":
+			next, docs = "AoE1", `[{"id":"r|s1","resourceId":"r","subject":"s1"}]`
+		case "AoE2":
+			docs = `[]`
+		}
+		fmt.Fprintf(w, `{"response":{"numFound":2,"docs":%s},"nextCursorMark":%q}`, docs, next)
+	}))
+	defer server.Close()
+	previousEndpoint := Endpoint
+	Endpoint = server.URL
+	t.Cleanup(func() { Endpoint = previousEndpoint })
+
+	cursor := NewCursor(SolrQuery{Filter: []string{"shape:x"}}, 1)
+	subjects := []string{}
+	for {
+		hits, err := cursor.Next(t.Context())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(hits) == 0 {
+			break
+		}
+		for _, hit := range hits {
+			subjects = append(subjects, hit.Subject)
+		}
+	}
+	if cursor.Total != 2 || !slices.Equal(subjects, []string{"s1", "s2"}) || !slices.Equal(marks, []string{"*", "AoE1", "AoE2"}) {
+		t.Fatalf("unexpected paging: total %d, subjects %v, marks %v", cursor.Total, subjects, marks)
+	}
+}
@@ -0,0 +1,233 @@
+package search
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"path"
+	"rdf-store-backend/base"
+	"rdf-store-backend/rdf"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// RankingFile is the optional JSON file with the ranking configuration.
+var RankingFile = base.EnvVar("SEARCH_RANKING_FILE", path.Join("local", "ranking.json"))
+
+// Ranking weights the relevance of full-text search hits.
+type Ranking struct {
+	// LabelBoost weights matches in entity labels.
+	LabelBoost float64 `json:"labelBoost"`
+	// ValueBoost weights matches in any text value of an entity.
+	ValueBoost float64 `json:"valueBoost"`
+	// Paths weights matches in the text values at specific paths, in addition
+	// to the rdfstore:searchBoost annotations of profiles.
+	Paths []PathBoost `json:"paths"`
+	// Recency multiplies the score of recently modified entities.
+	Recency RecencyBoost `json:"recency"`
+	// Edismax holds extra parameters of the edismax query over entity fields,
+	// e.g. "mm" or "tie". "qf" replaces the label and entity text fields.
+	Edismax map[string]string `json:"edismax"`
+}
+
+// PathBoost weights matches at a path in the segment notation of search criteria.
+type PathBoost struct {
+	Path  []string `json:"path"`
+	Boost float64  `json:"boost"`
+}
+
+// RecencyBoost multiplies scores by 1+Weight for entities modified now,
+// decaying to 1+Weight/2 after HalfLifeDays.
+type RecencyBoost struct {
+	Weight       float64 `json:"weight"`
+	HalfLifeDays float64 `json:"halfLifeDays"`
+}
+
+// DefaultRanking is used when no ranking file exists.
+var DefaultRanking = Ranking{
+	LabelBoost: 5,
+	ValueBoost: 1,
+	Recency:    RecencyBoost{Weight: 0.5, HalfLifeDays: 365},
+}
+
+var ranking = DefaultRanking
+
+// rankedPaths holds the boosted paths of each profile, and of all profiles
+// under the empty ID, so full-text searches do not walk the profiles. Profiles
+// without entry only have the configured path boosts.
+var rankedPaths struct {
+	sync.RWMutex
+	configured []IndexedPath
+	profiles   map[string][]IndexedPath
+}
+
+// edismaxParams are the edismax parameters a ranking file may set.
+var edismaxParams = map[string]bool{"qf": true, "mm": true, "tie": true, "pf": true, "ps": true, "qs": true, "q.op": true}
+
+// LoadRanking reads the ranking configuration from file. Defaults are kept for
+// unset members and used entirely if the file does not exist.
+// It returns an error if the file cannot be read or is invalid.
+func LoadRanking(file string) error {
+	loaded := DefaultRanking
+	data, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		ranking = loaded
+		UpdateRankedPaths()
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		return fmt.Errorf("parsing ranking file %s: %w", file, err)
+	}
+	if err := loaded.validate(); err != nil {
+		return fmt.Errorf("invalid ranking file %s: %w", file, err)
+	}
+	ranking = loaded
+	UpdateRankedPaths()
+	slog.Info("loaded search ranking", "file", file, "paths", len(loaded.Paths))
+	return nil
+}
+
+// UpdateRankedPaths computes the boosted paths of the loaded profiles. It is
+// called by LoadRanking and must be called whenever profiles are reloaded.
+func UpdateRankedPaths() {
+	profileIDs := slices.Sorted(maps.Keys(rdf.Profiles))
+	profiles := make(map[string][]IndexedPath, len(profileIDs)+1)
+	profiles[""] = ranking.boostedPaths(profileIDs)
+	for _, id := range profileIDs {
+		profiles[id] = ranking.boostedPaths([]string{id})
+	}
+	configured := ranking.boostedPaths(nil)
+	rankedPaths.Lock()
+	defer rankedPaths.Unlock()
+	rankedPaths.configured, rankedPaths.profiles = configured, profiles
+}
+
+// profileRankedPaths returns the boosted paths of a profile, or of all
+// profiles if it is empty.
+func profileRankedPaths(profileID string) []IndexedPath {
+	rankedPaths.RLock()
+	defer rankedPaths.RUnlock()
+	if paths, ok := rankedPaths.profiles[profileID]; ok {
+		return paths
+	}
+	return rankedPaths.configured
+}
+
+func (r Ranking) validate() error {
+	if r.LabelBoost < 0 || r.ValueBoost < 0 || r.Recency.Weight < 0 {
+		return errors.New("boosts must not be negative")
+	}
+	if r.Recency.Weight > 0 && r.Recency.HalfLifeDays <= 0 {
+		return errors.New("recency needs a positive halfLifeDays")
+	}
+	for _, boost := range r.Paths {
+		if len(boost.Path) == 0 || boost.Boost <= 0 {
+			return fmt.Errorf("path boost %v needs a path and a positive boost", boost.Path)
+		}
+	}
+	for name := range r.Edismax {
+		if !edismaxParams[name] {
+			return fmt.Errorf("unsupported edismax parameter %q", name)
+		}
+	}
+	return nil
+}
+
+// boostedPaths merges the configured path boosts with the search boosts of
+// the profiles. The larger boost wins.
+func (r Ranking) boostedPaths(profileIDs []string) []IndexedPath {
+	byID := make(map[string]IndexedPath)
+	for _, boost := range r.Paths {
+		id := queryPathID(boost.Path)
+		byID[id] = IndexedPath{Path: boost.Path, ID: id, boost: boost.Boost}
+	}
+	for _, id := range profileIDs {
+		paths, err := ProfilePaths(id)
+		if err != nil {
+			continue
+		}
+		for _, path := range paths {
+			if path.boost > byID[path.ID].boost {
+				byID[path.ID] = path
+			}
+		}
+	}
+	paths := make([]IndexedPath, 0, len(byID))
+	for _, path := range byID {
+		if path.boost > 0 {
+			paths = append(paths, path)
+		}
+	}
+	slices.SortFunc(paths, func(a, b IndexedPath) int { return strings.Compare(a.ID, b.ID) })
+	return paths
+}
+
+// applyRanking scores the entities of a full-text search. The query matches
+// all entities passing the filters, so ranking never changes the hits. Its
+// score sums an edismax query on entity fields and block-join queries on
+// text values, and is multiplied by the recency boost.
+func (r Ranking) applyRanking(request *searchRequest, term string, profileID string) {
+	if request.Params == nil {
+		request.Params = map[string]any{}
+	}
+	params := request.Params
+	params["rankTerm"] = term
+	params["rankAll"] = "*:*"
+	should := make([]string, 0)
+
+	entityParams := []string{"v=$rankTerm"}
+	params["rankEntityQf"] = fmt.Sprintf("labelText^%s _text_", formatBoost(r.LabelBoost))
+	for _, name := range slices.Sorted(maps.Keys(r.Edismax)) {
+		if name == "qf" {
+			params["rankEntityQf"] = r.Edismax[name]
+			continue
+		}
+		key := "rankEntity_" + strings.ReplaceAll(name, ".", "_")
+		params[key] = r.Edismax[name]
+		entityParams = append(entityParams, name+"=$"+key)
+	}
+	params["rankEntity"] = "{!edismax qf=$rankEntityQf " + strings.Join(entityParams, " ") + "}"
+	should = append(should, "should=$rankEntity")
+
+	params["rankValueQf"] = strings.Join(textFields(), " ")
+	params["rankValueText"] = "{!edismax qf=$rankValueQf v=$rankTerm}"
+	addValueBoost := func(name string, boost float64, pathID string) {
+		filter := "docType:value"
+		if pathID != "" {
+			filter = fmt.Sprintf(`+docType:value +path:"%s"`, pathID)
+		}
+		params[name+"Filter"] = filter
+		params[name+"Values"] = fmt.Sprintf("{!bool filter=$%sFilter must=$rankValueText}", name)
+		params[name+"Parents"] = fmt.Sprintf("{!parent which=docType:entity score=max v=$%sValues}", name)
+		params[name] = fmt.Sprintf("{!boost b=%s v=$%sParents}", formatBoost(boost), name)
+		should = append(should, "should=$"+name)
+	}
+	if r.ValueBoost > 0 {
+		addValueBoost("rankValues", r.ValueBoost, "")
+	}
+	for i, path := range profileRankedPaths(profileID) {
+		addValueBoost(fmt.Sprintf("rankPath%d", i), path.boost, path.ID)
+	}
+
+	query := "{!bool must=$rankAll " + strings.Join(should, " ") + "}"
+	if r.Recency.Weight > 0 {
+		halfLife := r.Recency.HalfLifeDays * 24 * 60 * 60 * 1000
+		params["rankRecency"] = fmt.Sprintf("sum(1,product(%s,recip(ms(NOW/HOUR,lastModified),%s,1,1)))",
+			formatBoost(r.Recency.Weight), strconv.FormatFloat(1/halfLife, 'g', -1, 64))
+		params["rankScored"] = query
+		query = "{!boost b=$rankRecency v=$rankScored}"
+	}
+	request.Query = query
+}
+
+func formatBoost(boost float64) string {
+	return strconv.FormatFloat(boost, 'f', -1, 64)
+}
@@ -0,0 +1,113 @@
+package search
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"rdf-store-backend/rdf"
+	"strings"
+	"testing"
+)
+
+func TestLoadRankingKeepsDefaultsAndValidates(t *testing.T) {
+	t.Cleanup(func() { ranking = DefaultRanking })
+	dir := t.TempDir()
+	if err := LoadRanking(filepath.Join(dir, "missing.json")); err != nil || ranking.LabelBoost != DefaultRanking.LabelBoost {
+		t.Fatalf("expected defaults without ranking file, got %+v, %v", ranking, err)
+	}
+
+	file := filepath.Join(dir, "ranking.json")
+	os.WriteFile(file, []byte(`{"labelBoost":8,"edismax":{"mm":"100%"},"paths":[{"path":["http://purl.org/dc/terms/title"],"boost":4}]}`), 0o644)
+	if err := LoadRanking(file); err != nil {
+		t.Fatal(err)
+	}
+	if ranking.LabelBoost != 8 || ranking.ValueBoost != DefaultRanking.ValueBoost || ranking.Recency != DefaultRanking.Recency || len(ranking.Paths) != 1 {
+		t.Fatalf("unexpected ranking %+v", ranking)
+	}
+
+	for _, invalid := range []string{`{"labelBoost":-1}`, `{"edismax":{"defType":"lucene"}}`, `{"paths":[{"path":[],"boost":2}]}`, `{"recency":{"weight":1,"halfLifeDays":0}}`, `{`} {
+		os.WriteFile(file, []byte(invalid), 0o644)
+		if err := LoadRanking(file); err == nil {
+			t.Errorf("expected error for %s", invalid)
+		}
+	}
+}
+
+func TestFulltextSearchIsRankedWithPathBoosts(t *testing.T) {
+	useStatisticsProfiles(t)
+	useTextLanguages(t)
+	rdf.Profiles[sampleID].Properties[massPath][0].SearchBoost = 2
+	previousRanking := ranking
+	ranking = Ranking{LabelBoost: 3, ValueBoost: 1, Paths: []PathBoost{{Path: []string{takenPath}, Boost: 1.5}}, Recency: RecencyBoost{Weight: 1, HalfLifeDays: 1}, Edismax: map[string]string{"mm": "100%"}}
+	UpdateRankedPaths()
+	t.Cleanup(func() {
+		ranking = previousRanking
+		UpdateRankedPaths()
+	})
+
+	request, err := buildSearchRequest(Query{Profile: sampleID, Fulltext: " soil "})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if request.Sort != rankedSearchSort || request.Query != "{!boost b=$rankRecency v=$rankScored}" {
+		t.Fatalf("unexpected ranked request %+v", request)
+	}
+	params := request.Params
+	expected := map[string]string{
+		"rankTerm":          "soil",
+		"rankScored":        "{!bool must=$rankAll should=$rankEntity should=$rankValues should=$rankPath0 should=$rankPath1}",
+		"rankEntity":        "{!edismax qf=$rankEntityQf v=$rankTerm mm=$rankEntity_mm}",
+		"rankEntityQf":      "labelText^3 _text_",
+		"rankEntity_mm":     "100%",
+		"rankValueQf":       "valueText",
+		"rankValuesParents": "{!parent which=docType:entity score=max v=$rankValuesValues}",
+		"rankRecency":       "sum(1,product(1,recip(ms(NOW/HOUR,lastModified),1.1574074074074074e-08,1,1)))",
+	}
+	for name, value := range expected {
+		if params[name] != value {
+			t.Errorf("expected param %s=%q, got %q", name, value, params[name])
+		}
+	}
+	boosts := []string{fmt.Sprint(params["rankPath0"], params["rankPath0Filter"]), fmt.Sprint(params["rankPath1"], params["rankPath1Filter"])}
+	for _, path := range []struct {
+		segments []string
+		boost    string
+	}{{[]string{massPath}, "b=2 "}, {[]string{takenPath}, "b=1.5 "}} {
+		found := false
+		for _, boost := range boosts {
+			found = found || (strings.Contains(boost, path.boost) && strings.Contains(boost, queryPathID(path.segments)))
+		}
+		if !found {
+			t.Errorf("missing boost %s of path %v in %q", path.boost, path.segments, boosts)
+		}
+	}
+
+	request, err = buildSearchRequest(Query{Profile: sampleID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if request.Query != "*:*" || request.Sort != defaultSearchSort || request.Params != nil {
+		t.Fatalf("unexpected unranked request %+v", request)
+	}
+}
+
+func TestRankedPathsAreComputedWhenProfilesLoad(t *testing.T) {
+	useStatisticsProfiles(t)
+	rdf.Profiles[sampleID].Properties[massPath][0].SearchBoost = 2
+	t.Cleanup(UpdateRankedPaths)
+	UpdateRankedPaths()
+	// later changes only apply once the ranked paths are updated again
+	rdf.Profiles[sampleID].Properties[massPath][0].SearchBoost = 3
+	for _, profileID := range []string{sampleID, ""} {
+		if paths := profileRankedPaths(profileID); len(paths) != 1 || paths[0].boost != 2 || paths[0].ID != queryPathID([]string{massPath}) {
+			t.Errorf("unexpected ranked paths of profile %q: %+v", profileID, paths)
+		}
+	}
+	if paths := profileRankedPaths("http://example.org/Unknown"); len(paths) != 0 {
+		t.Errorf("expected only configured paths for unknown profiles, got %+v", paths)
+	}
+	UpdateRankedPaths()
+	if paths := profileRankedPaths(sampleID); len(paths) != 1 || paths[0].boost != 3 {
+		t.Errorf("expected updated boost, got %+v", paths)
+	}
+}
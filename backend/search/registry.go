@@ -0,0 +1,198 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"rdf-store-backend/rdf"
+	"rdf-store-backend/shacl"
+	"slices"
+	"strings"
+)
+
+// maxRegisteredPaths bounds the registry documents read by a single request.
+const maxRegisteredPaths = 10000
+
+// RegisteredPath resolves a path ID emitted by the indexer to its SHACL path.
+type RegisteredPath struct {
+	ID string `json:"id"`
+	// Path holds the segments in the notation of search criteria.
+	Path []string `json:"path"`
+	// Labels holds the label of each segment, empty if none is known.
+	Labels []string `json:"labels"`
+	// RootShapes are the shapes from which the indexer reached the path.
+	RootShapes []string `json:"rootShapes"`
+	// Datatype is the datatype of the first literal indexed at the path, if any.
+	Datatype string `json:"datatype,omitempty"`
+}
+
+// registerPath records that the indexer emitted a value at a path. The
+// registry documents are written along with the entity documents. Each
+// resource and root shape gets its own document linked by resourceId, so
+// indexing a resource never has to read or merge the registrations of other
+// resources and deindexing it removes its registrations. Registry documents
+// have no children and never match docType:value, so block joins are
+// unaffected.
+func (indexer *queryIndexer) registerPath(shapePath []string, pathID string, datatype string) {
+	registry := indexer.traversal.paths
+	if registry == nil {
+		return
+	}
+	registrationKey := pathID + "|" + indexer.rootShape
+	resourceID := indexer.metadata.Id.RawValue()
+	id := "path|" + registrationKey + "|" + resourceID
+	if registration, ok := registry[id]; ok {
+		if _, typed := (*registration)["datatype"]; !typed && datatype != "" {
+			(*registration)["datatype"] = datatype
+		}
+		return
+	}
+	registration := &document{
+		"id":           id,
+		"docType":      "path",
+		"resourceId":   resourceID,
+		"registration": registrationKey,
+		"path":         pathID,
+		"pathIris":     slices.Clone(shapePath),
+		"shape":        indexer.rootShape,
+	}
+	if datatype != "" {
+		(*registration)["datatype"] = datatype
+	}
+	registry[id] = registration
+}
+
+// pathDocuments returns the registry documents ordered by ID.
+func pathDocuments(registry map[string]*document) []*document {
+	docs := make([]*document, 0, len(registry))
+	for _, id := range slices.Sorted(maps.Keys(registry)) {
+		docs = append(docs, registry[id])
+	}
+	return docs
+}
+
+// RegisteredPaths lists the registered paths ordered by path. If profile is
+// set, only paths reached from it are listed. Segment labels are resolved in
+// language, or the default label language if it is empty.
+// It returns an error if Solr or the label lookup fails.
+func RegisteredPaths(ctx context.Context, profile string, language string) ([]RegisteredPath, error) {
+	filter := []string{"docType:path"}
+	if profile != "" {
+		// join so that the root shapes of a path include other profiles reaching it
+		filter = append(filter, "{!join from=path to=path}docType:path AND shape:"+escapeQueryValue(profile))
+	}
+	return findRegisteredPaths(ctx, filter, language)
+}
+
+// FindRegisteredPath resolves a path ID. Segment labels are resolved in
+// language, or the default label language if it is empty.
+// It returns an error wrapping rdf.ErrNotFound if the ID was never emitted.
+func FindRegisteredPath(ctx context.Context, id string, language string) (RegisteredPath, error) {
+	paths, err := findRegisteredPaths(ctx, []string{"docType:path", "path:" + escapeQueryValue(id)}, language)
+	if err != nil {
+		return RegisteredPath{}, err
+	}
+	if len(paths) == 0 {
+		return RegisteredPath{}, fmt.Errorf("%w: path %q", rdf.ErrNotFound, id)
+	}
+	return paths[0], nil
+}
+
+// registrationFilter keeps one registry document per path and root shape,
+// preferring one with a datatype.
+const registrationFilter = "{!collapse field=registration sort='datatype desc'}"
+
+func findRegisteredPaths(ctx context.Context, filter []string, language string) ([]RegisteredPath, error) {
+	body, err := postQuery(ctx, &searchRequest{
+		Query:  "*:*",
+		Filter: append(filter, registrationFilter),
+		Sort:   "id asc",
+		Limit:  maxRegisteredPaths,
+		Fields: []string{"path", "pathIris", "shape", "datatype"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	paths, err := parseRegisteredPaths(body)
+	if err != nil {
+		return nil, err
+	}
+	return paths, labelSegments(ctx, language, paths)
+}
+
+// parseRegisteredPaths merges the registry documents of each path ID.
+func parseRegisteredPaths(body []byte) ([]RegisteredPath, error) {
+	var payload struct {
+		Response struct {
+			Docs []struct {
+				Path     string   `json:"path"`
+				PathIris []string `json:"pathIris"`
+				Shape    []string `json:"shape"`
+				Datatype string   `json:"datatype"`
+			} `json:"docs"`
+		} `json:"response"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*RegisteredPath)
+	for _, doc := range payload.Response.Docs {
+		path, ok := byID[doc.Path]
+		if !ok {
+			path = &RegisteredPath{ID: doc.Path, Path: doc.PathIris, RootShapes: []string{}}
+			byID[doc.Path] = path
+		}
+		for _, shape := range doc.Shape {
+			if !slices.Contains(path.RootShapes, shape) {
+				path.RootShapes = append(path.RootShapes, shape)
+			}
+		}
+		if path.Datatype == "" {
+			path.Datatype = doc.Datatype
+		}
+	}
+	paths := make([]RegisteredPath, 0, len(byID))
+	for _, path := range byID {
+		slices.Sort(path.RootShapes)
+		paths = append(paths, *path)
+	}
+	slices.SortFunc(paths, func(a, b RegisteredPath) int {
+		return strings.Compare(strings.Join(a.Path, "\x00"), strings.Join(b.Path, "\x00"))
+	})
+	return paths, nil
+}
+
+// labelSegments adds the labels of the path segments.
+func labelSegments(ctx context.Context, language string, paths []RegisteredPath) error {
+	ids := make([]string, 0)
+	for _, path := range paths {
+		for _, segment := range path.Path {
+			if shacl.IsPredicateKey(segment) {
+				ids = append(ids, "<"+segment+">")
+			}
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+	var labels map[string]string
+	var err error
+	if language == "" {
+		labels, err = rdf.GetDefaultLabels(ctx, ids)
+	} else {
+		labels, err = rdf.GetLabels(ctx, language, ids)
+	}
+	if err != nil {
+		return fmt.Errorf("loading path labels: %w", err)
+	}
+	for i := range paths {
+		paths[i].Labels = make([]string, len(paths[i].Path))
+		for j, segment := range paths[i].Path {
+			paths[i].Labels[j] = labels["<"+segment+">"]
+		}
+	}
+	return nil
+}
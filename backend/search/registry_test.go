@@ -0,0 +1,90 @@
+package search
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"rdf-store-backend/rdf"
+	"slices"
+	"testing"
+)
+
+func TestBuildResourceDocumentsRegistersEmittedPaths(t *testing.T) {
+	fx := newMeasurementFixture(t)
+	paths := make(map[string]*document)
+	if _, err := buildResourceDocuments(fx.graph, fx.metadata, resourceIndexOptions{paths: paths}); err != nil {
+		t.Fatal(err)
+	}
+	docs := pathDocuments(paths)
+	if len(docs) != 2 {
+		t.Fatalf("expected two path registrations, got %v", docs)
+	}
+	byShape := make(map[string]document)
+	for _, doc := range docs {
+		byShape[(*doc)["shape"].(string)] = *doc
+	}
+	nested := []string{fx.childPath, fx.valuePath}
+	root := byShape[fx.rootID]
+	if root["docType"] != "path" || root["path"] != queryPathID(nested) || !slices.Equal(root["pathIris"].([]string), nested) ||
+		root["datatype"] != "http://www.w3.org/2001/XMLSchema#decimal" || root["id"] != "path|"+queryPathID(nested)+"|"+fx.rootID+"|"+fx.resourceID ||
+		root["registration"] != queryPathID(nested)+"|"+fx.rootID {
+		t.Fatalf("unexpected registration of the root shape %v", root)
+	}
+	// the embedded measurement is also traversed from its own shape
+	if measurement := byShape["http://example.org/Measurement"]; measurement["path"] != queryPathID([]string{fx.valuePath}) {
+		t.Fatalf("unexpected registration of the measurement shape %v", measurement)
+	}
+	// deindexing deletes by resourceId, which must cover the registrations
+	for _, doc := range docs {
+		if (*doc)["resourceId"] != fx.resourceID {
+			t.Fatalf("registration %v is not linked to the resource", *doc)
+		}
+	}
+}
+
+func TestRegisteredPathsMergesRootShapes(t *testing.T) {
+	var request map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&request)
+		w.Write([]byte(`{"response":{"docs":[
+			{"path":"b","pathIris":["http://example.org/owner","http://xmlns.com/foaf/0.1/name"],"shape":["http://example.org/Sample"]},
+			{"path":"a","pathIris":["http://example.org/mass"],"shape":["http://example.org/Sample"],"datatype":"http://www.w3.org/2001/XMLSchema#double"},
+			{"path":"a","pathIris":["http://example.org/mass"],"shape":["http://example.org/BaseSample"]}]}}`))
+	}))
+	defer server.Close()
+	previousEndpoint := Endpoint
+	Endpoint = server.URL
+	t.Cleanup(func() { Endpoint = previousEndpoint })
+
+	paths, err := RegisteredPaths(t.Context(), "http://example.org/Sample", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	filter := request["filter"].([]any)
+	if len(filter) != 3 || filter[1] != `{!join from=path to=path}docType:path AND shape:"http\:\/\/example.org\/Sample"` ||
+		filter[2] != registrationFilter {
+		t.Fatalf("unexpected filter %v", filter)
+	}
+	if len(paths) != 2 || paths[0].ID != "a" || paths[1].ID != "b" {
+		t.Fatalf("expected paths ordered by segments, got %+v", paths)
+	}
+	if !slices.Equal(paths[0].RootShapes, []string{"http://example.org/BaseSample", "http://example.org/Sample"}) ||
+		paths[0].Datatype != "http://www.w3.org/2001/XMLSchema#double" || len(paths[1].Labels) != 2 {
+		t.Fatalf("unexpected merged path %+v", paths)
+	}
+}
+
+func TestFindRegisteredPathReportsUnknownIDs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"response":{"docs":[]}}`))
+	}))
+	defer server.Close()
+	previousEndpoint := Endpoint
+	Endpoint = server.URL
+	t.Cleanup(func() { Endpoint = previousEndpoint })
+
+	if _, err := FindRegisteredPath(t.Context(), "unknown", ""); !errors.Is(err, rdf.ErrNotFound) {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+}
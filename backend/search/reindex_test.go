@@ -0,0 +1,40 @@
+package search
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParseReindexScope(t *testing.T) {
+	scope, err := ParseReindexScope(" http://example.org/Profile ", "2024-05-01", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scope.Profile != "http://example.org/Profile" || !scope.Since.Equal(time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)) || scope.IsEmpty() {
+		t.Fatalf("unexpected scope: %#v", scope)
+	}
+	scope, err = ParseReindexScope("", "2024-05-01T10:00:00+02:00", "")
+	if err != nil || !scope.Since.Equal(time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected scope %#v, error %v", scope, err)
+	}
+	if _, err := ParseReindexScope("", "last week", ""); err == nil {
+		t.Fatal("expected error for invalid timestamp")
+	}
+	if scope, _ := ParseReindexScope(" ", "", ""); !scope.IsEmpty() {
+		t.Fatalf("expected empty scope, got %#v", scope)
+	}
+}
+
+func TestIntersectIDs(t *testing.T) {
+	result := intersectIDs([][]string{
+		{"http://example.org/b", "http://example.org/a", "http://example.org/a", "http://example.org/c"},
+		{"http://example.org/c", "http://example.org/a"},
+	})
+	if !slices.Equal(result, []string{"http://example.org/a", "http://example.org/c"}) {
+		t.Fatalf("unexpected intersection: %v", result)
+	}
+	if result := intersectIDs(nil); len(result) != 0 {
+		t.Fatalf("expected empty intersection, got %v", result)
+	}
+}
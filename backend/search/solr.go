@@ -9,16 +9,20 @@ import (
 	"log/slog"
 	"net/http"
 	"rdf-store-backend/base"
+	"rdf-store-backend/httpclient"
+	"rdf-store-backend/metrics"
 	"reflect"
 	"regexp"
 	"slices"
+	"time"
 
 	"github.com/stevenferrer/solr-go"
 )
 
 var Endpoint = base.EnvVar("SOLR_ENDPOINT", "http://localhost:8983")
 var numShards = base.EnvVarAsInt("SOLR_NUM_SHARDS", 1)
-var client = solr.NewJSONClient(Endpoint)
+var httpClient = httpclient.New(metrics.ServiceSolr, httpclient.Timeout("SOLR_TIMEOUT", 60))
+var client = solr.NewJSONClient(Endpoint).WithRequestSender(solr.NewDefaultRequestSender().WithHTTPClient(httpClient))
 
 type document map[string]any
 
@@ -55,7 +59,7 @@ func checkCollectionExists(ctx context.Context) (bool, error) {
 	if err != nil {
 		return false, err
 	}
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := httpclient.Do(httpClient, req, true)
 	if err != nil {
 		return false, err
 	}
@@ -72,23 +76,39 @@ func checkCollectionExists(ctx context.Context) (bool, error) {
 	return slices.Contains(payload.Collections, base.SolrIndex), nil
 }
 
+// Ping checks that Solr is reachable and the configured collection exists.
+// It returns an error when the collection listing fails or the collection is missing.
+func Ping(ctx context.Context) error {
+	exists, err := checkCollectionExists(ctx)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("solr collection %s does not exist", base.SolrIndex)
+	}
+	return nil
+}
+
 // recreateCollection drops and rebuilds the Solr collection and schema.
 // It returns an error if any Solr operation fails.
-func recreateCollection() (err error) {
+func recreateCollection(ctx context.Context) (err error) {
 	slog.Debug("recreating solr collection", "endpoint", Endpoint, "collection", base.SolrIndex)
-	if err := client.DeleteCollection(context.Background(), solr.NewCollectionParams().Name(base.SolrIndex)); err != nil {
+	if err := client.DeleteCollection(ctx, solr.NewCollectionParams().Name(base.SolrIndex)); err != nil {
 		slog.Warn("collection couldn't be deleted", "error", err)
 	}
-	if err = client.CreateCollection(context.Background(), solr.NewCollectionParams().Name(base.SolrIndex).NumShards(numShards)); err != nil {
+	if err = client.CreateCollection(ctx, solr.NewCollectionParams().Name(base.SolrIndex).NumShards(numShards)); err != nil {
 		return
 	}
-	if err = client.AddFields(context.Background(), base.SolrIndex, createCollectionSchema()...); err != nil {
+	if err = patchSchema(ctx, map[string]any{"add-field-type": append(createTextFieldTypes(), createSuggestFieldType(), createDateRangeFieldType())}); err != nil {
 		return
 	}
-	if err = client.AddCopyFields(context.Background(), base.SolrIndex, solr.CopyField{Source: "*", Dest: "_text_"}); err != nil {
+	if err = client.AddFields(ctx, base.SolrIndex, createCollectionSchema()...); err != nil {
 		return
 	}
-	if err = patchLocationField(); err != nil {
+	if err = client.AddCopyFields(ctx, base.SolrIndex, append([]solr.CopyField{{Source: "*", Dest: "_text_"}, {Source: "label", Dest: "labelText"}}, suggestCopyFields()...)...); err != nil {
+		return
+	}
+	if err = patchLocationField(ctx); err != nil {
 		return
 	}
 	return
@@ -98,8 +118,8 @@ func recreateCollection() (err error) {
 // See https://solr.apache.org/guide/solr/latest/query-guide/spatial-search.html#jts-and-polygons-flat
 // patchLocationField enables spatial WKT indexing for the location field.
 // It returns an error if the Solr schema patch fails.
-func patchLocationField() error {
-	body := map[string]any{
+func patchLocationField(ctx context.Context) error {
+	return patchSchema(ctx, map[string]any{
 		"replace-field-type": map[string]any{
 			"name":                  "location_rpt",
 			"class":                 "solr.SpatialRecursivePrefixTreeFieldType",
@@ -110,26 +130,36 @@ func patchLocationField() error {
 			"maxDistErr":            "0.001",
 			"distanceUnits":         "kilometers",
 		},
-	}
+	})
+}
+
+// patchSchema posts schema API commands that solr-go doesn't support.
+// It returns an error if the Solr schema patch fails.
+func patchSchema(ctx context.Context, body map[string]any) error {
 	data, err := json.Marshal(body)
 	if err != nil {
 		return err
 	}
-	// since solr-go doesn't support this we'll simply post directly to solr
-	resp, err := http.Post(fmt.Sprintf("%s/solr/%s/schema", Endpoint, base.SolrIndex), "application/json", bytes.NewReader(data))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/solr/%s/schema", Endpoint, base.SolrIndex), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := httpClient.Do(req)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != 200 {
-		return fmt.Errorf("failed patching solr schema. status was %d", resp.StatusCode)
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed patching solr schema. status was %d: %s", resp.StatusCode, extractSolrError(body))
 	}
 	return nil
 }
 
 // updateDocs submits document updates and commits them in Solr.
 // It returns an error if the update or commit fails.
-func updateDocs(docs []*document) error {
+func updateDocs(ctx context.Context, docs []*document) error {
 	commands := make([]any, 0, len(docs))
 	for _, doc := range docs {
 		// Documents are sent unwrapped. The {"doc": {...}} element form is
@@ -138,7 +168,19 @@ func updateDocs(docs []*document) error {
 		// still defines the block-join _root_/_nest_path_ fields).
 		commands = append(commands, doc)
 	}
-	return solrUpdateBody(map[string]any{"add": commands}, true)
+	if err := solrUpdateBody(ctx, map[string]any{"add": commands}, true); err != nil {
+		return err
+	}
+	counts := make(map[string]int)
+	for _, doc := range docs {
+		counts[fmt.Sprint((*doc)["docType"])]++
+		children, _ := (*doc)["_childDocuments_"].([]any)
+		counts["value"] += len(children)
+	}
+	for docType, count := range counts {
+		metrics.IndexedDocuments.WithLabelValues(docType).Add(float64(count))
+	}
+	return nil
 }
 
 var luceneSpecialCharacters = regexp.MustCompile(`[+\-&|!(){}\[\]^"~*?:\\/]`)
@@ -154,9 +196,9 @@ func escapeQueryValue(value string) string {
 // deleteByResourceId deletes all search documents belonging to a resource.
 // The id clause keeps compatibility with documents indexed by older versions.
 // It returns an error if the delete or commit fails.
-func deleteByResourceId(resourceId string) error {
+func deleteByResourceId(ctx context.Context, resourceId string) error {
 	escaped := escapeQueryValue(resourceId)
-	return solrUpdateBody(map[string]any{"delete": map[string]any{"query": fmt.Sprintf("id:%s OR resourceId:%s", escaped, escaped)}}, true)
+	return solrUpdateBody(ctx, map[string]any{"delete": map[string]any{"query": fmt.Sprintf("id:%s OR resourceId:%s", escaped, escaped)}}, true)
 }
 
 // solrUpdateBody posts an update payload to the collection's /update handler
@@ -164,7 +206,7 @@ func deleteByResourceId(resourceId string) error {
 // member that may be an array or an object; the solr-go client decodes it as
 // []string and aborts response parsing, hiding the real message, so update
 // responses are handled directly.
-func solrUpdateBody(payload any, commit bool) error {
+func solrUpdateBody(ctx context.Context, payload any, commit bool) error {
 	data, err := json.Marshal(payload)
 	if err != nil {
 		return err
@@ -173,10 +215,18 @@ func solrUpdateBody(payload any, commit bool) error {
 	if commit {
 		urlStr += "?commit=true"
 	}
-	resp, err := http.Post(urlStr, "application/json", bytes.NewReader(data))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, urlStr, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	start := time.Now()
+	resp, err := httpClient.Do(req)
 	if err != nil {
+		metrics.ObserveBackendWrite(metrics.ServiceSolr, "update", start, 0, err)
 		return err
 	}
+	metrics.ObserveBackendWrite(metrics.ServiceSolr, "update", start, resp.StatusCode, nil)
 	defer resp.Body.Close()
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
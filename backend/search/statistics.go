@@ -0,0 +1,364 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"rdf-store-backend/rdf"
+	"strings"
+	"time"
+)
+
+const (
+	histogramBuckets  = 10
+	defaultValueLimit = 100
+)
+
+// PathStatistics summarizes the indexed values of a path across the entities
+// conforming to a profile. Only the statistics of value types present at the
+// path are set.
+type PathStatistics struct {
+	IndexedPath
+	// Entities is the number of entities with at least one value.
+	Entities int `json:"entities"`
+	// Values counts the most frequent string, text and IRI values.
+	Values   []ValueCount     `json:"values,omitempty"`
+	Numbers  *RangeStatistics `json:"numbers,omitempty"`
+	Dates    *RangeStatistics `json:"dates,omitempty"`
+	Booleans *BooleanCounts   `json:"booleans,omitempty"`
+}
+
+// ValueCount is the number of entities having a value. IRIs are given in
+// angle brackets, as indexed, and labelled where a label is known.
+type ValueCount struct {
+	Value string `json:"value"`
+	Label string `json:"label,omitempty"`
+	Count int    `json:"count"`
+}
+
+// RangeStatistics holds the bounds and an equal-width histogram of numbers
+// or dates. Bounds of dates are ISO 8601 strings.
+type RangeStatistics struct {
+	Min       any               `json:"min"`
+	Max       any               `json:"max"`
+	Histogram []HistogramBucket `json:"histogram"`
+}
+
+// HistogramBucket counts the values in [Start, End). The last bucket
+// includes End.
+type HistogramBucket struct {
+	Start any `json:"start"`
+	End   any `json:"end"`
+	Count int `json:"count"`
+}
+
+// BooleanCounts counts boolean values.
+type BooleanCounts struct {
+	True  int `json:"true"`
+	False int `json:"false"`
+}
+
+// StatisticsQuery selects the paths to summarize.
+type StatisticsQuery struct {
+	Profile string
+	// Path restricts the statistics to a single path. All indexed paths of the
+	// profile are summarized when it is empty.
+	Path []string
+	// Language selects the language of value labels.
+	Language string
+	// Limit is the maximum number of values counted per path.
+	Limit int
+	// Unit converts the number statistics of a single path from the canonical
+	// unit of QuantityKind, as in criteria.
+	Unit         string
+	QuantityKind string
+	IsDelta      bool
+}
+
+// pathFacet is the Solr facet response of a single path.
+type pathFacet struct {
+	Entities int `json:"entities"`
+	Values   struct {
+		Buckets []struct {
+			Val      string `json:"val"`
+			Entities int    `json:"entities"`
+		} `json:"buckets"`
+	} `json:"values"`
+	Booleans struct {
+		Buckets []struct {
+			Val      bool `json:"val"`
+			Entities int  `json:"entities"`
+		} `json:"buckets"`
+	} `json:"booleans"`
+	NumberMin *float64 `json:"numberMin"`
+	NumberMax *float64 `json:"numberMax"`
+	DateMin   string   `json:"dateMin"`
+	DateMax   string   `json:"dateMax"`
+}
+
+// PathValueStatistics computes value statistics for the indexed paths of a
+// profile with two faceting requests: one for counts and bounds and one for
+// the histograms within these bounds.
+// It returns an error wrapping ErrInvalidQuery for unknown profiles or paths.
+func PathValueStatistics(ctx context.Context, query StatisticsQuery) ([]PathStatistics, error) {
+	var paths []IndexedPath
+	canonical := ""
+	if query.Unit != "" {
+		if len(query.Path) == 0 {
+			return nil, fmt.Errorf("%w: a unit needs a path", ErrInvalidQuery)
+		}
+		var err error
+		if canonical, err = quantityUnit(query.Unit, query.QuantityKind); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidQuery, err)
+		}
+	}
+	if len(query.Path) > 0 {
+		path, err := FindProfilePath(query.Profile, query.Path)
+		if err != nil {
+			return nil, err
+		}
+		paths = []IndexedPath{path}
+	} else {
+		var err error
+		if paths, err = ProfilePaths(query.Profile); err != nil {
+			return nil, err
+		}
+	}
+	if query.Limit <= 0 {
+		query.Limit = defaultValueLimit
+	}
+	if len(paths) == 0 {
+		return []PathStatistics{}, nil
+	}
+	body, err := postQuery(ctx, buildStatisticsRequest(query.Profile, paths, query.Limit))
+	if err != nil {
+		return nil, err
+	}
+	statistics, err := parseStatistics(paths, body)
+	if err != nil {
+		return nil, err
+	}
+	if request := buildHistogramRequest(query.Profile, statistics); request != nil {
+		if body, err = postQuery(ctx, request); err != nil {
+			return nil, err
+		}
+		if err := parseHistograms(statistics, body); err != nil {
+			return nil, err
+		}
+	}
+	if err := labelValues(ctx, query.Language, statistics); err != nil {
+		return nil, err
+	}
+	if canonical != "" {
+		convertNumbers(statistics, canonical, query.Unit, query.IsDelta)
+	}
+	return statistics, nil
+}
+
+// statisticsRequest selects the entities conforming to a profile. Facets
+// switch to the value documents of a path with a block-join domain.
+func statisticsRequest(profile string, facets map[string]any) map[string]any {
+	return map[string]any{
+		"query":  "docType:entity",
+		"filter": []string{"shape:" + escapeQueryValue(profile)},
+		"limit":  0,
+		"facet":  facets,
+	}
+}
+
+// pathDomain selects the value documents of a path, leaving out the broader
+// concepts added to IRI values.
+func pathDomain(path IndexedPath) map[string]any {
+	return map[string]any{"blockChildren": "docType:entity", "filter": []string{"docType:value", fmt.Sprintf(`path:"%s"`, path.ID), "-expanded:true"}}
+}
+
+func buildStatisticsRequest(profile string, paths []IndexedPath, limit int) map[string]any {
+	facets := make(map[string]any, len(paths))
+	for i, path := range paths {
+		facets[fmt.Sprintf("p%d", i)] = map[string]any{
+			"type":   "query",
+			"q":      "*:*",
+			"domain": pathDomain(path),
+			"facet": map[string]any{
+				"entities":  "uniqueBlock(_root_)",
+				"values":    entityTerms("valueString", limit),
+				"booleans":  entityTerms("valueBoolean", 2),
+				"numberMin": "min(valueNumber)",
+				"numberMax": "max(valueNumber)",
+				"dateMin":   "min(valueDate)",
+				"dateMax":   "max(valueDate)",
+			},
+		}
+	}
+	return statisticsRequest(profile, facets)
+}
+
+// entityTerms counts the entities per value of a field rather than the value
+// documents.
+func entityTerms(field string, limit int) map[string]any {
+	return map[string]any{
+		"type":  "terms",
+		"field": field,
+		"limit": limit,
+		"sort":  "entities desc",
+		"facet": map[string]any{"entities": "uniqueBlock(_root_)"},
+	}
+}
+
+func parseStatistics(paths []IndexedPath, body []byte) ([]PathStatistics, error) {
+	var payload struct {
+		Facets map[string]json.RawMessage `json:"facets"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+	statistics := make([]PathStatistics, len(paths))
+	for i, path := range paths {
+		statistics[i].IndexedPath = path
+		raw, ok := payload.Facets[fmt.Sprintf("p%d", i)]
+		if !ok {
+			continue
+		}
+		var facet pathFacet
+		if err := json.Unmarshal(raw, &facet); err != nil {
+			return nil, fmt.Errorf("parsing statistics of path %q: %w", path.Path, err)
+		}
+		statistics[i].Entities = facet.Entities
+		for _, bucket := range facet.Values.Buckets {
+			statistics[i].Values = append(statistics[i].Values, ValueCount{Value: bucket.Val, Count: bucket.Entities})
+		}
+		if len(facet.Booleans.Buckets) > 0 {
+			statistics[i].Booleans = &BooleanCounts{}
+			for _, bucket := range facet.Booleans.Buckets {
+				if bucket.Val {
+					statistics[i].Booleans.True = bucket.Entities
+				} else {
+					statistics[i].Booleans.False = bucket.Entities
+				}
+			}
+		}
+		if facet.NumberMin != nil && facet.NumberMax != nil {
+			statistics[i].Numbers = &RangeStatistics{Min: *facet.NumberMin, Max: *facet.NumberMax, Histogram: []HistogramBucket{}}
+		}
+		if facet.DateMin != "" && facet.DateMax != "" {
+			statistics[i].Dates = &RangeStatistics{Min: facet.DateMin, Max: facet.DateMax, Histogram: []HistogramBucket{}}
+		}
+	}
+	return statistics, nil
+}
+
+// buildHistogramRequest builds range facets over the bounds of all paths with
+// numbers or dates.
+// It returns nil if no path has a non-empty range.
+func buildHistogramRequest(profile string, statistics []PathStatistics) map[string]any {
+	facets := make(map[string]any)
+	for i, stats := range statistics {
+		if stats.Numbers != nil {
+			lower, upper := stats.Numbers.Min.(float64), stats.Numbers.Max.(float64)
+			if lower < upper {
+				// widen the gap slightly so rounding never adds a bucket
+				facets[fmt.Sprintf("n%d", i)] = rangeFacet(stats.IndexedPath, "valueNumber", lower, upper, (upper-lower)/histogramBuckets*(1+1e-9))
+			}
+		}
+		if stats.Dates != nil {
+			lower, errLower := time.Parse(time.RFC3339, stats.Dates.Min.(string))
+			upper, errUpper := time.Parse(time.RFC3339, stats.Dates.Max.(string))
+			if errLower == nil && errUpper == nil && lower.Before(upper) {
+				gap := int64(math.Ceil(float64(upper.Sub(lower).Milliseconds()) / histogramBuckets))
+				facets[fmt.Sprintf("d%d", i)] = rangeFacet(stats.IndexedPath, "valueDate", stats.Dates.Min, stats.Dates.Max, fmt.Sprintf("+%dMILLISECONDS", max(gap, 1)))
+			}
+		}
+	}
+	if len(facets) == 0 {
+		return nil
+	}
+	return statisticsRequest(profile, facets)
+}
+
+func rangeFacet(path IndexedPath, field string, start any, end any, gap any) map[string]any {
+	return map[string]any{
+		"type":    "range",
+		"field":   field,
+		"start":   start,
+		"end":     end,
+		"gap":     gap,
+		"hardend": true,
+		"include": []string{"lower", "edge"},
+		"domain":  pathDomain(path),
+	}
+}
+
+func parseHistograms(statistics []PathStatistics, body []byte) error {
+	var payload struct {
+		Facets map[string]json.RawMessage `json:"facets"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return err
+	}
+	for i, stats := range statistics {
+		if raw, ok := payload.Facets[fmt.Sprintf("n%d", i)]; ok {
+			if err := fillHistogram(stats.Numbers, raw); err != nil {
+				return fmt.Errorf("parsing number histogram of path %q: %w", stats.Path, err)
+			}
+		}
+		if raw, ok := payload.Facets[fmt.Sprintf("d%d", i)]; ok {
+			if err := fillHistogram(stats.Dates, raw); err != nil {
+				return fmt.Errorf("parsing date histogram of path %q: %w", stats.Path, err)
+			}
+		}
+	}
+	return nil
+}
+
+// fillHistogram adds the buckets of a range facet. Each bucket ends where the
+// next one starts and the last one ends at the maximum.
+func fillHistogram(stats *RangeStatistics, raw json.RawMessage) error {
+	var facet struct {
+		Buckets []struct {
+			Val   any `json:"val"`
+			Count int `json:"count"`
+		} `json:"buckets"`
+	}
+	if err := json.Unmarshal(raw, &facet); err != nil {
+		return err
+	}
+	for j, bucket := range facet.Buckets {
+		stats.Histogram = append(stats.Histogram, HistogramBucket{Start: bucket.Val, End: stats.Max, Count: bucket.Count})
+		if j > 0 {
+			stats.Histogram[j-1].End = bucket.Val
+		}
+	}
+	return nil
+}
+
+// labelValues adds labels to IRI values.
+func labelValues(ctx context.Context, language string, statistics []PathStatistics) error {
+	ids := make([]string, 0)
+	for _, stats := range statistics {
+		for _, value := range stats.Values {
+			if strings.HasPrefix(value.Value, "<") && strings.HasSuffix(value.Value, ">") {
+				ids = append(ids, value.Value)
+			}
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	var labels map[string]string
+	var err error
+	if language == "" {
+		labels, err = rdf.GetDefaultLabels(ctx, ids)
+	} else {
+		labels, err = rdf.GetLabels(ctx, language, ids)
+	}
+	if err != nil {
+		return fmt.Errorf("loading value labels: %w", err)
+	}
+	for _, stats := range statistics {
+		for j := range stats.Values {
+			stats.Values[j].Label = labels[stats.Values[j].Value]
+		}
+	}
+	return nil
+}
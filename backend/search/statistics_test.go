@@ -0,0 +1,160 @@
+package search
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"rdf-store-backend/rdf"
+	"rdf-store-backend/shacl"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/deiu/rdf2go"
+)
+
+const (
+	sampleID     = "http://example.org/Sample"
+	baseSampleID = "http://example.org/BaseSample"
+	personID     = "http://example.org/Person"
+	massPath     = "http://example.org/mass"
+	takenPath    = "http://example.org/taken"
+	ownerPath    = "http://example.org/owner"
+	namePath     = "http://xmlns.com/foaf/0.1/name"
+	contactID    = "urn:property:contact"
+)
+
+func useStatisticsProfiles(t *testing.T) {
+	facet := true
+	sample := &shacl.NodeShape{
+		Id: rdf2go.NewResource(sampleID), Parents: map[string]bool{baseSampleID: true}, Alternatives: map[string]bool{},
+		Properties: map[string][]*shacl.Property{
+			massPath: {{Id: rdf2go.NewResource("urn:property:mass"), Path: massPath, Datatype: "http://www.w3.org/2001/XMLSchema#double"}},
+			ownerPath: {
+				{Id: rdf2go.NewResource("urn:property:owner"), Path: ownerPath, NodeShapes: map[string]bool{personID: true}, Facet: &facet},
+				{Id: rdf2go.NewResource(contactID), Path: ownerPath, QualifiedValueShape: personID},
+			},
+		},
+	}
+	baseSample := &shacl.NodeShape{
+		Id: rdf2go.NewResource(baseSampleID), Parents: map[string]bool{}, Alternatives: map[string]bool{},
+		Properties: map[string][]*shacl.Property{
+			takenPath: {{Id: rdf2go.NewResource("urn:property:taken"), Path: takenPath, Datatype: "http://www.w3.org/2001/XMLSchema#date"}},
+		},
+	}
+	person := &shacl.NodeShape{
+		Id: rdf2go.NewResource(personID), Parents: map[string]bool{}, Alternatives: map[string]bool{},
+		Properties: map[string][]*shacl.Property{
+			namePath:  {{Id: rdf2go.NewResource("urn:property:name"), Path: namePath}},
+			ownerPath: {{Id: rdf2go.NewResource("urn:property:personOwner"), Path: ownerPath, NodeShapes: map[string]bool{personID: true}}},
+		},
+	}
+	previousProfiles := rdf.Profiles
+	rdf.Profiles = map[string]*shacl.NodeShape{sampleID: sample, baseSampleID: baseSample, personID: person}
+	t.Cleanup(func() { rdf.Profiles = previousProfiles })
+}
+
+func TestProfilePathsWalksShapesLikeTheIndexer(t *testing.T) {
+	useStatisticsProfiles(t)
+	paths, err := ProfilePaths(sampleID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make([]string, 0, len(paths))
+	for _, path := range paths {
+		if path.ID != queryPathID(path.Path) {
+			t.Errorf("unexpected ID of path %q", path.Path)
+		}
+		got = append(got, strings.Join(path.Path, " ")+" "+path.Datatype)
+	}
+	expected := []string{
+		massPath + " http://www.w3.org/2001/XMLSchema#double",
+		// dash:facet indexes the referenced resource at the relationship path
+		ownerPath + " ",
+		takenPath + " http://www.w3.org/2001/XMLSchema#date",
+		contactID + " " + namePath + " ",
+	}
+	if !slices.Equal(got, expected) {
+		t.Fatalf("unexpected paths:\n%s", strings.Join(got, "\n"))
+	}
+	if _, err := ProfilePaths("http://example.org/Unknown"); err == nil {
+		t.Fatal("expected error for unknown profile")
+	}
+	if _, err := FindProfilePath(sampleID, []string{ownerPath, namePath}); err == nil {
+		t.Fatal("expected error for path below a facet")
+	}
+}
+
+func TestPathValueStatisticsAggregatesValueTypes(t *testing.T) {
+	useStatisticsProfiles(t)
+	var histogramRequest map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request map[string]any
+		json.NewDecoder(r.Body).Decode(&request)
+		facets := request["facet"].(map[string]any)
+		if _, ok := facets["p0"]; ok {
+			w.Write([]byte(`{"facets":{"count":3,
+				"p0":{"count":4,"entities":3,"values":{"buckets":[]},"booleans":{"buckets":[]},"numberMin":1.5,"numberMax":11.5},
+				"p1":{"count":2,"entities":2,"values":{"buckets":[{"val":"<http://example.org/alice>","count":3,"entities":2}]},"booleans":{"buckets":[]}},
+				"p2":{"count":2,"entities":2,"values":{"buckets":[]},"booleans":{"buckets":[]},"dateMin":"2024-01-01T00:00:00Z","dateMax":"2024-01-11T00:00:00Z"}}}`))
+			return
+		}
+		histogramRequest = request
+		w.Write([]byte(`{"facets":{"count":3,
+			"n0":{"buckets":[{"val":1.5,"count":3},{"val":6.5,"count":1}]},
+			"d2":{"buckets":[{"val":"2024-01-01T00:00:00Z","count":1},{"val":"2024-01-06T00:00:00Z","count":1}]}}}`))
+	}))
+	defer server.Close()
+	previousEndpoint := Endpoint
+	Endpoint = server.URL
+	t.Cleanup(func() { Endpoint = previousEndpoint })
+
+	statistics, err := PathValueStatistics(t.Context(), StatisticsQuery{Profile: sampleID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(statistics) != 4 {
+		t.Fatalf("expected statistics for 4 paths, got %d", len(statistics))
+	}
+	mass, owner, taken := statistics[0], statistics[1], statistics[2]
+	if mass.Entities != 3 || mass.Numbers == nil || mass.Numbers.Min != 1.5 || len(mass.Numbers.Histogram) != 2 ||
+		mass.Numbers.Histogram[0].End != 6.5 || mass.Numbers.Histogram[1].End != 11.5 || mass.Values != nil {
+		t.Fatalf("unexpected number statistics %+v", mass)
+	}
+	if len(owner.Values) != 1 || owner.Values[0].Count != 2 || owner.Numbers != nil || owner.Booleans != nil {
+		t.Fatalf("unexpected value statistics %+v", owner)
+	}
+	if taken.Dates == nil || len(taken.Dates.Histogram) != 2 || taken.Dates.Histogram[1].End != "2024-01-11T00:00:00Z" {
+		t.Fatalf("unexpected date statistics %+v", taken)
+	}
+	if statistics[3].Entities != 0 || statistics[3].Values != nil {
+		t.Fatalf("unexpected statistics of path without values %+v", statistics[3])
+	}
+
+	facets := histogramRequest["facet"].(map[string]any)
+	if len(facets) != 2 {
+		t.Fatalf("expected two histogram facets, got %v", facets)
+	}
+	dates := facets["d2"].(map[string]any)
+	if dates["gap"] != "+86400000MILLISECONDS" || dates["field"] != "valueDate" {
+		t.Fatalf("unexpected date histogram %v", dates)
+	}
+	domain := facets["n0"].(map[string]any)["domain"].(map[string]any)
+	if filter := domain["filter"].([]any); len(filter) != 3 || filter[1] != `path:"`+queryPathID([]string{massPath})+`"` || filter[2] != "-expanded:true" {
+		t.Fatalf("unexpected domain %v", domain)
+	}
+}
+
+func TestParseStatisticsCountsBooleans(t *testing.T) {
+	paths := []IndexedPath{{Path: []string{"http://example.org/done"}, ID: "x"}}
+	statistics, err := parseStatistics(paths, []byte(`{"facets":{"p0":{"entities":5,"booleans":{"buckets":[{"val":true,"count":4,"entities":4},{"val":false,"count":2,"entities":1}]}}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if booleans := statistics[0].Booleans; booleans == nil || booleans.True != 4 || booleans.False != 1 {
+		t.Fatalf("unexpected boolean counts %+v", booleans)
+	}
+	if buildHistogramRequest("p", statistics) != nil {
+		t.Fatal("expected no histogram request without numbers or dates")
+	}
+}
@@ -0,0 +1,163 @@
+package search
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	defaultSuggestLimit = 10
+	// MaxSuggestLimit bounds the number of completions of a single request.
+	MaxSuggestLimit = 50
+	// suggestOverfetch multiplies the number of documents requested from Solr,
+	// since a label indexed also as text value yields the same completion twice.
+	suggestOverfetch = 2
+)
+
+var suggestFields = []string{"id", "docType", "resourceId", "subject", "label", "valueString", "score"}
+
+// SuggestQuery selects the completions of a partially typed text.
+type SuggestQuery struct {
+	// Text is matched word by word, each word as a prefix.
+	Text string
+	// Profile restricts completions to entities conforming to the profile.
+	Profile string
+	// Path restricts completions to the text values at a path in the segment
+	// notation of search criteria. Labels and the text values of all paths are
+	// completed when it is empty.
+	Path  []string
+	Limit int
+}
+
+// Suggestion is a completion with the entity it was found on.
+type Suggestion struct {
+	Text       string  `json:"text"`
+	Subject    string  `json:"subject"`
+	ResourceID string  `json:"resourceId"`
+	Score      float64 `json:"score"`
+}
+
+// Suggest returns completions ranked by relevance. The suggest field holds
+// entity labels and text values, so without a path both complete the input.
+// It returns an error wrapping ErrInvalidQuery for malformed queries.
+func Suggest(ctx context.Context, query SuggestQuery) ([]Suggestion, error) {
+	request, err := buildSuggestRequest(query)
+	if err != nil {
+		return nil, err
+	}
+	body, err := postQuery(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return parseSuggestions(query.Text, cmp.Or(query.Limit, defaultSuggestLimit), body)
+}
+
+func buildSuggestRequest(query SuggestQuery) (*searchRequest, error) {
+	terms := strings.Fields(query.Text)
+	if len(terms) == 0 {
+		return nil, fmt.Errorf("%w: empty suggest text", ErrInvalidQuery)
+	}
+	if query.Limit == 0 {
+		query.Limit = defaultSuggestLimit
+	}
+	if query.Limit < 0 || query.Limit > MaxSuggestLimit {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidQuery, MaxSuggestLimit)
+	}
+	for i, term := range terms {
+		terms[i] = escapeQueryTerm(term)
+	}
+	request := &searchRequest{
+		Query:  "suggest:(" + strings.Join(terms, " AND ") + ")",
+		Filter: []string{},
+		Sort:   "score desc, id asc",
+		Limit:  query.Limit * suggestOverfetch,
+		Fields: suggestFields,
+		Params: map[string]any{},
+	}
+	if len(query.Path) > 0 {
+		pathID := queryPathID(query.Path)
+		if query.Profile != "" {
+			path, err := FindProfilePath(query.Profile, query.Path)
+			if err != nil {
+				return nil, err
+			}
+			pathID = path.ID
+		}
+		request.Filter = append(request.Filter, "docType:value", fmt.Sprintf(`path:"%s"`, pathID))
+	}
+	if query.Profile != "" {
+		profile := "shape:" + escapeQueryValue(query.Profile)
+		// value documents are selected by the shapes of their parent entity
+		request.Filter = append(request.Filter, "{!bool should=$entityProfile should=$valueProfile}")
+		request.Params["entityProfile"] = "docType:entity AND " + profile
+		request.Params["valueProfile"] = "{!child of=docType:entity}docType:entity AND " + profile
+	}
+	return request, nil
+}
+
+// parseSuggestions returns up to limit completions of the documents, skipping
+// repeated texts of the same subject.
+func parseSuggestions(text string, limit int, body []byte) ([]Suggestion, error) {
+	var payload struct {
+		Response struct {
+			Docs []struct {
+				ID          string   `json:"id"`
+				DocType     string   `json:"docType"`
+				ResourceID  string   `json:"resourceId"`
+				Subject     string   `json:"subject"`
+				Label       []string `json:"label"`
+				ValueString string   `json:"valueString"`
+				Score       float64  `json:"score"`
+			} `json:"docs"`
+		} `json:"response"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+	suggestions := make([]Suggestion, 0, len(payload.Response.Docs))
+	seen := make(map[[2]string]bool)
+	for _, doc := range payload.Response.Docs {
+		suggestion := Suggestion{ResourceID: doc.ResourceID, Subject: doc.Subject, Score: doc.Score}
+		if doc.DocType == "value" {
+			suggestion.Text = doc.ValueString
+			suggestion.Subject = valueSubject(doc.ID, doc.ResourceID)
+		} else {
+			suggestion.Text = matchingLabel(doc.Label, text)
+		}
+		key := [2]string{suggestion.Text, suggestion.Subject}
+		if suggestion.Text == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		suggestions = append(suggestions, suggestion)
+		if len(suggestions) == limit {
+			break
+		}
+	}
+	return suggestions, nil
+}
+
+// valueSubject recovers the subject of a value document from its ID, which
+// extends the "<resource>|<subject>" ID of its entity.
+func valueSubject(id string, resourceID string) string {
+	entityID, _, _ := strings.Cut(id, "|value|")
+	return strings.TrimPrefix(entityID, resourceID+"|")
+}
+
+// matchingLabel returns the first label containing the first word of text,
+// or the first label if none does.
+func matchingLabel(labels []string, text string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	first := strings.ToLower(strings.Fields(text)[0])
+	for _, label := range labels {
+		if strings.Contains(strings.ToLower(label), first) {
+			return label
+		}
+	}
+	return labels[0]
+}
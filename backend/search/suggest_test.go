@@ -0,0 +1,69 @@
+package search
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestBuildSuggestRequestMatchesWordPrefixes(t *testing.T) {
+	useStatisticsProfiles(t)
+	request, err := buildSuggestRequest(SuggestQuery{Text: " soil  c:a ", Profile: sampleID, Path: []string{contactID, namePath}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if request.Query != `suggest:(soil AND c\:a)` || request.Limit != defaultSuggestLimit*suggestOverfetch {
+		t.Fatalf("unexpected request %+v", request)
+	}
+	expected := []string{"docType:value", `path:"` + queryPathID([]string{contactID, namePath}) + `"`, "{!bool should=$entityProfile should=$valueProfile}"}
+	if !slices.Equal(request.Filter, expected) {
+		t.Fatalf("unexpected filters %q", request.Filter)
+	}
+	if request.Params["valueProfile"] != `{!child of=docType:entity}docType:entity AND shape:"http\:\/\/example.org\/Sample"` {
+		t.Fatalf("unexpected params %v", request.Params)
+	}
+
+	for _, invalid := range []SuggestQuery{{Text: " "}, {Text: "a", Limit: MaxSuggestLimit + 1}, {Text: "a", Profile: sampleID, Path: []string{namePath}}} {
+		if _, err := buildSuggestRequest(invalid); err == nil {
+			t.Errorf("expected error for %+v", invalid)
+		}
+	}
+}
+
+func TestParseSuggestionsResolvesSubjects(t *testing.T) {
+	body := []byte(`{"response":{"docs":[
+		{"id":"r|http://example.org/s","docType":"entity","resourceId":"r","subject":"http://example.org/s","label":["Boden","Soil samples"],"score":2.5},
+		{"id":"r|http://example.org/s|value|01","docType":"value","resourceId":"r","valueString":"Soil samples","score":2},
+		{"id":"r|http://example.org/s|value|02","docType":"value","resourceId":"r","valueString":"Soil samples","score":1.5},
+		{"id":"r|http://example.org/t|value|03","docType":"value","resourceId":"r","valueString":"Soil samples","score":1}]}}`)
+	suggestions, err := parseSuggestions("soil", defaultSuggestLimit, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Suggestion{
+		{Text: "Soil samples", Subject: "http://example.org/s", ResourceID: "r", Score: 2.5},
+		{Text: "Soil samples", Subject: "http://example.org/t", ResourceID: "r", Score: 1},
+	}
+	if !slices.Equal(suggestions, expected) {
+		t.Fatalf("unexpected suggestions %+v", suggestions)
+	}
+}
+
+func TestParseSuggestionsFillsLimitAfterDeduplication(t *testing.T) {
+	// the label of s is also indexed as its text value
+	body := []byte(`{"response":{"docs":[
+		{"id":"r|http://example.org/s","docType":"entity","resourceId":"r","subject":"http://example.org/s","label":["Soil"],"score":3},
+		{"id":"r|http://example.org/s|value|01","docType":"value","resourceId":"r","valueString":"Soil","score":2},
+		{"id":"r|http://example.org/t","docType":"entity","resourceId":"r","subject":"http://example.org/t","label":["Soil water"],"score":1.5},
+		{"id":"r|http://example.org/u","docType":"entity","resourceId":"r","subject":"http://example.org/u","label":["Soil air"],"score":1}]}}`)
+	suggestions, err := parseSuggestions("soil", 2, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Suggestion{
+		{Text: "Soil", Subject: "http://example.org/s", ResourceID: "r", Score: 3},
+		{Text: "Soil water", Subject: "http://example.org/t", ResourceID: "r", Score: 1.5},
+	}
+	if !slices.Equal(suggestions, expected) {
+		t.Fatalf("unexpected suggestions %+v", suggestions)
+	}
+}
@@ -0,0 +1,86 @@
+package search
+
+import (
+	"fmt"
+	"rdf-store-backend/search/qudt"
+	"strconv"
+)
+
+// quantityUnit resolves the canonical unit that quantity values in a unit are
+// indexed in, by their quantity kind or else their dimension vector.
+// It returns an error for unknown units and units that do not match the
+// dimension vector of the quantity kind.
+func quantityUnit(unit string, quantityKind string) (string, error) {
+	info := qudt.Unit(unit)
+	if info == nil {
+		return "", fmt.Errorf("unknown unit %s", unit)
+	}
+	if canonical := qudt.CanonicalUnitURI(unit, quantityKind); canonical != "" {
+		return canonical, nil
+	}
+	if quantityKind == "" {
+		return "", fmt.Errorf("unit %s needs a quantity kind", unit)
+	}
+	canonical := qudt.Unit(qudt.CanonicalUnitOf(quantityKind))
+	if canonical == nil {
+		return "", fmt.Errorf("no canonical unit known for quantity kind %s", quantityKind)
+	}
+	return "", fmt.Errorf("dimension vector %s of unit %s does not match %s of quantity kind %s", info.DimensionVector, unit, canonical.DimensionVector, quantityKind)
+}
+
+// canonicalCriterion converts the numeric values of a criterion with a unit
+// to the canonical unit they are indexed in.
+// It returns an error for values that are not numbers and for units that
+// do not fit the quantity kind.
+func canonicalCriterion(criterion Criterion) (Criterion, error) {
+	switch criterion.Operator {
+	case OperatorEquals, OperatorRange, OperatorIntervalOverlaps, OperatorIntervalWithin, OperatorIntervalContains:
+	default:
+		return criterion, fmt.Errorf("operator %s does not support units", criterion.Operator)
+	}
+	if criterion.Datatype != "" && valueField(criterion.Datatype) != "valueNumber" {
+		return criterion, fmt.Errorf("datatype %s does not support units", criterion.Datatype)
+	}
+	if _, err := quantityUnit(criterion.Unit, criterion.QuantityKind); err != nil {
+		return criterion, err
+	}
+	for _, value := range []*string{&criterion.Value, &criterion.Min, &criterion.Max} {
+		if *value == "" {
+			continue
+		}
+		number, err := strconv.ParseFloat(*value, 64)
+		if err != nil {
+			return criterion, fmt.Errorf("invalid number %q", *value)
+		}
+		if converted, ok := qudt.Convert(number, criterion.Unit, criterion.QuantityKind, criterion.IsDelta); ok {
+			*value = strconv.FormatFloat(converted, 'f', -1, 64)
+		}
+	}
+	if criterion.Datatype == "" {
+		criterion.Datatype = fmt.Sprintf(prefixXSD, "double")
+	}
+	return criterion, nil
+}
+
+// convertNumbers converts the number statistics of paths from the canonical
+// unit to a unit.
+func convertNumbers(statistics []PathStatistics, canonical string, unit string, isDelta bool) {
+	convert := func(value any) any {
+		if number, ok := value.(float64); ok {
+			if converted, ok := qudt.ConvertTo(number, canonical, unit, isDelta); ok {
+				return converted
+			}
+		}
+		return value
+	}
+	for _, stats := range statistics {
+		if stats.Numbers == nil {
+			continue
+		}
+		stats.Numbers.Min, stats.Numbers.Max = convert(stats.Numbers.Min), convert(stats.Numbers.Max)
+		for i := range stats.Numbers.Histogram {
+			bucket := &stats.Numbers.Histogram[i]
+			bucket.Start, bucket.End = convert(bucket.Start), convert(bucket.End)
+		}
+	}
+}
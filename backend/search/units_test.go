@@ -0,0 +1,76 @@
+package search
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+const (
+	unitPrefix         = "http://qudt.org/vocab/unit/"
+	quantityKindPrefix = "http://qudt.org/vocab/quantitykind/"
+)
+
+func TestCriterionFilterConvertsUnits(t *testing.T) {
+	path := []string{"http://example.org/length"}
+	for name, test := range map[string]struct {
+		criterion Criterion
+		bounds    string
+	}{
+		"range":     {Criterion{Path: path, Operator: OperatorRange, Min: "1.5", Max: "2", Unit: unitPrefix + "KiloM", QuantityKind: quantityKindPrefix + "Length"}, `valueNumber:["1500" TO "2000"]`},
+		"dimension": {Criterion{Path: path, Operator: OperatorRange, Max: "3", Unit: unitPrefix + "KiloM"}, `valueNumber:[* TO "3000"]`},
+		"offset":    {Criterion{Path: path, Operator: OperatorEquals, Value: "0", Unit: unitPrefix + "DEG_C", QuantityKind: quantityKindPrefix + "Temperature"}, `valueNumber:"273.15"`},
+		"interval":  {Criterion{Path: path, Operator: OperatorIntervalWithin, Min: "1", Max: "2", Unit: unitPrefix + "KiloM"}, `(valueNumber:["1000" TO "2000"] AND -valueNumberMin:{* TO "1000"} AND -valueNumberMax:{"2000" TO *})`},
+		"delta":     {Criterion{Path: path, Operator: OperatorEquals, Value: "10", Unit: unitPrefix + "DEG_C", QuantityKind: quantityKindPrefix + "Temperature", IsDelta: true}, `valueNumber:"10"`},
+	} {
+		filter, err := criterionFilter(test.criterion)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		expected := `{!parent which=docType:entity}(docType:value AND path:"` + queryPathID(path) + `" AND ` + test.bounds + `)`
+		if filter != expected {
+			t.Errorf("%s: unexpected filter %q, expected %q", name, filter, expected)
+		}
+	}
+}
+
+func TestBuildSearchRequestRejectsInvalidUnits(t *testing.T) {
+	path := []string{"http://example.org/length"}
+	for name, criterion := range map[string]Criterion{
+		"unit":     {Path: path, Operator: OperatorRange, Min: "1", Unit: unitPrefix + "NO_SUCH_UNIT"},
+		"kind":     {Path: path, Operator: OperatorRange, Min: "1", Unit: unitPrefix + "KiloM", QuantityKind: quantityKindPrefix + "Mass"},
+		"operator": {Path: path, Operator: OperatorContains, Value: "1", Unit: unitPrefix + "KiloM"},
+		"datatype": {Path: path, Operator: OperatorEquals, Value: "2024-01-01", Datatype: "http://www.w3.org/2001/XMLSchema#date", Unit: unitPrefix + "KiloM"},
+		"number":   {Path: path, Operator: OperatorEquals, Value: "one", Unit: unitPrefix + "KiloM"},
+	} {
+		if _, err := buildSearchRequest(Query{Criteria: []Criterion{criterion}}); !errors.Is(err, ErrInvalidQuery) {
+			t.Errorf("%s: expected invalid query error, got %v", name, err)
+		}
+	}
+}
+
+func TestConvertNumbersConvertsFromCanonicalUnit(t *testing.T) {
+	statistics := []PathStatistics{{Numbers: &RangeStatistics{Min: 273.15, Max: 373.15, Histogram: []HistogramBucket{{Start: 273.15, End: 323.15, Count: 2}}}}, {}}
+	convertNumbers(statistics, unitPrefix+"K", unitPrefix+"DEG_C", false)
+	numbers := statistics[0].Numbers
+	near := func(value any, expected float64) bool {
+		number, ok := value.(float64)
+		return ok && math.Abs(number-expected) < 1e-9
+	}
+	if !near(numbers.Min, 0) || !near(numbers.Max, 100) || !near(numbers.Histogram[0].Start, 0) || !near(numbers.Histogram[0].End, 50) || numbers.Histogram[0].Count != 2 {
+		t.Fatalf("unexpected number statistics %+v", numbers)
+	}
+}
+
+func TestPathValueStatisticsRejectsInvalidUnits(t *testing.T) {
+	useStatisticsProfiles(t)
+	for name, query := range map[string]StatisticsQuery{
+		"path": {Profile: sampleID, Unit: unitPrefix + "KiloGM"},
+		"unit": {Profile: sampleID, Path: []string{massPath}, Unit: unitPrefix + "NO_SUCH_UNIT"},
+		"kind": {Profile: sampleID, Path: []string{massPath}, Unit: unitPrefix + "KiloGM", QuantityKind: quantityKindPrefix + "Length"},
+	} {
+		if _, err := PathValueStatistics(t.Context(), query); !errors.Is(err, ErrInvalidQuery) {
+			t.Errorf("%s: expected invalid query error, got %v", name, err)
+		}
+	}
+}
@@ -15,6 +15,7 @@ var prefixDASH = "http://datashapes.org/dash#%s"
 var prefixFOAF = "http://xmlns.com/foaf/0.1/%s"
 var prefixDCTerms = "http://purl.org/dc/terms/%s"
 var prefixSchema = "http://schema.org/%s"
+var prefixRDFStore = "https://github.com/ULB-Darmstadt/rdf-store#%s"
 
 var RDF_TYPE = rdf2go.NewResource(fmt.Sprintf(prefixRDF, "type"))
 var RDFS_LABEL = rdf2go.NewResource(fmt.Sprintf(prefixRDFS, "label"))
@@ -28,6 +29,8 @@ var DCTERMS_MODIFIED = rdf2go.NewResource(fmt.Sprintf(prefixDCTerms, "modified")
 var DCTERMS_CREATOR = rdf2go.NewResource(fmt.Sprintf(prefixDCTerms, "creator"))
 var OWL_IMPORTS = rdf2go.NewResource(fmt.Sprintf(prefixOWL, "imports"))
 var SKOS_PREF_LABEL = rdf2go.NewResource(fmt.Sprintf(prefixSKOS, "prefLabel"))
+var SKOS_BROADER = rdf2go.NewResource(fmt.Sprintf(prefixSKOS, "broader"))
+var SKOS_NARROWER = rdf2go.NewResource(fmt.Sprintf(prefixSKOS, "narrower"))
 var SCHEMA_TITLE = rdf2go.NewResource(fmt.Sprintf(prefixSchema, "title"))
 var SCHEMA_HEADLINE = rdf2go.NewResource(fmt.Sprintf(prefixSchema, "headline"))
 
@@ -45,12 +48,32 @@ var SHACL_NAME = rdf2go.NewResource(fmt.Sprintf(prefixSHACL, "name"))
 var SHACL_PROPERTY = rdf2go.NewResource(fmt.Sprintf(prefixSHACL, "property"))
 var SHACL_DATATYPE = rdf2go.NewResource(fmt.Sprintf(prefixSHACL, "datatype"))
 var SHACL_PATH = rdf2go.NewResource(fmt.Sprintf(prefixSHACL, "path"))
+var SHACL_INVERSE_PATH = rdf2go.NewResource(fmt.Sprintf(prefixSHACL, "inversePath"))
+var SHACL_ALTERNATIVE_PATH = rdf2go.NewResource(fmt.Sprintf(prefixSHACL, "alternativePath"))
+var SHACL_ZERO_OR_MORE_PATH = rdf2go.NewResource(fmt.Sprintf(prefixSHACL, "zeroOrMorePath"))
+var SHACL_ONE_OR_MORE_PATH = rdf2go.NewResource(fmt.Sprintf(prefixSHACL, "oneOrMorePath"))
+var SHACL_ZERO_OR_ONE_PATH = rdf2go.NewResource(fmt.Sprintf(prefixSHACL, "zeroOrOnePath"))
 var SHACL_CLASS = rdf2go.NewResource(fmt.Sprintf(prefixSHACL, "class"))
 var SHACL_NODE_KIND = rdf2go.NewResource(fmt.Sprintf(prefixSHACL, "nodeKind"))
 var SHACL_IRI = rdf2go.NewResource(fmt.Sprintf(prefixSHACL, "IRI"))
 
 var DASH_FACET = rdf2go.NewResource(fmt.Sprintf(prefixDASH, "facet"))
 
+// RDFSTORE_SEARCH_BOOST annotates property shapes with a relevance boost for
+// full-text matches in their values.
+var RDFSTORE_SEARCH_BOOST = rdf2go.NewResource(fmt.Sprintf(prefixRDFStore, "searchBoost"))
+
+// RDFSTORE_INTERVAL_END annotates the property shape of an interval start
+// with the predicate of the matching interval end.
+var RDFSTORE_INTERVAL_END = rdf2go.NewResource(fmt.Sprintf(prefixRDFStore, "intervalEnd"))
+
+// RDFSTORE_QUANTITY_UNIT, RDFSTORE_QUANTITY_KIND and RDFSTORE_QUANTITY_VALUE
+// annotate node shapes of quantity values with the predicates of their unit,
+// quantity kind and numerical value.
+var RDFSTORE_QUANTITY_UNIT = rdf2go.NewResource(fmt.Sprintf(prefixRDFStore, "quantityUnit"))
+var RDFSTORE_QUANTITY_KIND = rdf2go.NewResource(fmt.Sprintf(prefixRDFStore, "quantityKind"))
+var RDFSTORE_QUANTITY_VALUE = rdf2go.NewResource(fmt.Sprintf(prefixRDFStore, "quantityValue"))
+
 var RDF_LIST_FIRST = rdf2go.NewResource(fmt.Sprintf(prefixRDF, "first"))
 var RDF_LIST_REST = rdf2go.NewResource(fmt.Sprintf(prefixRDF, "rest"))
 var RDF_LIST_NIL = rdf2go.NewResource(fmt.Sprintf(prefixRDF, "nil"))
@@ -0,0 +1,232 @@
+package shacl
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/deiu/rdf2go"
+)
+
+// PathKind is the form of a SHACL property path.
+type PathKind int
+
+const (
+	PredicatePath PathKind = iota
+	InversePath
+	SequencePath
+	AlternativePath
+	ZeroOrMorePath
+	OneOrMorePath
+	ZeroOrOnePath
+)
+
+// maxPathDepth bounds the nesting of parsed path expressions, guarding against
+// cyclic path nodes.
+const maxPathDepth = 32
+
+// unaryPaths are the path forms described by a single predicate on the path node.
+var unaryPaths = []struct {
+	predicate rdf2go.Term
+	kind      PathKind
+}{
+	{SHACL_INVERSE_PATH, InversePath},
+	{SHACL_ZERO_OR_MORE_PATH, ZeroOrMorePath},
+	{SHACL_ONE_OR_MORE_PATH, OneOrMorePath},
+	{SHACL_ZERO_OR_ONE_PATH, ZeroOrOnePath},
+}
+
+// PathExpression is a SHACL property path.
+type PathExpression struct {
+	Kind PathKind
+	// Predicate is the IRI of a predicate path.
+	Predicate string
+	// Elements are the sub-paths. Inverse and repetition paths have one,
+	// sequence and alternative paths at least two.
+	Elements []*PathExpression
+}
+
+// NewPredicatePath returns the path of a single predicate.
+func NewPredicatePath(predicate string) *PathExpression {
+	return &PathExpression{Kind: PredicatePath, Predicate: predicate}
+}
+
+// ParsePath reads the property path an sh:path object describes.
+// It returns an error if the node is not a valid property path.
+func ParsePath(node rdf2go.Term, graph *rdf2go.Graph) (*PathExpression, error) {
+	return parsePath(node, graph, 0)
+}
+
+func parsePath(node rdf2go.Term, graph *rdf2go.Graph, depth int) (*PathExpression, error) {
+	if depth > maxPathDepth {
+		return nil, fmt.Errorf("property path nested deeper than %d levels", maxPathDepth)
+	}
+	// profiles replace blank nodes with IRIs, so path nodes are recognized by
+	// their structure rather than by their term type
+	if graph.One(node, RDF_LIST_FIRST, nil) != nil {
+		return parsePathList(SequencePath, node, graph, depth)
+	}
+	for _, unary := range unaryPaths {
+		if triple := graph.One(node, unary.predicate, nil); triple != nil {
+			element, err := parsePath(triple.Object, graph, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			return &PathExpression{Kind: unary.kind, Elements: []*PathExpression{element}}, nil
+		}
+	}
+	if triple := graph.One(node, SHACL_ALTERNATIVE_PATH, nil); triple != nil {
+		return parsePathList(AlternativePath, triple.Object, graph, depth)
+	}
+	if resource, ok := node.(*rdf2go.Resource); ok && !resource.Equal(RDF_LIST_NIL) {
+		return NewPredicatePath(resource.RawValue()), nil
+	}
+	return nil, fmt.Errorf("invalid property path %v", node)
+}
+
+func parsePathList(kind PathKind, head rdf2go.Term, graph *rdf2go.Graph, depth int) (*PathExpression, error) {
+	members := parseList(head, graph)
+	if len(members) < 2 {
+		return nil, fmt.Errorf("property path list %v needs at least two members", head)
+	}
+	path := &PathExpression{Kind: kind, Elements: make([]*PathExpression, 0, len(members))}
+	for _, member := range members {
+		element, err := parsePath(member, graph, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		path.Elements = append(path.Elements, element)
+	}
+	return path, nil
+}
+
+// Key identifies the path within a node shape and as segment of search paths.
+// Predicate paths are identified by their IRI, so their path IDs do not
+// change. Other paths use the SPARQL property path syntax with full IRIs.
+func (path *PathExpression) Key() string {
+	if path.Kind == PredicatePath {
+		return path.Predicate
+	}
+	return path.String()
+}
+
+// IsPredicateKey reports whether a path key is a predicate IRI rather than
+// another path in SPARQL syntax, which cannot be labeled as a single IRI.
+func IsPredicateKey(key string) bool {
+	return key != "" && !strings.ContainsAny(key[:1], "^(<")
+}
+
+// String returns the path in SPARQL property path syntax with full IRIs.
+func (path *PathExpression) String() string {
+	switch path.Kind {
+	case InversePath:
+		return "^" + path.Elements[0].operand()
+	case SequencePath, AlternativePath:
+		separator := "/"
+		if path.Kind == AlternativePath {
+			separator = "|"
+		}
+		elements := make([]string, 0, len(path.Elements))
+		for _, element := range path.Elements {
+			elements = append(elements, element.String())
+		}
+		return "(" + strings.Join(elements, separator) + ")"
+	case ZeroOrMorePath:
+		return path.Elements[0].operand() + "*"
+	case OneOrMorePath:
+		return path.Elements[0].operand() + "+"
+	case ZeroOrOnePath:
+		return path.Elements[0].operand() + "?"
+	default:
+		return "<" + path.Predicate + ">"
+	}
+}
+
+// operand returns the path as the operand of a unary operator.
+func (path *PathExpression) operand() string {
+	switch path.Kind {
+	case PredicatePath, SequencePath, AlternativePath:
+		return path.String()
+	default:
+		return "(" + path.String() + ")"
+	}
+}
+
+// Evaluate returns the distinct value nodes the path reaches from subject, in
+// the order they are first reached.
+func (path *PathExpression) Evaluate(subject rdf2go.Term, graph *rdf2go.Graph) []rdf2go.Term {
+	return path.evaluate([]rdf2go.Term{subject}, graph, false)
+}
+
+func (path *PathExpression) evaluate(nodes []rdf2go.Term, graph *rdf2go.Graph, inverse bool) []rdf2go.Term {
+	switch path.Kind {
+	case InversePath:
+		return path.Elements[0].evaluate(nodes, graph, !inverse)
+	case SequencePath:
+		for i := range path.Elements {
+			element := path.Elements[i]
+			if inverse {
+				element = path.Elements[len(path.Elements)-1-i]
+			}
+			nodes = element.evaluate(nodes, graph, inverse)
+		}
+		return nodes
+	case AlternativePath:
+		reached := make([]rdf2go.Term, 0)
+		for _, element := range path.Elements {
+			reached = append(reached, element.evaluate(nodes, graph, inverse)...)
+		}
+		return distinctTerms(reached)
+	case ZeroOrMorePath, OneOrMorePath:
+		reached := make([]rdf2go.Term, 0)
+		seen := make(map[string]bool)
+		if path.Kind == ZeroOrMorePath {
+			for _, node := range nodes {
+				if !seen[node.String()] {
+					seen[node.String()] = true
+					reached = append(reached, node)
+				}
+			}
+		}
+		for frontier := nodes; len(frontier) > 0; {
+			next := make([]rdf2go.Term, 0)
+			for _, node := range path.Elements[0].evaluate(frontier, graph, inverse) {
+				if !seen[node.String()] {
+					seen[node.String()] = true
+					reached = append(reached, node)
+					next = append(next, node)
+				}
+			}
+			frontier = next
+		}
+		return reached
+	case ZeroOrOnePath:
+		return distinctTerms(append(append([]rdf2go.Term{}, nodes...), path.Elements[0].evaluate(nodes, graph, inverse)...))
+	default:
+		predicate := rdf2go.NewResource(path.Predicate)
+		reached := make([]rdf2go.Term, 0)
+		for _, node := range nodes {
+			if inverse {
+				for _, triple := range graph.All(nil, predicate, node) {
+					reached = append(reached, triple.Subject)
+				}
+			} else {
+				for _, triple := range graph.All(node, predicate, nil) {
+					reached = append(reached, triple.Object)
+				}
+			}
+		}
+		return distinctTerms(reached)
+	}
+}
+
+func distinctTerms(terms []rdf2go.Term) []rdf2go.Term {
+	seen := make(map[string]bool, len(terms))
+	result := make([]rdf2go.Term, 0, len(terms))
+	for _, term := range terms {
+		if !seen[term.String()] {
+			seen[term.String()] = true
+			result = append(result, term)
+		}
+	}
+	return result
+}
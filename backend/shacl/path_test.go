@@ -0,0 +1,97 @@
+package shacl
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/deiu/rdf2go"
+)
+
+func TestParsePropertyPaths(t *testing.T) {
+	graph := rdf2go.NewGraph("")
+	data := `
+@prefix sh: <http://www.w3.org/ns/shacl#> .
+@prefix ex: <http://example.org/> .
+ex:Part a sh:NodeShape ;
+  sh:property [ sh:path ex:name ] ;
+  sh:property [ sh:path [ sh:inversePath ex:hasPart ] ] ;
+  sh:property [ sh:path ( ex:owner [ sh:alternativePath ( ex:name ex:label ) ] ) ] ;
+  sh:property [ sh:path [ sh:zeroOrMorePath [ sh:inversePath ex:hasPart ] ] ] .
+`
+	if err := graph.Parse(strings.NewReader(data), "text/turtle"); err != nil {
+		t.Fatal(err)
+	}
+	shape, err := (&NodeShape{Graph: graph}).Parse(rdf2go.NewResource("http://example.org/Part"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := make([]string, 0, len(shape.Properties))
+	for key := range shape.Properties {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	expected := []string{
+		"(<http://example.org/owner>/(<http://example.org/name>|<http://example.org/label>))",
+		"(^<http://example.org/hasPart>)*",
+		"^<http://example.org/hasPart>",
+		"http://example.org/name",
+	}
+	if !slices.Equal(keys, expected) {
+		t.Fatalf("unexpected path keys:\n%s", strings.Join(keys, "\n"))
+	}
+	if !IsPredicateKey(keys[3]) || IsPredicateKey(keys[0]) || IsPredicateKey(keys[2]) {
+		t.Fatal("unexpected predicate key detection")
+	}
+}
+
+func TestParsePathRejectsInvalidPaths(t *testing.T) {
+	graph := rdf2go.NewGraph("")
+	data := `
+@prefix sh: <http://www.w3.org/ns/shacl#> .
+@prefix ex: <http://example.org/> .
+ex:Shape a sh:NodeShape ; sh:property [ sh:path [ sh:alternativePath ( ex:name ) ] ] .
+`
+	if err := graph.Parse(strings.NewReader(data), "text/turtle"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := (&NodeShape{Graph: graph}).Parse(rdf2go.NewResource("http://example.org/Shape"), nil); err == nil {
+		t.Fatal("expected error for alternative path with a single member")
+	}
+}
+
+func TestEvaluatePropertyPaths(t *testing.T) {
+	hasPart := "http://example.org/hasPart"
+	name := "http://example.org/name"
+	graph := rdf2go.NewGraph("")
+	whole, part, piece := rdf2go.NewResource("http://example.org/whole"), rdf2go.NewResource("http://example.org/part"), rdf2go.NewResource("http://example.org/piece")
+	graph.AddTriple(whole, rdf2go.NewResource(hasPart), part)
+	graph.AddTriple(part, rdf2go.NewResource(hasPart), piece)
+	graph.AddTriple(whole, rdf2go.NewResource(name), rdf2go.NewLiteral("Whole"))
+	graph.AddTriple(part, rdf2go.NewResource(name), rdf2go.NewLiteral("Part"))
+
+	inverse := &PathExpression{Kind: InversePath, Elements: []*PathExpression{NewPredicatePath(hasPart)}}
+	tests := []struct {
+		path     *PathExpression
+		subject  rdf2go.Term
+		expected []string
+	}{
+		{inverse, piece, []string{part.RawValue()}},
+		{&PathExpression{Kind: SequencePath, Elements: []*PathExpression{inverse, NewPredicatePath(name)}}, piece, []string{"Part"}},
+		{&PathExpression{Kind: OneOrMorePath, Elements: []*PathExpression{inverse}}, piece, []string{part.RawValue(), whole.RawValue()}},
+		{&PathExpression{Kind: ZeroOrMorePath, Elements: []*PathExpression{NewPredicatePath(hasPart)}}, part, []string{part.RawValue(), piece.RawValue()}},
+		{&PathExpression{Kind: ZeroOrOnePath, Elements: []*PathExpression{NewPredicatePath(hasPart)}}, piece, []string{piece.RawValue()}},
+		{&PathExpression{Kind: AlternativePath, Elements: []*PathExpression{NewPredicatePath(name), NewPredicatePath(hasPart)}}, whole, []string{"Whole", part.RawValue()}},
+		// inverting a sequence reverses its steps
+		{&PathExpression{Kind: InversePath, Elements: []*PathExpression{{Kind: SequencePath, Elements: []*PathExpression{NewPredicatePath(hasPart), NewPredicatePath(hasPart)}}}}, piece, []string{whole.RawValue()}},
+	}
+	for _, test := range tests {
+		got := make([]string, 0)
+		for _, value := range test.path.Evaluate(test.subject, graph) {
+			got = append(got, value.RawValue())
+		}
+		if !slices.Equal(got, test.expected) {
+			t.Errorf("%s from %s: expected %v, got %v", test.path, test.subject.RawValue(), test.expected, got)
+		}
+	}
+}
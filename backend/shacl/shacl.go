@@ -17,6 +17,11 @@ type NodeShape struct {
 	Graph        *rdf2go.Graph
 	Class        bool
 	Facet        *bool
+	// QuantityUnit, QuantityKind and QuantityValue are the predicates of
+	// the quantity values conforming to the shape, if declared.
+	QuantityUnit  string
+	QuantityKind  string
+	QuantityValue string
 }
 
 // Parse loads a NodeShape from RDF data into the NodeShape struct.
@@ -61,11 +66,24 @@ func (node *NodeShape) Parse(id rdf2go.Term, rdf *[]byte) (*NodeShape, error) {
 				return nil, fmt.Errorf("node shape's dash:facet is not a boolean: %v", triple.Object.RawValue())
 			}
 			node.Facet = &boolValue
+		} else if predicate := quantityAnnotations[triple.Predicate.RawValue()]; predicate != nil {
+			if _, ok := triple.Object.(*rdf2go.Resource); !ok {
+				return nil, fmt.Errorf("node shape's quantity predicate is not a named node: %v", triple.Object)
+			}
+			*predicate(node) = triple.Object.RawValue()
 		}
 	}
 	return node, nil
 }
 
+// quantityAnnotations select the node shape field of each quantity
+// predicate annotation.
+var quantityAnnotations = map[string]func(*NodeShape) *string{
+	RDFSTORE_QUANTITY_UNIT.RawValue():  func(node *NodeShape) *string { return &node.QuantityUnit },
+	RDFSTORE_QUANTITY_KIND.RawValue():  func(node *NodeShape) *string { return &node.QuantityKind },
+	RDFSTORE_QUANTITY_VALUE.RawValue(): func(node *NodeShape) *string { return &node.QuantityValue },
+}
+
 // AddProperty registers a property, merging where appropriate.
 func (node *NodeShape) AddProperty(property *Property) {
 	if len(property.Path) > 0 {
@@ -155,6 +173,15 @@ type Property struct {
 	Or                              map[*Property]bool
 	NodeKind                        string
 	Facet                           *bool
+	// SearchBoost weights full-text matches in values of the property. Zero
+	// means no boost was declared.
+	SearchBoost float64
+	// PathExpression is the parsed sh:path. Path holds its key, which is the
+	// predicate IRI for predicate paths.
+	PathExpression *PathExpression
+	// IntervalEnd is the predicate of the interval end when the property is
+	// the start of a time interval.
+	IntervalEnd string
 }
 
 // Print logs a human-readable representation of the property.
@@ -189,9 +216,12 @@ func (prop *Property) Parse(id rdf2go.Term, parent *NodeShape, graph *rdf2go.Gra
 				return nil, fmt.Errorf("property's sh:datatype is not a named node: %v", triple.Object)
 			}
 		} else if triple.Predicate.Equal(SHACL_PATH) {
-			if spec, ok := triple.Object.(*rdf2go.Resource); ok {
-				prop.Path = spec.RawValue()
+			path, err := ParsePath(triple.Object, graph)
+			if err != nil {
+				return nil, fmt.Errorf("property's sh:path is not supported: %w", err)
 			}
+			prop.Path = path.Key()
+			prop.PathExpression = path
 		} else if triple.Predicate.Equal(SHACL_NODE) {
 			prop.NodeShapes[triple.Object.RawValue()] = true
 		} else if triple.Predicate.Equal(SHACL_AND) {
@@ -225,6 +255,17 @@ func (prop *Property) Parse(id rdf2go.Term, parent *NodeShape, graph *rdf2go.Gra
 				return nil, fmt.Errorf("property's dash:facet is not a boolean: %v", triple.Object.RawValue())
 			}
 			prop.Facet = &boolValue
+		} else if triple.Predicate.Equal(RDFSTORE_SEARCH_BOOST) {
+			boost, err := strconv.ParseFloat(triple.Object.RawValue(), 64)
+			if err != nil || boost <= 0 {
+				return nil, fmt.Errorf("property's rdfstore:searchBoost is not a positive number: %v", triple.Object.RawValue())
+			}
+			prop.SearchBoost = boost
+		} else if triple.Predicate.Equal(RDFSTORE_INTERVAL_END) {
+			if _, ok := triple.Object.(*rdf2go.Resource); !ok {
+				return nil, fmt.Errorf("property's rdfstore:intervalEnd is not a named node: %v", triple.Object)
+			}
+			prop.IntervalEnd = triple.Object.RawValue()
 		} else if triple.Predicate.Equal(SHACL_OR) || triple.Predicate.Equal(SHACL_XONE) {
 			for _, option := range parseList(triple.Object, graph) {
 				optionProp, err := new(Property).Parse(option, parent, graph)
@@ -261,6 +302,12 @@ func (prop *Property) Merge(other *Property) {
 	if other.Facet != nil {
 		prop.Facet = other.Facet
 	}
+	if other.SearchBoost > 0 {
+		prop.SearchBoost = other.SearchBoost
+	}
+	if len(other.IntervalEnd) > 0 {
+		prop.IntervalEnd = other.IntervalEnd
+	}
 	for k := range other.NodeShapes {
 		prop.NodeShapes[k] = true
 	}
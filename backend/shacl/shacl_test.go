@@ -62,3 +62,87 @@ ex:Person a sh:NodeShape ; dash:facet true .
 		t.Fatalf("expected dash:facet true, got %#v", shape.Facet)
 	}
 }
+
+func TestParsePropertySearchBoost(t *testing.T) {
+	graph := rdf2go.NewGraph("")
+	data := `
+@prefix sh: <http://www.w3.org/ns/shacl#> .
+@prefix rdfstore: <https://github.com/ULB-Darmstadt/rdf-store#> .
+@prefix ex: <http://example.org/> .
+ex:Dataset a sh:NodeShape ;
+  sh:property [ sh:path ex:title ; rdfstore:searchBoost 3.5 ] ;
+  sh:property [ sh:path ex:note ] .
+ex:Invalid a sh:NodeShape ;
+  sh:property [ sh:path ex:title ; rdfstore:searchBoost "high" ] .
+`
+	if err := graph.Parse(strings.NewReader(data), "text/turtle"); err != nil {
+		t.Fatal(err)
+	}
+	shape, err := (&NodeShape{Graph: graph}).Parse(rdf2go.NewResource("http://example.org/Dataset"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if boost := shape.Properties["http://example.org/title"][0].SearchBoost; boost != 3.5 {
+		t.Fatalf("expected search boost 3.5, got %v", boost)
+	}
+	if boost := shape.Properties["http://example.org/note"][0].SearchBoost; boost != 0 {
+		t.Fatalf("expected no search boost, got %v", boost)
+	}
+	if _, err := (&NodeShape{Graph: graph}).Parse(rdf2go.NewResource("http://example.org/Invalid"), nil); err == nil {
+		t.Fatal("expected error for non-numeric search boost")
+	}
+}
+
+func TestParsePropertyIntervalEnd(t *testing.T) {
+	graph := rdf2go.NewGraph("")
+	data := `
+@prefix sh: <http://www.w3.org/ns/shacl#> .
+@prefix rdfstore: <https://github.com/ULB-Darmstadt/rdf-store#> .
+@prefix ex: <http://example.org/> .
+ex:Contract a sh:NodeShape ;
+  sh:property [ sh:path ex:validFrom ; rdfstore:intervalEnd ex:validUntil ] .
+ex:Invalid a sh:NodeShape ;
+  sh:property [ sh:path ex:validFrom ; rdfstore:intervalEnd "validUntil" ] .
+`
+	if err := graph.Parse(strings.NewReader(data), "text/turtle"); err != nil {
+		t.Fatal(err)
+	}
+	shape, err := (&NodeShape{Graph: graph}).Parse(rdf2go.NewResource("http://example.org/Contract"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if end := shape.Properties["http://example.org/validFrom"][0].IntervalEnd; end != "http://example.org/validUntil" {
+		t.Fatalf("unexpected interval end %q", end)
+	}
+	if _, err := (&NodeShape{Graph: graph}).Parse(rdf2go.NewResource("http://example.org/Invalid"), nil); err == nil {
+		t.Fatal("expected error for literal interval end")
+	}
+}
+
+func TestParseNodeShapeQuantityPredicates(t *testing.T) {
+	graph := rdf2go.NewGraph("")
+	data := `
+@prefix sh: <http://www.w3.org/ns/shacl#> .
+@prefix rdfstore: <https://github.com/ULB-Darmstadt/rdf-store#> .
+@prefix schema: <http://schema.org/> .
+@prefix ex: <http://example.org/> .
+ex:Measurement a sh:NodeShape ;
+  rdfstore:quantityUnit schema:unitCode ;
+  rdfstore:quantityValue schema:value .
+ex:Invalid a sh:NodeShape ;
+  rdfstore:quantityUnit "unitCode" .
+`
+	if err := graph.Parse(strings.NewReader(data), "text/turtle"); err != nil {
+		t.Fatal(err)
+	}
+	shape, err := (&NodeShape{Graph: graph}).Parse(rdf2go.NewResource("http://example.org/Measurement"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shape.QuantityUnit != "http://schema.org/unitCode" || shape.QuantityValue != "http://schema.org/value" || shape.QuantityKind != "" {
+		t.Fatalf("unexpected quantity predicates %q %q %q", shape.QuantityUnit, shape.QuantityKind, shape.QuantityValue)
+	}
+	if _, err := (&NodeShape{Graph: graph}).Parse(rdf2go.NewResource("http://example.org/Invalid"), nil); err == nil {
+		t.Fatal("expected error for literal quantity predicate")
+	}
+}
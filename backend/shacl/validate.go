@@ -2,39 +2,65 @@ package shacl
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"rdf-store-backend/base"
+	"rdf-store-backend/httpclient"
+	"rdf-store-backend/metrics"
 	"strings"
+	"time"
 )
 
 type validationResponse map[string][]string
 
+var httpClient = httpclient.New(metrics.ServiceValidator, httpclient.Timeout("VALIDATOR_TIMEOUT", 60))
+
 // Validate posts data and shapes to the SHACL validator service.
 // It returns a map of resource IDs to shape IDs plus any error encountered.
-func Validate(shapesGraph string, shapeID string, dataGraph string, dataID string) (map[string][]string, error) {
-	return validate(shapesGraph, shapeID, dataGraph, dataID)
+func Validate(ctx context.Context, shapesGraph string, shapeID string, dataGraph string, dataID string) (map[string][]string, error) {
+	return validate(ctx, shapesGraph, shapeID, dataGraph, dataID)
+}
+
+// Ping checks that the validator service answers its health endpoint.
+// It returns an error when the request fails or the service responds with a non-2xx status.
+func Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(base.ValidatorEndpoint, "/")+"/healthz", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("validator health check failed - status: %v", resp.StatusCode)
+	}
+	return nil
 }
 
-func validate(shapesGraph string, shapeID string, dataGraph string, dataID string) (map[string][]string, error) {
+func validate(ctx context.Context, shapesGraph string, shapeID string, dataGraph string, dataID string) (map[string][]string, error) {
 	form := url.Values{}
 	form.Add("shapesGraph", shapesGraph)
 	form.Add("shapeID", shapeID)
 	form.Add("dataGraph", dataGraph)
 	form.Add("dataID", dataID)
-	client := http.Client{}
-	req, err := http.NewRequest("POST", base.ValidatorEndpoint, strings.NewReader(form.Encode()))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, base.ValidatorEndpoint, strings.NewReader(form.Encode()))
 	if err != nil {
 		return nil, err
 	}
 	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
-	resp, err := client.Do(req)
+	start := time.Now()
+	resp, err := httpclient.Do(httpClient, req, true)
 	if err != nil {
+		metrics.ObserveBackendCall(metrics.ServiceValidator, "validate", start, 0, err)
 		return nil, err
 	}
+	metrics.ObserveBackendCall(metrics.ServiceValidator, "validate", start, resp.StatusCode, nil)
 	defer resp.Body.Close()
 	if resp.StatusCode < 200 || resp.StatusCode > 299 {
 		message := ""
@@ -0,0 +1,93 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"rdf-store-backend/base"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const ServiceName = "rdf-store"
+
+// Supported values of the TRACES_EXPORTER environment variable.
+const (
+	ExporterNone = "none"
+	ExporterOTLP = "otlp"
+	ExporterFile = "file"
+)
+
+var exporter = base.EnvVar("TRACES_EXPORTER", ExporterNone)
+var exportFile = base.EnvVar("TRACES_FILE", "traces.json")
+
+// init installs the W3C trace context propagator, so incoming trace headers
+// are continued and outgoing requests carry them even if export is disabled.
+func init() {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+}
+
+// Init configures the global tracer provider for the exporter selected by the
+// TRACES_EXPORTER environment variable. The OTLP exporter reads its endpoint
+// and headers from the standard OTEL_EXPORTER_OTLP_* environment variables.
+// It returns a function that flushes buffered spans and stops the provider,
+// or an error if the exporter cannot be created.
+func Init() (func(context.Context) error, error) {
+	var spanExporter sdktrace.SpanExporter
+	var err error
+	switch exporter {
+	case "", ExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case ExporterOTLP:
+		spanExporter, err = otlptracehttp.New(context.Background())
+	case ExporterFile:
+		var file *os.File
+		file, err = os.OpenFile(exportFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err == nil {
+			spanExporter, err = stdouttrace.New(stdouttrace.WithWriter(file))
+		}
+	default:
+		err = fmt.Errorf("unknown traces exporter %q", exporter)
+	}
+	if err != nil {
+		return nil, err
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(semconv.ServiceName(ServiceName)))
+	if err != nil {
+		return nil, err
+	}
+	options := []sdktrace.TracerProviderOption{sdktrace.WithResource(res)}
+	if exporter == ExporterFile {
+		// write spans immediately, so the file is complete when debugging locally
+		options = append(options, sdktrace.WithSyncer(spanExporter))
+	} else {
+		options = append(options, sdktrace.WithBatcher(spanExporter))
+	}
+	provider := sdktrace.NewTracerProvider(options...)
+	otel.SetTracerProvider(provider)
+	slog.Info("tracing enabled", "exporter", exporter)
+	return provider.Shutdown, nil
+}
+
+// Transport wraps an HTTP transport so that every request to a backend
+// service creates a client span and carries the trace context headers.
+// It returns the instrumented round tripper.
+func Transport(next http.RoundTripper, service string) http.RoundTripper {
+	return otelhttp.NewTransport(next,
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			return fmt.Sprintf("%s %s %s", service, r.Method, r.URL.Path)
+		}),
+		otelhttp.WithSpanOptions(trace.WithAttributes(attribute.String("peer.service", service))),
+	)
+}
@@ -0,0 +1,66 @@
+package tracing
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestTransportPropagatesTraceContext(t *testing.T) {
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+	}))
+	defer server.Close()
+
+	ctx, span := sdktrace.NewTracerProvider().Tracer("test").Start(t.Context(), "parent")
+	defer span.End()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/healthz", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := (&http.Client{Transport: Transport(http.DefaultTransport, "validator")}).Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if !strings.Contains(traceparent, span.SpanContext().TraceID().String()) {
+		t.Fatalf("expected traceparent with trace id %s, got %q", span.SpanContext().TraceID(), traceparent)
+	}
+}
+
+func TestInitShutdownFlushesBatchedSpans(t *testing.T) {
+	var exports atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/traces" {
+			exports.Add(1)
+		}
+	}))
+	defer server.Close()
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", server.URL)
+	previousExporter, previousProvider := exporter, otel.GetTracerProvider()
+	exporter = ExporterOTLP
+	t.Cleanup(func() {
+		exporter = previousExporter
+		otel.SetTracerProvider(previousProvider)
+	})
+
+	shutdown, err := Init()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, span := otel.Tracer("test").Start(t.Context(), "request")
+	span.End()
+	if err := shutdown(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	if exports.Load() == 0 {
+		t.Fatal("expected the buffered span to be exported on shutdown")
+	}
+}
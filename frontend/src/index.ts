@@ -16,6 +16,7 @@ import { map } from 'lit/directives/map.js'
 import { registerPlugin, ShaclForm, Query } from '@ulb-darmstadt/shacl-form'
 import { LeafletPlugin } from '@ulb-darmstadt/shacl-form/plugins/leaflet.js'
 import { SolrQueryFacetProvider } from './shacl-query'
+import type { QuantityPredicates } from './unit-conversion'
 
 export type Config = {
     layout: string
@@ -32,6 +33,8 @@ export type Config = {
     conversionUnit: string
     conversionQuantity: string
     conversionValue: string
+    conversionPredicates?: QuantityPredicates[]
+    textLanguages: string[]
 }
 
 @customElement('rdf-store')
@@ -247,6 +247,43 @@ describe('quantity conversion', () => {
         expect(filters).toContainEqual(expect.stringContaining('valueNumber:["0" TO "100"]'))
     })
 
+    it('converts quantities of published predicate sets without quantity kind', async() => {
+        mockQuantityFetch({ [unit('t12')]: { multiplier: 1, offset: 273.15 } })
+        const config = {
+            ...quantityConfig(),
+            conversionPredicates: [{ unit: 'http://schema.org/unitCode', value: 'http://schema.org/value', kindOptional: true }]
+        } as unknown as Config
+        const provider = new SolrQueryFacetProvider(config)
+        const filters = await buildFilters(provider, [
+            {
+                field: field('value', ['part', 'http://schema.org/value']),
+                operator: 'range',
+                min: DataFactory.literal('0'),
+                max: DataFactory.literal('100')
+            },
+            {
+                field: field('unit', ['part', 'http://schema.org/unitCode']),
+                operator: 'equals',
+                value: DataFactory.namedNode(unit('t12'))
+            }
+        ])
+        expect(filters).toContainEqual(expect.stringContaining('valueNumber:["273.15" TO "373.15"]'))
+    })
+
+    it('requires a quantity kind for predicate sets that declare one', async() => {
+        mockQuantityFetch({ [unit('t13')]: { multiplier: 1, offset: 273.15 } })
+        const config = {
+            ...quantityConfig(),
+            conversionPredicates: [{ unit: UNIT_PREDICATE, quantityKind: KIND_PREDICATE, value: VALUE_PREDICATE }]
+        } as unknown as Config
+        const provider = new SolrQueryFacetProvider(config)
+        const filters = await buildFilters(provider, [
+            rangeCriterion('0', '100'),
+            unitCriterion(unit('t13'))
+        ])
+        expect(filters).toContainEqual(expect.stringContaining('valueNumber:["0" TO "100"]'))
+    })
+
     it('converts facet min and max back to the selected unit', async() => {
         mockQuantityFetch({ [unit('t6')]: { multiplier: 1, offset: 273.15 } })
         vi.mocked(executeSolrRequest).mockResolvedValue({
@@ -346,6 +383,21 @@ describe('quantity conversion', () => {
         ])
         expect(filters).toContainEqual(expect.stringContaining('valueNumber:["0" TO "100"]'))
     })
+
+    it('excludes expanded broader concepts from facet domains', async() => {
+        mockQuantityFetch({})
+        vi.mocked(executeSolrRequest).mockResolvedValue({
+            facets: { f0_count: { entities: 3 }, f0_buckets: { buckets: [] } }
+        } as unknown as SearchResponse)
+        const provider = new SolrQueryFacetProvider(quantityConfig())
+        await provider.getFacets({
+            query: { rootShapeId: 'urn:shape:root', criteria: [] },
+            fields: [field('kind', ['part', KIND_PREDICATE])],
+            signal: new AbortController().signal
+        })
+        const facet = vi.mocked(executeSolrRequest).mock.lastCall?.[1].facet as Record<string, { domain: { filter: string[] } }>
+        expect(facet.f0_buckets.domain.filter).toContain('-expanded:true')
+    })
 })
 
 describe('loading notification', () => {
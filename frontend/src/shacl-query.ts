@@ -170,7 +170,8 @@ export class SolrQueryFacetProvider implements QueryFacetProvider {
         const paths = await Promise.all(request.fields.map(field => this.pathFilter(field)))
 
         request.fields.forEach((field, index) => {
-            const domain = { blockChildren: 'docType:entity', filter: ['docType:value', paths[index]] }
+            // broader concepts added at index time must not show up as facet values
+            const domain = { blockChildren: 'docType:entity', filter: ['docType:value', paths[index], '-expanded:true'] }
             const valueField = this.facetValueField(field)
             facet[`f${index}_count`] = {
                 type: 'query', q: `${valueField}:[* TO *]`, domain,
@@ -202,7 +203,7 @@ export class SolrQueryFacetProvider implements QueryFacetProvider {
         let autoKindDetected = false
         request.fields.forEach((field, index) => {
             queryFieldPaths(field).forEach(path => {
-                if (path[path.length - 1] !== this.config.conversionQuantity) {
+                if (!this.unitConversion.isQuantityKindPredicate(path[path.length - 1])) {
                     return
                 }
                 const buckets = (aggregations[`f${index}_buckets`] as SolrFacetResult | undefined)?.buckets || []
@@ -308,6 +309,17 @@ export class SolrQueryFacetProvider implements QueryFacetProvider {
         return 'valueString'
     }
 
+    // matches text values in any text field; language fields are additionally
+    // matched with analysis, as wildcard terms are not stemmed
+    private textContainsFilter(value: string): string {
+        const escaped = value.replace(LUCENE_SPECIAL_RE, '\\$1')
+        const clauses = [`valueText:*${escaped}*`]
+        for (const language of this.config.textLanguages ?? []) {
+            clauses.push(`valueText_${language}:*${escaped}*`, `valueText_${language}:${quote(value)}`)
+        }
+        return clauses.length === 1 ? clauses[0] : `(${clauses.join(' OR ')})`
+    }
+
     private facetValueField(field: QueryField): string {
         return this.valueField(field)
     }
@@ -324,8 +336,7 @@ export class SolrQueryFacetProvider implements QueryFacetProvider {
         const path = await this.pathFilter(criterion.field)
         let valueFilter: string | undefined
         if (criterion.operator === 'contains' && criterion.value) {
-            const escaped = criterion.value.value.replace(LUCENE_SPECIAL_RE, '\\$1')
-            valueFilter = `valueText:*${escaped}*`
+            valueFilter = this.textContainsFilter(criterion.value.value)
         } else if (criterion.operator === 'equals' && criterion.value) {
             const valueField = this.valueField(criterion.field, criterion.value)
             if (valueField === 'valueGeo') {
@@ -27,12 +27,23 @@ type SubjectGroup = {
     units: QueryField[]
     kinds: QueryField[]
     deltas: QueryField[]
+    predicates: Set<string>
+}
+
+export type QuantityPredicates = {
+    unit: string
+    quantityKind?: string
+    value: string
+    kindOptional?: boolean
 }
 
 type ConversionConfig = {
     conversionUnit?: string
     conversionQuantity?: string
     conversionValue?: string
+    // all predicate sets the backend converts when indexing; older backends
+    // only provide the single conversionUnit/Quantity/Value triple
+    conversionPredicates?: QuantityPredicates[]
 }
 
 function toSi(value: number, conversion: QuantityUnitConversion): number {
@@ -82,9 +93,7 @@ function selectedIri(term?: Term): string | undefined {
 }
 
 export class UnitConversionResolver {
-    private readonly conversionUnit: string
-    private readonly conversionQuantity: string
-    private readonly conversionValue: string
+    private readonly predicateSets: QuantityPredicates[]
     private readonly expandPaths: (field: QueryField) => string[][]
     private readonly quantityCache = new Map<string, Quantity>()
     // quantity kinds learned from kind facets that offer exactly one bucket,
@@ -92,14 +101,22 @@ export class UnitConversionResolver {
     private readonly autoQuantityKinds = new Map<string, string>()
 
     constructor(config: ConversionConfig, expandPaths: (field: QueryField) => string[][]) {
-        this.conversionUnit = config.conversionUnit ?? ''
-        this.conversionQuantity = config.conversionQuantity ?? ''
-        this.conversionValue = config.conversionValue ?? ''
+        if (config.conversionPredicates) {
+            this.predicateSets = config.conversionPredicates
+        } else if (config.conversionUnit && config.conversionQuantity && config.conversionValue) {
+            this.predicateSets = [{ unit: config.conversionUnit, quantityKind: config.conversionQuantity, value: config.conversionValue }]
+        } else {
+            this.predicateSets = []
+        }
         this.expandPaths = expandPaths
     }
 
     get enabled(): boolean {
-        return this.conversionUnit.length > 0 && this.conversionQuantity.length > 0 && this.conversionValue.length > 0
+        return this.predicateSets.length > 0
+    }
+
+    isQuantityKindPredicate(predicate: string): boolean {
+        return this.predicateSets.some(set => set.quantityKind === predicate)
     }
 
     learnAutoKind(subject: string, kindURI: string): boolean {
@@ -127,12 +144,13 @@ export class UnitConversionResolver {
             const unitURI = group.units.map(field => selected.get(field.id)).find(Boolean)
             const quantityKindURI = group.kinds.map(field => selected.get(field.id)).find(Boolean)
                 ?? this.autoQuantityKinds.get(subject)
-            if (!unitURI || !quantityKindURI) {
+            if (!unitURI || (!quantityKindURI && !this.kindOptional(group))) {
                 continue
             }
             const quantity: Quantity = {
                 unitURI,
-                quantityKindURI,
+                // the backend converts values without kind by the dimension of their unit
+                quantityKindURI: quantityKindURI ?? '',
                 isDelta: group.deltas.some(field => {
                     const value = selected.get(field.id)
                     return value === 'true' || value === '1'
@@ -178,12 +196,12 @@ export class UnitConversionResolver {
         fields.forEach(field => {
             this.expandPaths(field).forEach(path => {
                 const predicate = path[path.length - 1]
-                let role: keyof SubjectGroup | undefined
-                if (predicate === this.conversionValue) {
+                let role: 'values' | 'units' | 'kinds' | 'deltas' | undefined
+                if (this.predicateSets.some(set => set.value === predicate)) {
                     role = 'values'
-                } else if (predicate === this.conversionUnit) {
+                } else if (this.predicateSets.some(set => set.unit === predicate)) {
                     role = 'units'
-                } else if (predicate === this.conversionQuantity) {
+                } else if (this.isQuantityKindPredicate(predicate)) {
                     role = 'kinds'
                 } else if (predicate === QUDT_IS_DELTA_QUANTITY) {
                     role = 'deltas'
@@ -192,8 +210,9 @@ export class UnitConversionResolver {
                     return
                 }
                 const subject = path.slice(0, -1).join('\0')
-                const group = subjects.get(subject) ?? { values: [], units: [], kinds: [], deltas: [] }
+                const group = subjects.get(subject) ?? { values: [], units: [], kinds: [], deltas: [], predicates: new Set<string>() }
                 subjects.set(subject, group)
+                group.predicates.add(predicate)
                 if (!group[role].includes(field)) {
                     group[role].push(field)
                 }
@@ -202,6 +221,10 @@ export class UnitConversionResolver {
         return subjects
     }
 
+    private kindOptional(group: SubjectGroup): boolean {
+        return this.predicateSets.some(set => set.kindOptional && group.predicates.has(set.unit) && group.predicates.has(set.value))
+    }
+
     private async fetchQuantities(quantities: Quantity[]) {
         if (quantities.length === 0) {
             return